@@ -0,0 +1,209 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Shutdowner is implemented by types that stop gracefully within the deadline of a context,
+// such as *http.Server.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Stopper is implemented by types with a blocking Stop method that cannot fail,
+// such as schedulers and worker pools.
+type Stopper interface {
+	Stop()
+}
+
+// GracefulStopper is implemented by servers that stop either gracefully, waiting for pending
+// work, or immediately, such as *grpc.Server.
+type GracefulStopper interface {
+	GracefulStop()
+	Stop()
+}
+
+// AddShutdowner registers s to be shut down when CloseAll is called, configured by opts.
+// Shutdown receives a context carrying the values of ctx, which is canceled when either ctx or
+// the shutdown context is done and has the shutdown deadline, if any. The function runs in
+// PhaseRequests if c declares it, as NewWebService does, unless opts give it another priority
+// or phase. The function is named after the dynamic type of s. It panics if s is nil.
+//
+// Example:
+//
+//	c.AddShutdowner(context.Background(), srv, closer.WithLabel("api"))
+func (c *Closer) AddShutdowner(ctx context.Context, s Shutdowner, opts ...Option) {
+	mustNotBeNil(s, "Shutdowner")
+	opts = append([]Option{withName(typeName(s)), c.phaseOr(PhaseRequests, WithPriority(0))}, opts...)
+	c.addContext(opts, func(shutdownCtx context.Context) error {
+		ctx, cancel := mergeContext(ctx, shutdownCtx)
+		defer cancel()
+		return s.Shutdown(ctx)
+	})
+}
+
+// AddStopper registers s to be stopped when CloseAll is called, configured by opts.
+// The function is named after the dynamic type of s. It panics if s is nil.
+func (c *Closer) AddStopper(s Stopper, opts ...Option) {
+	mustNotBeNil(s, "Stopper")
+	c.add(append([]Option{withName(typeName(s))}, opts...), func() error {
+		s.Stop()
+		return nil
+	})
+}
+
+// AddGracefulStopper registers s to be stopped gracefully when CloseAll is called, configured
+// by opts, falling back to stopping it immediately if GracefulStop has not returned after
+// grace or by the shutdown deadline. A fallback is reported with an error wrapping ErrTimeout,
+// so the path taken shows in logs and in the Report. A zero grace waits for GracefulStop until
+// the deadline. The function runs in PhaseRequests if c declares it, as NewWebService does,
+// unless opts give it another priority or phase. The function is named after the dynamic type
+// of s. It panics if s is nil.
+//
+// Example:
+//
+//	c.AddGracefulStopper(grpcServer, 10*time.Second)
+func (c *Closer) AddGracefulStopper(s GracefulStopper, grace time.Duration, opts ...Option) {
+	mustNotBeNil(s, "GracefulStopper")
+	opts = append([]Option{withName(typeName(s)), c.phaseOr(PhaseRequests, WithPriority(0))}, opts...)
+	c.addContext(opts, func(ctx context.Context) error {
+		if grace > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = withTimeout(ctx, grace)
+			defer cancel()
+		}
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			s.Stop()
+			return fmt.Errorf("graceful stop %w: %w, stopped immediately", ErrTimeout, context.Cause(ctx))
+		}
+	})
+}
+
+// AddCloser registers one or more io.Closer values to be closed when CloseAll is called.
+// Each function is named after the dynamic type of its closer. Nil closers, including
+// interfaces holding nil pointers, are skipped, so optional resources can be registered
+// without checking them first.
+//
+// Example:
+//
+//	c.AddCloser(db, file, conn)
+func (c *Closer) AddCloser(closers ...io.Closer) {
+	for _, cl := range closers {
+		if isNil(cl) {
+			continue
+		}
+		c.AddNamed(typeName(cl), cl.Close, rehearsalOf(cl)...)
+	}
+}
+
+// AddFunc registers one or more cleanup functions that cannot fail to be executed when
+// CloseAll is called, sparing them a wrapper returning nil. Nil functions are handled as by
+// Add.
+//
+// Example:
+//
+//	c.AddFunc(ticker.Stop, cancel)
+func (c *Closer) AddFunc(f ...func()) {
+	fs := make([]closeFunc, 0, len(f))
+	for _, fn := range f {
+		// Check fn itself: its wrapper is never nil nor a duplicate.
+		if !c.valid(fn == nil, funcValue(fn)) {
+			continue
+		}
+		fs = append(fs, func() error {
+			fn()
+			return nil
+		})
+	}
+	c.add(nil, fs...)
+}
+
+// AddValue registers v to be closed when CloseAll is called, configured by opts, and returns
+// it, so that a resource can be registered where it is created. The function is named after
+// the dynamic type of v. It panics if v is nil.
+//
+// Example:
+//
+//	f := closer.AddValue(c, must(os.Create(path)))
+func AddValue[T io.Closer](c *Closer, v T, opts ...Option) T {
+	mustNotBeNil(v, "io.Closer")
+	c.add(append(append([]Option{withName(typeName(v))}, rehearsalOf(v)...), opts...), v.Close)
+	return v
+}
+
+// Manage opens a resource with open and, if it succeeds, registers it as AddValue does,
+// configured by opts, so that opening a resource and registering its Close take one call.
+// If open fails, its results are returned as they are and nothing is registered.
+//
+// Example:
+//
+//	db, err := closer.Manage(c, func() (*sql.DB, error) { return sql.Open("pgx", dsn) })
+//	if err != nil {
+//		return err
+//	}
+func Manage[T io.Closer](c *Closer, open func() (T, error), opts ...Option) (T, error) {
+	v, err := open()
+	if err != nil {
+		return v, err
+	}
+	return AddValue(c, v, opts...), nil
+}
+
+// mergeContext returns a context carrying the values of base that is canceled when either
+// base or other is done, and that has the deadline of other if it is earlier.
+func mergeContext(base, other context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(base)
+	stop := context.AfterFunc(other, func() {
+		cancel(context.Cause(other))
+	})
+	if deadline, ok := other.Deadline(); ok {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = withDeadline(withClock(ctx, clockFrom(other)), deadline)
+		return ctx, func() {
+			cancelDeadline()
+			stop()
+			cancel(context.Canceled)
+		}
+	}
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// mustNotBeNil panics if v is nil or an interface holding a nil pointer,
+// so that misconfigured registrations fail at Add time rather than mid-shutdown.
+func mustNotBeNil(v any, kind string) {
+	if isNil(v) {
+		panic("closer: nil " + kind)
+	}
+}
+
+// isNil reports whether v is nil or holds a nil value of a nillable kind.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// typeName returns the name of the dynamic type of v for use as a function name.
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
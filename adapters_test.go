@@ -0,0 +1,309 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeShutdowner records the context it was shut down with.
+type fakeShutdowner struct {
+	calls int32
+	ctx   context.Context
+}
+
+func (f *fakeShutdowner) Shutdown(ctx context.Context) error {
+	atomic.AddInt32(&f.calls, 1)
+	f.ctx = ctx
+	return nil
+}
+
+// fakeStopper counts calls to Stop.
+type fakeStopper struct {
+	calls int32
+}
+
+func (f *fakeStopper) Stop() {
+	atomic.AddInt32(&f.calls, 1)
+}
+
+// ctxKey is a context key used to identify the context passed to Shutdown.
+type ctxKey struct{}
+
+// TestAddShutdowner verifies that a Shutdowner is shut down once with the registered context.
+func TestAddShutdowner(t *testing.T) {
+	c := New()
+	s := &fakeShutdowner{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "registered")
+
+	c.AddShutdowner(ctx, s)
+	c.CloseAll()
+	c.Wait()
+
+	if s.calls != 1 {
+		t.Errorf("expected Shutdown to be called once, got %d", s.calls)
+	}
+	if s.ctx.Value(ctxKey{}) != "registered" {
+		t.Error("expected Shutdown to receive the registered context")
+	}
+}
+
+// TestAddStopper verifies that a Stopper is stopped once.
+func TestAddStopper(t *testing.T) {
+	c := New()
+	s := &fakeStopper{}
+
+	c.AddStopper(s)
+	c.CloseAll()
+	c.Wait()
+
+	if s.calls != 1 {
+		t.Errorf("expected Stop to be called once, got %d", s.calls)
+	}
+}
+
+// TestAdapterOptions verifies that the options given to AddShutdowner, AddStopper and
+// AddGracefulStopper configure their functions.
+func TestAdapterOptions(t *testing.T) {
+	c := New()
+	g := newFakeGracefulStopper()
+	close(g.release)
+	c.AddStopper(&fakeStopper{}, WithLabel("jobs"), WithPriority(-1))
+	c.AddShutdowner(context.Background(), &fakeShutdowner{}, WithLabel("api"), WithPriority(2))
+	c.AddGracefulStopper(g, time.Second, WithLabel("grpc"), WithPriority(1))
+
+	var got []string
+	for _, s := range c.Plan() {
+		for _, f := range s.Funcs {
+			got = append(got, f.Name+"/"+f.Label)
+		}
+	}
+	want := []string{"*closer.fakeShutdowner/api", "*closer.fakeGracefulStopper/grpc", "*closer.fakeStopper/jobs"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestAddNilAdapters verifies that nil values, including typed nil pointers, are rejected.
+func TestAddNilAdapters(t *testing.T) {
+	c := New()
+	tests := map[string]func(){
+		"nil Shutdowner":       func() { c.AddShutdowner(context.Background(), nil) },
+		"typed nil Shutdowner": func() { c.AddShutdowner(context.Background(), (*fakeShutdowner)(nil)) },
+		"nil Stopper":          func() { c.AddStopper(nil) },
+		"typed nil Stopper":    func() { c.AddStopper((*fakeStopper)(nil)) },
+		"typed nil Closer":     func() { AddValue(c, (*os.File)(nil)) },
+	}
+	for name, add := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			add()
+		})
+	}
+}
+
+// TestAddShutdownerDeadline verifies that Shutdown receives the shutdown deadline
+// together with the values of the registered context.
+func TestAddShutdownerDeadline(t *testing.T) {
+	c := New(WithTimeout(time.Minute))
+	s := &fakeShutdowner{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "registered")
+
+	c.AddShutdowner(ctx, s)
+	c.CloseAll()
+	c.Wait()
+
+	if _, ok := s.ctx.Deadline(); !ok {
+		t.Error("expected Shutdown to receive the shutdown deadline")
+	}
+	if s.ctx.Value(ctxKey{}) != "registered" {
+		t.Error("expected Shutdown to receive the registered context")
+	}
+}
+
+// fakeCloser counts calls to Close.
+type fakeCloser struct {
+	calls int32
+}
+
+func (f *fakeCloser) Close() error {
+	atomic.AddInt32(&f.calls, 1)
+	return nil
+}
+
+// TestAddCloser verifies that io.Closer values are closed once and that nil ones are skipped.
+func TestAddCloser(t *testing.T) {
+	c := New()
+	a, b := &fakeCloser{}, &fakeCloser{}
+	var missing *fakeCloser
+
+	c.AddCloser(a, nil, missing, b)
+	if n := len(c.Plan()[0].Funcs); n != 2 {
+		t.Fatalf("expected 2 registered closers, got %d", n)
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a.calls != 1 || b.calls != 1 {
+		t.Errorf("expected each closer to be closed once, got %d and %d", a.calls, b.calls)
+	}
+}
+
+// fakeGracefulStopper blocks GracefulStop until Stop is called or release is closed.
+type fakeGracefulStopper struct {
+	release chan struct{}
+	stopped chan struct{}
+}
+
+func newFakeGracefulStopper() *fakeGracefulStopper {
+	return &fakeGracefulStopper{release: make(chan struct{}), stopped: make(chan struct{})}
+}
+
+func (f *fakeGracefulStopper) GracefulStop() {
+	select {
+	case <-f.release:
+	case <-f.stopped:
+	}
+}
+
+func (f *fakeGracefulStopper) Stop() {
+	close(f.stopped)
+}
+
+// TestAddGracefulStopper verifies that a server stopping in time is not stopped forcibly.
+func TestAddGracefulStopper(t *testing.T) {
+	s := newFakeGracefulStopper()
+	close(s.release)
+	c := New()
+	c.AddGracefulStopper(s, time.Second)
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	select {
+	case <-s.stopped:
+		t.Error("expected Stop not to be called")
+	default:
+	}
+}
+
+// TestAddGracefulStopperFallback verifies that Stop is called once the grace period ends and
+// that the fallback is reported as a timeout.
+func TestAddGracefulStopperFallback(t *testing.T) {
+	s := newFakeGracefulStopper()
+	c := New()
+	c.AddGracefulStopper(s, 10*time.Millisecond)
+
+	if err := c.CloseAll(); !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "stopped immediately") {
+		t.Errorf("expected forced stop timeout, got %v", err)
+	}
+	select {
+	case <-s.stopped:
+	default:
+		t.Error("expected Stop to be called")
+	}
+	if f := c.Report().Funcs[0]; !f.TimedOut {
+		t.Errorf("expected report to show the timeout, got %+v", f)
+	}
+}
+
+// TestAddFunc verifies that functions without a result are all run.
+func TestAddFunc(t *testing.T) {
+	c := New()
+	var calls atomic.Int32
+	inc := func() { calls.Add(1) }
+	c.AddFunc(inc, inc)
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
+
+// TestAddFuncNil verifies that a nil function panics at registration, or is skipped with
+// WithSkipNil, instead of panicking once its wrapper runs.
+func TestAddFuncNil(t *testing.T) {
+	t.Run("panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != "closer: nil closing function" {
+				t.Errorf("expected panic on nil function, got %v", r)
+			}
+		}()
+		New().AddFunc(nil)
+	})
+	t.Run("skip", func(t *testing.T) {
+		l := &recordLogger{}
+		c := New(WithSkipNil(), WithLogger(l))
+		ran := false
+		c.AddFunc(nil, func() { ran = true })
+
+		if err := c.CloseAll(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !ran {
+			t.Error("expected the other function to run")
+		}
+		if n := len(c.Report().Funcs); n != 1 {
+			t.Errorf("expected 1 function registered, got %d", n)
+		}
+		if !strings.HasPrefix(l.events[0], "ERROR nil closer skipped") {
+			t.Errorf("expected the nil function to be logged, got %v", l.events)
+		}
+	})
+}
+
+// TestAddValue verifies that a typed resource is returned as is, closed on shutdown and
+// named after its type.
+func TestAddValue(t *testing.T) {
+	c := New()
+	f := &fakeCloser{}
+	if got := AddValue(c, f, WithLabel("storage")); got != f {
+		t.Errorf("expected %p to be returned, got %p", f, got)
+	}
+
+	c.CloseAll()
+	if f.calls != 1 {
+		t.Errorf("expected value to be closed once, got %d", f.calls)
+	}
+	if r := c.Report().Funcs[0]; r.Name != "*closer.fakeCloser" || r.Label != "storage" {
+		t.Errorf("expected *closer.fakeCloser in storage, got %s in %s", r.Name, r.Label)
+	}
+}
+
+// TestManage verifies that a resource opened successfully is registered and returned, and
+// that a failed open registers nothing.
+func TestManage(t *testing.T) {
+	c := New()
+	f := &fakeCloser{}
+	got, err := Manage(c, func() (*fakeCloser, error) { return f, nil })
+	if err != nil || got != f {
+		t.Fatalf("expected %p and no error, got %p and %v", f, got, err)
+	}
+	boom := errors.New("boom")
+	if _, err := Manage(c, func() (*fakeCloser, error) { return nil, boom }); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+
+	c.CloseAll()
+	if f.calls != 1 {
+		t.Errorf("expected value to be closed once, got %d", f.calls)
+	}
+	if n := len(c.Report().Funcs); n != 1 {
+		t.Errorf("expected 1 function registered, got %d", n)
+	}
+}
@@ -0,0 +1,85 @@
+package closer
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestAfter verifies that functions run after their dependencies in a diamond-shaped graph.
+func TestAfter(t *testing.T) {
+	c := New()
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) closeFunc {
+		return func() error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	c.AddNamed("logger", record("logger"), After("db", "cache"))
+	c.AddNamed("db", record("db"), After("http"))
+	c.AddNamed("cache", record("cache"), After("http"))
+	c.AddNamed("http", record("http"))
+
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pos := make(map[string]int)
+	for i, name := range order {
+		pos[name] = i
+	}
+	if pos["http"] != 0 || pos["logger"] != 3 {
+		t.Errorf("expected http first and logger last, got %v", order)
+	}
+}
+
+// TestPlanAfter compares the plan of a dependency graph against a golden structure.
+func TestPlanAfter(t *testing.T) {
+	c := New()
+	noop := func() error { return nil }
+	c.AddNamed("db", noop, After("http"))
+	c.Group(WithLabel("kafka")).Add(noop)
+	c.AddNamed("logger", noop, After("db", "kafka"))
+	c.AddNamed("http", noop, After("missing"))
+
+	want := []PlanStep{
+		{Index: 0, Concurrent: true, Funcs: []PlanFunc{{Index: 1, Label: "kafka"}, {Index: 3, Name: "http", After: []string{"missing"}}}},
+		{Index: 1, Concurrent: true, Funcs: []PlanFunc{{Index: 0, Name: "db", After: []string{"http"}}}},
+		{Index: 2, Concurrent: true, Funcs: []PlanFunc{{Index: 2, Name: "logger", After: []string{"db", "kafka"}}}},
+	}
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected plan %+v, got %+v", want, got)
+	}
+}
+
+// TestAfterCycle verifies that functions in a dependency cycle still run, after the others,
+// and are reported.
+func TestAfterCycle(t *testing.T) {
+	c := New()
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) closeFunc {
+		return func() error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	c.AddNamed("a", record("a"), After("b"))
+	c.AddNamed("b", record("b"), After("a"))
+	c.AddNamed("c", record("c"))
+
+	err := c.CloseAll()
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected %v, got %v", ErrDependencyCycle, err)
+	}
+	if len(order) != 3 || order[0] != "c" {
+		t.Errorf("expected c to run before the cycle, got %v", order)
+	}
+}
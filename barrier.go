@@ -0,0 +1,49 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// barrier is a step run by WithPreShutdownBarrier.
+type barrier struct {
+	fn      contextFunc
+	timeout time.Duration
+}
+
+// WithPreShutdownBarrier makes New create a Closer that runs fn once shutdown has started,
+// after holds are released and tasks have finished, and before any closing function runs,
+// for steps that must be complete before the application starts tearing down, such as
+// resigning leadership or deregistering from service discovery. Unlike closing functions,
+// fn runs even if the shutdown deadline has passed: its context is bounded by timeout only,
+// if positive, and fn is given up on once timeout elapses. Barriers run one at a time, in
+// the order they were given, and the closing functions wait for all of them, whatever
+// their outcome. Quiescers already run by Quiesce do not wait for them. A failure is logged
+// as an Error "pre-shutdown barrier failed" event and reported by Wait under the name
+// "barrier". It panics if fn is nil.
+//
+// Example:
+//
+//	c := closer.New(closer.WithPreShutdownBarrier(elector.Resign, 5*time.Second))
+func WithPreShutdownBarrier(fn func(ctx context.Context) error, timeout time.Duration) Option {
+	mustNotBeNil(fn, "barrier")
+	return func(o *options) {
+		o.barriers = append(o.barriers, barrier{fn: fn, timeout: timeout})
+	}
+}
+
+// runBarriers runs the barriers of c as described in WithPreShutdownBarrier, logging their
+// failures to l, and returns those failures.
+func (c *Closer) runBarriers(ctx context.Context, l Logger) []error {
+	var errs []error
+	for i, b := range c.barriers {
+		e := entry{index: i, timeout: b.timeout, fn: b.fn}
+		start := c.clock.Now()
+		if err := e.run(context.WithoutCancel(ctx)); err != nil {
+			l.Error("pre-shutdown barrier failed", "barrier", i, "duration", c.clock.Now().Sub(start), "error", err)
+			errs = append(errs, fmt.Errorf("barrier: %w", err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,64 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWithPreShutdownBarrier verifies that barriers run in order before any closing function,
+// and that their failures are logged and reported without stopping the shutdown.
+func TestWithPreShutdownBarrier(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	record := func(s string) {
+		mu.Lock()
+		ran = append(ran, s)
+		mu.Unlock()
+	}
+	errResign := errors.New("resign failed")
+	l := &recordLogger{}
+	c := New(WithLogger(l),
+		WithPreShutdownBarrier(func(context.Context) error { record("resign"); return errResign }, 0),
+		WithPreShutdownBarrier(func(context.Context) error { record("deregister"); return nil }, 0))
+	c.Add(func() error { record("db"); return nil })
+	c.AddQuiescer(func() error { record("listener"); return nil })
+
+	err := c.CloseAll()
+	if want := []string{"resign", "deregister", "listener", "db"}; !slices.Equal(ran, want) {
+		t.Errorf("expected %v, got %v", want, ran)
+	}
+	var se *ShutdownError
+	if !errors.As(err, &se) || se.Errors()["barrier"] != errResign {
+		t.Errorf("expected %v reported as barrier, got %v", errResign, err)
+	}
+	if err.Error() != "barrier: resign failed" {
+		t.Errorf("expected %q, got %q", "barrier: resign failed", err.Error())
+	}
+	if want := "ERROR pre-shutdown barrier failed barrier=0 error=resign failed"; !slices.Contains(l.events, want) {
+		t.Errorf("expected %q, got %v", want, l.events)
+	}
+}
+
+// TestWithPreShutdownBarrierTimeout verifies that a barrier is bounded by its own timeout,
+// not by the shutdown deadline.
+func TestWithPreShutdownBarrierTimeout(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	c := New(WithTimeout(time.Millisecond), WithPreShutdownBarrier(func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return ctx.Err()
+	}, 20*time.Millisecond))
+	start := time.Now()
+	err := c.CloseAll()
+	if d := (<-deadlines).Sub(start); d < 15*time.Millisecond {
+		t.Errorf("expected the barrier to have its own deadline, got %v", d)
+	}
+	if !errors.Is(err, ErrTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TB is the part of testing.TB used by BindTest, so that the package does not depend on
+// the testing package.
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Logf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// BindTest ties c to the test t: c is shut down when t and its subtests complete, t fails if
+// a closing function fails, and the events of c are logged with t.Logf until then instead of
+// going to its logger. It lets integration tests reuse production wiring that registers
+// resources into a Closer. For a Closer created by the test itself, see also
+// closertest.NewForTest, which additionally bounds how long the shutdown may take.
+//
+// Example:
+//
+//	c := closer.New()
+//	c.BindTest(t)
+//	app := wire(c)
+func (c *Closer) BindTest(t TB) {
+	t.Helper()
+	c.bound.Store(&testLogger{t})
+	t.Cleanup(func() {
+		err := c.CloseAll()
+		c.bound.Store(nil)
+		if err != nil {
+			t.Errorf("closer: shutdown failed: %v", err)
+		}
+	})
+}
+
+// testLogger is a Logger writing events to a test log.
+type testLogger struct {
+	t TB
+}
+
+func (l *testLogger) Debug(msg string, args ...any) { l.log("DEBUG", msg, args) }
+func (l *testLogger) Info(msg string, args ...any)  { l.log("INFO", msg, args) }
+func (l *testLogger) Error(msg string, args ...any) { l.log("ERROR", msg, args) }
+
+// log writes the event as "closer: LEVEL msg key=value ...".
+func (l *testLogger) log(level, msg string, args []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "closer: %s %s", level, msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	l.t.Logf("%s", b.String())
+}
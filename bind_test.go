@@ -0,0 +1,60 @@
+package closer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeTB records cleanups, logs and errors instead of acting on the test running it.
+type fakeTB struct {
+	cleanups []func()
+	logs     []string
+	errors   []string
+}
+
+func (f *fakeTB) Helper()           {}
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeTB) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// TestBindTest verifies that a bound Closer is shut down at cleanup, logs to the test and
+// fails it on errors, and logs elsewhere once the test is over.
+func TestBindTest(t *testing.T) {
+	var _ TB = t
+	tb := &fakeTB{}
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	c.BindTest(tb)
+	c.AddNamed("db", func() error { return errors.New("boom") })
+	if len(tb.cleanups) != 1 || c.IsClosing() {
+		t.Fatalf("expected shutdown to be deferred to cleanup, got %d cleanups", len(tb.cleanups))
+	}
+
+	tb.cleanups[0]()
+	if !c.IsClosed() {
+		t.Error("expected Closer to be closed at cleanup")
+	}
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "db #0: boom") {
+		t.Errorf("expected shutdown error to fail the test, got %v", tb.errors)
+	}
+	if !slices.ContainsFunc(tb.logs, func(s string) bool {
+		return strings.HasPrefix(s, "closer: ERROR closer failed name=db index=0")
+	}) {
+		t.Errorf("expected events to be logged to the test, got %v", tb.logs)
+	}
+	if len(l.events) != 0 {
+		t.Errorf("expected no events on the logger while bound, got %v", l.events)
+	}
+
+	c.AddNamed("late", func() error { return nil })
+	if len(l.events) != 1 {
+		t.Errorf("expected events on the logger once unbound, got %v", l.events)
+	}
+}
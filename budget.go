@@ -0,0 +1,89 @@
+package closer
+
+import (
+	"context"
+	"time"
+)
+
+// WithPhaseBudget makes New give the phase declared with WithPhase under name its own time
+// budget d. Once the budget of a phase is spent, its functions still running are abandoned
+// and reported as not finished, and shutdown moves on to the next phase, so that a slow phase
+// does not starve the ones after it. The time a phase leaves unused is added to the budget of
+// the next phase that has one. The timeout of the whole shutdown still applies, and critical
+// functions are not bound by budgets. Giving a budget to an undeclared phase makes New panic.
+//
+// Example:
+//
+//	c := closer.New(
+//		closer.WithTimeout(30*time.Second),
+//		closer.WithPhase("drain", 100),
+//		closer.WithPhase("flush", -100),
+//		closer.WithPhaseBudget("drain", 20*time.Second),
+//		closer.WithPhaseBudget("flush", 5*time.Second), // plus what drain left over
+//	)
+func WithPhaseBudget(name string, d time.Duration) Option {
+	return func(o *options) {
+		if o.budgets == nil {
+			o.budgets = make(map[string]time.Duration)
+		}
+		o.budgets[name] = d
+	}
+}
+
+// phaseBudgets returns the budgets of named phases by priority.
+func phaseBudgets(phases map[string]int, budgets map[string]time.Duration) map[int]time.Duration {
+	if len(budgets) == 0 {
+		return nil
+	}
+	byPrio := make(map[int]time.Duration, len(budgets))
+	for name, d := range budgets {
+		prio, ok := phases[name]
+		if !ok {
+			panic("closer: unknown phase " + name)
+		}
+		byPrio[prio] = d
+	}
+	return byPrio
+}
+
+// budgeter derives the contexts of the steps of a shutdown from their phase budgets.
+type budgeter struct {
+	budgets  map[int]time.Duration // budgets by priority, nil if there are none
+	active   bool                  // whether a budgeted phase is running
+	prio     int                   // priority of the running budgeted phase
+	ctx      context.Context       // context of the running budgeted phase
+	cancel   context.CancelFunc
+	deadline time.Time
+	clock    Clock         // clock of the running budgeted phase
+	carry    time.Duration // time left unused by the previous budgeted phases
+}
+
+// context returns the context s runs with: ctx bounded by the budget of its phase, if any.
+func (b *budgeter) context(ctx context.Context, s step) context.Context {
+	if b.budgets == nil {
+		return ctx
+	}
+	prio := s.funcs[0].prio
+	if b.active && b.prio == prio {
+		return b.ctx
+	}
+	b.end()
+	budget, ok := b.budgets[prio]
+	if !ok {
+		return ctx
+	}
+	b.active, b.prio, b.clock = true, prio, clockFrom(ctx)
+	b.deadline = b.clock.Now().Add(budget + b.carry)
+	b.ctx, b.cancel = withDeadline(ctx, b.deadline)
+	return b.ctx
+}
+
+// end ends the running budgeted phase, if any, carrying over the time it left unused.
+func (b *budgeter) end() {
+	if !b.active {
+		return
+	}
+	b.cancel()
+	b.carry = max(b.deadline.Sub(b.clock.Now()), 0)
+	b.active = false
+}
@@ -0,0 +1,63 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPhaseBudget verifies that a phase exceeding its budget is abandoned and that the next
+// phase still runs.
+func TestPhaseBudget(t *testing.T) {
+	c := New(WithPhase("drain", 100), WithPhase("flush", -100), WithPhaseBudget("drain", 20*time.Millisecond))
+	c.addContext([]Option{InPhase("drain"), withName("consumer")}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	flushed := false
+	c.AddNamed("metrics", func() error { flushed = true; return nil }, InPhase("flush"))
+
+	err := c.CloseAll()
+	if err == nil || !strings.Contains(err.Error(), "consumer") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected consumer to exceed the budget, got %v", err)
+	}
+	if !flushed {
+		t.Error("expected flush phase to run after the drain budget was spent")
+	}
+}
+
+// TestPhaseBudgetCarry verifies that the time a phase leaves unused is added to the budget
+// of the next one.
+func TestPhaseBudgetCarry(t *testing.T) {
+	c := New(
+		WithPhase("drain", 100),
+		WithPhase("flush", -100),
+		WithPhaseBudget("drain", time.Second),
+		WithPhaseBudget("flush", time.Millisecond),
+	)
+	c.AddNamed("consumer", func() error { return nil }, InPhase("drain"))
+	c.addContext([]Option{InPhase("flush"), withName("metrics")}, func(ctx context.Context) error {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected flush to use the time left by drain, got %v", err)
+	}
+}
+
+// TestPhaseBudgetUnknownPhase verifies that a budget for an undeclared phase panics.
+func TestPhaseBudgetUnknownPhase(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New(WithPhaseBudget("drain", time.Second))
+}
@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pkgDir is the directory of the package source, used to tell registration calls made by
+// the package itself from those made by its users.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callers holds the program counters of the innermost frames of a registration call, deep
+// enough to include the first frame outside the package for every way of registering.
+type callers [6]uintptr
+
+// sites caches the resolved location of every registration call stack seen so far, as
+// resolving it takes longer than registering a function. Functions are typically
+// registered from a small number of places, so the cache stays small.
+var sites struct {
+	sync.RWMutex
+	m map[callers]string
+}
+
+// caller returns the file and line of the call that registered a function, as "file:line":
+// the innermost call made from outside the package, skipping the package's own frames, such
+// as those of Add calling register. It returns an empty string if they are unknown.
+func caller() string {
+	var pcs callers
+	runtime.Callers(3, pcs[:])
+	sites.RLock()
+	site, ok := sites.m[pcs]
+	sites.RUnlock()
+	if ok {
+		return site
+	}
+	site = pcs.resolve()
+	sites.Lock()
+	if sites.m == nil {
+		sites.m = make(map[callers]string)
+	}
+	sites.m[pcs] = site
+	sites.Unlock()
+	return site
+}
+
+// resolve returns the location of the first frame of pcs outside the package.
+func (pcs callers) resolve() string {
+	n := 0
+	for n < len(pcs) && pcs[n] != 0 {
+		n++
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+	for n > 0 {
+		f, more := frames.Next()
+		if filepath.Dir(f.File) != pkgDir || strings.HasSuffix(f.File, "_test.go") {
+			return f.File + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// previousLine returns the location of the line before the one calling it, as reported for
+// registrations.
+func previousLine() string {
+	_, file, n, _ := runtime.Caller(1)
+	return file + ":" + strconv.Itoa(n-1)
+}
+
+// TestCaller verifies that the call site of a registration is reported, whichever way the
+// function was registered, and that it is logged for failed functions.
+func TestCaller(t *testing.T) {
+	buf := captureLog(t)
+	prev := SetGlobal(New())
+	t.Cleanup(func() { SetGlobal(prev) })
+
+	c := New()
+	boom := func() error { return errors.New("boom") }
+	var expected []string
+	c.Add(boom)
+	expected = append(expected, previousLine())
+	c.AddNamed("db", boom)
+	expected = append(expected, previousLine())
+	c.Group(WithLabel("kafka")).Add(boom)
+	expected = append(expected, previousLine())
+	c.AddStopper(&fakeStopper{})
+	expected = append(expected, previousLine())
+	c.AddFunc(func() {})
+	expected = append(expected, previousLine())
+	AddValue(c, &fakeCloser{})
+	expected = append(expected, previousLine())
+	c.AddCloser(&fakeCloser{})
+	expected = append(expected, previousLine())
+	c.CloseAll()
+
+	for i, f := range c.Report().Funcs {
+		if f.Caller != expected[i] {
+			t.Errorf("expected #%d registered at %s, got %q", i, expected[i], f.Caller)
+		}
+	}
+	if out := buf.String(); !strings.Contains(out, "caller="+expected[0]) {
+		t.Errorf("expected call site to be logged, got %q", out)
+	}
+
+	Add(boom)
+	site := previousLine()
+	CloseAll()
+	if f := Global().Report().Funcs[0]; f.Caller != site {
+		t.Errorf("expected global registration at %s, got %q", site, f.Caller)
+	}
+}
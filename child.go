@@ -0,0 +1,30 @@
+package closer
+
+import "context"
+
+// Child creates a Closer for a part of the application, such as a module or a tenant, and
+// registers its shutdown as a single closing function of c under the given name. The child
+// can be closed on its own earlier, for example when a tenant is unloaded; otherwise it is
+// closed by c's shutdown, bounded by c's deadline. Either way, errors of the child's
+// functions are reported by the child and again by c, prefixed with name.
+//
+// opts configure both the child, as if passed to New, and its registration in c, as if passed
+// to AddNamed; options that do not apply to one of them are ignored there. The child uses c's
+// logger unless opts give it another one.
+//
+// Example:
+//
+//	tenant := c.Child("tenant-"+id, closer.WithPriority(10))
+//	tenant.AddNamed("db", db.Close)
+//	...
+//	tenant.CloseAll() // when the tenant is unloaded
+func (c *Closer) Child(name string, opts ...Option) *Closer {
+	if c.logger != nil {
+		opts = append([]Option{WithLogger(c.logger)}, opts...)
+	}
+	child := New(opts...)
+	c.addContext(append([]Option{withName(name)}, opts...), func(ctx context.Context) error {
+		return child.CloseAllContext(ctx)
+	})
+	return child
+}
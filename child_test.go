@@ -0,0 +1,50 @@
+package closer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// TestChild verifies that a child is closed by its parent and that its errors are reported
+// by both.
+func TestChild(t *testing.T) {
+	c := New()
+	child := c.Child("tenant")
+	var ran int32
+	child.Add(func() error {
+		atomic.AddInt32(&ran, 1)
+		return errors.New("boom")
+	})
+
+	err := c.CloseAll()
+	if err == nil || err.Error() != "tenant #0: #0: boom" {
+		t.Errorf("expected child error prefixed with its name, got %v", err)
+	}
+	if cerr := child.Wait(); cerr == nil || cerr.Error() != "#0: boom" {
+		t.Errorf("expected child to report its own error, got %v", cerr)
+	}
+	if n := atomic.LoadInt32(&ran); n != 1 {
+		t.Errorf("expected child function to run once, got %d", n)
+	}
+}
+
+// TestChildClosedEarly verifies that a child closed on its own does not run its functions
+// again when the parent shuts down.
+func TestChildClosedEarly(t *testing.T) {
+	c := New()
+	child := c.Child("tenant")
+	var ran int32
+	child.Add(func() error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	})
+
+	child.CloseAll()
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&ran); n != 1 {
+		t.Errorf("expected child function to run once, got %d", n)
+	}
+}
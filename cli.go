@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// DefaultInterruptMessage is the message NewCLI prints on the first interrupt.
+const DefaultInterruptMessage = "shutting down, press Ctrl-C again to force quit"
+
+// interruptMessage is the message printed by WithInterruptMessage.
+type interruptMessage struct {
+	w    io.Writer
+	text string
+}
+
+// print prints the message for the interrupt sig.
+func (m *interruptMessage) print(sig os.Signal) {
+	fmt.Fprintln(m.w, m.text)
+}
+
+// WithInterruptMessage makes New create a Closer that prints text, followed by a newline, to
+// w when os.Interrupt initiates shutdown, so that the user of an interactive tool knows that
+// Ctrl-C was taken into account. It has no effect unless os.Interrupt is given to
+// WithSignals, and an empty text prints nothing.
+//
+// Example:
+//
+//	c := closer.NewCLI(closer.WithInterruptMessage(os.Stderr, "stopping, Ctrl-C again to abort"))
+func WithInterruptMessage(w io.Writer, text string) Option {
+	return func(o *options) {
+		o.interruptMessage = &interruptMessage{w: w, text: text}
+	}
+}
+
+// NewCLI creates a Closer for interactive command-line tools: the first Ctrl-C, or SIGTERM,
+// initiates shutdown, canceling the Context of the Closer, and prints DefaultInterruptMessage
+// to standard error; a second one exits the process at once with code 130, as shells do for
+// a process interrupted by SIGINT. ExitCode also returns 130 after a shutdown initiated by
+// Ctrl-C. opts are applied after these settings, so they can override them, for example
+// WithInterruptMessage to change the message or WithForceExit to change the exit code.
+//
+// Example:
+//
+//	c := closer.NewCLI(closer.WithTimeout(5 * time.Second))
+//	if err := run(c.Context()); err != nil { // returns early once Ctrl-C cancels the context
+//		log.Print(err)
+//	}
+//	c.CloseAllAndExit()
+func NewCLI(opts ...Option) *Closer {
+	return New(append([]Option{
+		WithSignals(os.Interrupt, syscall.SIGTERM),
+		WithForceExit(130),
+		WithSignalExitCode(os.Interrupt, 130),
+		WithInterruptMessage(os.Stderr, DefaultInterruptMessage),
+	}, opts...)...)
+}
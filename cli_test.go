@@ -0,0 +1,67 @@
+package closer
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestNewCLI verifies that the first interrupt prints the message and initiates shutdown,
+// and that the second one exits with code 130.
+func TestNewCLI(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	var out syncBuffer
+	c := NewCLI(WithNotifier(&fakeNotifier{}), WithInterruptMessage(&out, "bye"))
+	started := make(chan struct{})
+	block := make(chan struct{})
+	defer close(block)
+	c.Add(func() error {
+		close(started)
+		<-block
+		return nil
+	})
+
+	c.watcher.ch <- os.Interrupt
+	<-started
+	if got := out.String(); got != "bye\n" {
+		t.Errorf("expected %q, got %q", "bye\n", got)
+	}
+	if err := c.Context().Err(); err == nil {
+		t.Error("expected the context to be canceled")
+	}
+	c.watcher.ch <- os.Interrupt
+
+	select {
+	case code := <-codes:
+		if code != 130 {
+			t.Errorf("expected exit code 130, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected process to be forced to exit")
+	}
+}
+
+// TestNewCLIExitCode verifies that a shutdown initiated by an interrupt exits with code 130.
+func TestNewCLIExitCode(t *testing.T) {
+	c := NewCLI(WithNotifier(&fakeNotifier{}), WithInterruptMessage(&syncBuffer{}, ""))
+	c.watcher.ch <- os.Interrupt
+	if code := c.ExitCode(); code != 130 {
+		t.Errorf("expected exit code 130, got %d", code)
+	}
+}
+
+// TestWithInterruptMessageWithoutInterrupt verifies that no message is printed unless
+// os.Interrupt initiates shutdown.
+func TestWithInterruptMessageWithoutInterrupt(t *testing.T) {
+	term := testSignal("term")
+	var out syncBuffer
+	c := New(WithSignals(term), WithInterruptMessage(&out, "bye"))
+	c.watcher.ch <- term
+	c.Wait()
+	if got := out.String(); got != "" {
+		t.Errorf("expected no message, got %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package closer
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of a Closer, given with WithClock, so that tests of timeouts,
+// delays and progress events run without waiting for real time to pass. The closertest
+// package provides a fake implementation.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a Timer sending the current time on its channel once d has elapsed,
+	// as time.NewTimer does.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a Ticker sending the current time on its channel every d, as
+	// time.NewTicker does.
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f in its own goroutine once d has elapsed, as time.AfterFunc does. The
+	// channel of the returned Timer is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single event of a Clock, as a *time.Timer is.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a recurring event of a Clock, as a *time.Ticker is.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock makes New create a Closer that takes the time from clock instead of the system
+// clock for the shutdown timeout, the timeouts and cancel grace of closing functions,
+// deadlines of phase budgets and of WithHardDeadline, WithDrainDelay, progress events, the
+// backoffs of WithRetry and AddLease, the polling of AddDB, AddSink, WithMemoryWatchdog,
+// WithTriggerFile and the systemd watchdog, the timeout of WithRestart, and the times of
+// reports, events and WithStateFile. The system clock still sets the deadlines of contexts
+// given by the caller, such as to CloseAllContext or Restart, the modification times of
+// trigger files, and the timestamps added by loggers.
+//
+// Contexts bounded by a clock other than the system clock report their deadline passing with
+// context.DeadlineExceeded as their Err, but contexts derived from them report
+// context.Canceled; context.Cause tells deadlines apart.
+//
+// Example:
+//
+//	clock := closertest.NewFakeClock(time.Now())
+//	c := closer.New(closer.WithClock(clock), closer.WithTimeout(30*time.Second))
+//	go c.CloseAll()
+//	clock.Advance(30 * time.Second)
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// clockKey is the context key of the Clock of a shutdown other than the system clock.
+type clockKey struct{}
+
+// withClock returns ctx carrying clock, for the timeouts of the functions it is handed to.
+func withClock(ctx context.Context, clock Clock) context.Context {
+	if _, ok := clock.(systemClock); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// clockFrom returns the Clock carried by ctx, the system clock if none.
+func clockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return systemClock{}
+}
+
+// withTimeout is context.WithTimeout, with the deadline measured by the Clock of ctx.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	clock := clockFrom(ctx)
+	if _, ok := clock.(systemClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	x, cancel := withExtendableTimeout(ctx, clock, d, 0, nil)
+	return x, cancel
+}
+
+// withDeadline is context.WithDeadline, with the deadline measured by the Clock of ctx.
+func withDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	clock := clockFrom(ctx)
+	if _, ok := clock.(systemClock); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return withTimeout(ctx, deadline.Sub(clock.Now()))
+}
+
+// systemClock is the Clock of the system, used unless WithClock is given.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+// systemTimer is a Timer of the system clock.
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// systemTicker is a Ticker of the system clock.
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
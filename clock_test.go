@@ -0,0 +1,111 @@
+package closer_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nzb3/closer"
+	"github.com/nzb3/closer/closertest"
+)
+
+// TestWithClockRetry verifies that the backoffs of WithRetry and the durations of the report
+// are measured by the clock given with WithClock.
+func TestWithClockRetry(t *testing.T) {
+	clock := closertest.NewFakeClock(time.Now())
+	c := closer.New(closer.WithClock(clock))
+	c.AddNamed("upload", func() error { return errors.New("unavailable") }, closer.WithRetry(3, time.Hour))
+	go c.CloseAll()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Hour)
+
+	if err := c.Wait(); err == nil {
+		t.Fatal("expected the last attempt to fail, got no error")
+	}
+	r := c.Report()
+	if len(r.Funcs) != 1 || r.Funcs[0].Attempts != 3 {
+		t.Fatalf("expected one function attempted 3 times, got %+v", r.Funcs)
+	}
+	if r.Duration != 3*time.Hour {
+		t.Errorf("expected the shutdown to last %v, got %v", 3*time.Hour, r.Duration)
+	}
+}
+
+// TestWithClockDB verifies that AddDB polls the connections in use with the clock given with
+// WithClock.
+func TestWithClockDB(t *testing.T) {
+	db, _ := sql.Open("closer-fake", "")
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := closertest.NewFakeClock(time.Now())
+	c := closer.New(closer.WithClock(clock))
+	c.AddDB(db)
+
+	errc := make(chan error, 1)
+	go func() { errc <- c.CloseAll() }()
+	clock.BlockUntil(1)
+	conn.Close()
+	select {
+	case err := <-errc:
+		t.Fatalf("expected the pool to be polled on the clock only, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	if err := <-errc; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestWithClockStateFile verifies that the lines of the state file are timed by the clock
+// given with WithClock.
+func TestWithClockStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown.state")
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := closertest.NewFakeClock(start)
+	c := closer.New(closer.WithClock(clock), closer.WithStateFile(path))
+	c.AddNamed("db", func() error {
+		clock.Advance(time.Minute)
+		return nil
+	})
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want := map[string]time.Time{
+		"started":   start,
+		"finished":  start.Add(time.Minute),
+		"completed": start.Add(time.Minute),
+	}
+	lines := bufio.NewScanner(f)
+	n := 0
+	for ; lines.Scan(); n++ {
+		var line struct {
+			Event string    `json:"event"`
+			Time  time.Time `json:"time"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if !line.Time.Equal(want[line.Event]) {
+			t.Errorf("expected %s at %v, got %v", line.Event, want[line.Event], line.Time)
+		}
+	}
+	if n != len(want) {
+		t.Errorf("expected %d lines, got %d", len(want), n)
+	}
+}
@@ -3,114 +3,1309 @@
 package closer
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// globalCloser is the default instance of Closer used for package-level functions.
-var globalCloser = New()
+var (
+	// ErrTimeout is returned for a closing function that did not finish within its timeout,
+	// or before the shutdown deadline.
+	ErrTimeout = errors.New("closer: function timed out")
+
+	// ErrClosed is returned by operations that are not allowed once shutdown has started.
+	ErrClosed = errors.New("closer: shutdown already started")
+
+	// ErrDependencyCycle is reported for closing functions whose After dependencies form a cycle.
+	ErrDependencyCycle = errors.New("closer: dependency cycle")
+
+	// ErrSkipped is reported for closing functions not run because an earlier one failed, as
+	// configured with WithFailFast.
+	ErrSkipped = errors.New("closer: skipped after an earlier failure")
+)
+
+// globalCloser holds the default instance of Closer used for package-level functions.
+// It is accessed atomically so that ResetGlobal can replace it concurrently with their use.
+var globalCloser atomic.Pointer[Closer]
+
+func init() {
+	globalCloser.Store(New())
+}
+
+// ResetGlobal replaces the global closer instance with a fresh one and returns the previous
+// instance, which is neither closed nor waited for. It is intended for tests that use the
+// package-level functions and need a live global closer after a previous test closed it.
+// The swap is safe to perform concurrently with calls to the package-level functions.
+func ResetGlobal() *Closer {
+	return globalCloser.Swap(New())
+}
+
+// Global returns the global closer instance used by the package-level functions, for
+// example to register functions with options or to read its Report.
+func Global() *Closer {
+	return globalCloser.Load()
+}
+
+// SetGlobal makes c the global closer instance used by the package-level functions and
+// returns the previous instance, which is neither closed nor waited for. It lets applications
+// configure the global closer, with signals, a timeout or a logger, and tests substitute
+// their own. The swap is safe to perform concurrently with calls to the package-level
+// functions. It panics if c is nil.
+//
+// Example:
+//
+//	closer.SetGlobal(closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithTimeout(30*time.Second)))
+func SetGlobal(c *Closer) *Closer {
+	mustNotBeNil(c, "Closer")
+	return globalCloser.Swap(c)
+}
 
 // Add registers one or more closing functions to the global closer instance.
 // These functions will be executed concurrently when CloseAll is called.
 func Add(f ...closeFunc) {
-	globalCloser.Add(f...)
+	globalCloser.Load().Add(f...)
 }
 
-// Wait blocks until all registered closing functions have completed execution.
-func Wait() {
-	globalCloser.Wait()
+// AddNamed registers a single closing function under the given name to the global closer
+// instance. The name identifies the function in log events and in the returned errors.
+func AddNamed(name string, f closeFunc, opts ...Option) {
+	globalCloser.Load().AddNamed(name, f, opts...)
+}
+
+// Wait blocks until all registered closing functions have completed execution
+// and returns the errors they produced.
+func Wait() error {
+	return globalCloser.Load().Wait()
 }
 
 // CloseAll triggers the execution of all registered closing functions in the global closer instance.
-// All functions are executed concurrently, and any errors are logged.
-func CloseAll() {
-	globalCloser.CloseAll()
+// All functions are executed concurrently, and any errors are logged and returned.
+func CloseAll() error {
+	return globalCloser.Load().CloseAll()
 }
 
 // closeFunc represents a function that performs cleanup operations and may return an error.
 type closeFunc func() error
 
+// contextFunc is a closing function that receives the shutdown context.
+type contextFunc func(ctx context.Context) error
+
+// withoutContext adapts f to a contextFunc that ignores the shutdown context.
+func withoutContext(f closeFunc) contextFunc {
+	return func(context.Context) error {
+		return f()
+	}
+}
+
+// entry is a single registered closing function together with its options.
+type entry struct {
+	index    int           // position of the function in registration order
+	name     string        // optional name used to identify the function in logs
+	label    string        // optional label shared by related functions
+	timeout  time.Duration // maximum time the function may run, zero means unlimited
+	grace    time.Duration // time the function may take to return once its context is canceled
+	flusher  bool          // whether the function also runs on Flush
+	critical bool          // whether the function runs last, regardless of the deadline
+	quiescer bool          // whether the function stops intake and runs first, or on Quiesce
+	panics   PanicPolicy   // what the shutdown does when the function panics
+	optional bool          // whether a failure of the function does not fail the shutdown
+	serial   string        // key of the functions this one must not run concurrently with
+	prio     int           // priority, functions with higher priorities run first
+	after    []string      // names or labels of the functions this one must run after
+	fn       contextFunc
+	rehearse contextFunc   // dry run of the function executed by Rehearse, nil to do nothing
+	tries    *atomic.Int32 // attempts of the latest run if the function is retried, nil otherwise
+	retry    *retryPolicy  // retry policy of the function, nil if it is not retried
+	caller   string        // file and line of the call registering the function, empty if unknown
+}
+
+// String returns a human-readable identifier of the entry for log messages,
+// made of its label, name and registration index.
+func (e entry) String() string {
+	id := fmt.Sprintf("#%d", e.index)
+	switch {
+	case e.label != "" && e.name != "":
+		return e.label + "/" + e.name + " " + id
+	case e.label != "":
+		return e.label + " " + id
+	case e.name != "":
+		return e.name + " " + id
+	}
+	return id
+}
+
+// key returns the name of the entry, or its identifier if it has none, as used in the
+// failures of a ShutdownError.
+func (e entry) key() string {
+	if e.name != "" {
+		return e.name
+	}
+	return e.String()
+}
+
+// run executes the entry's function with ctx, giving up on it once its timeout elapses or
+// ctx is done, and its grace has passed since. A function that is given up on keeps running
+// in the background; its result is discarded. A panic in the function is returned as
+// a *PanicError.
+func (e entry) run(ctx context.Context) error {
+	fnCtx := ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		fnCtx, cancel = withTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	if fnCtx.Done() == nil {
+		return call(e.fn, fnCtx)
+	}
+
+	done, fn := make(chan error, 1), e.fn
+	go func() {
+		done <- call(fn, fnCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-fnCtx.Done():
+		if e.grace > 0 {
+			grace := clockFrom(ctx).NewTimer(e.grace)
+			select {
+			case err := <-done:
+				grace.Stop()
+				return err
+			case <-grace.C():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return notFinished(err)
+		}
+		return fmt.Errorf("%w after %v", ErrTimeout, e.timeout)
+	}
+}
+
+// runWatched executes the entry's function as run does, for callers that themselves give up
+// on it once ctx is done: without a timeout of its own, the function is called directly,
+// saving the goroutine and channel run takes to stop waiting for it.
+func (e entry) runWatched(ctx context.Context) error {
+	if e.timeout > 0 {
+		return e.run(ctx)
+	}
+	err := call(e.fn, ctx)
+	if ctxErr := ctx.Err(); ctxErr != nil && e.grace == 0 {
+		// As with run, a function returning after ctx is done has been given up on.
+		return notFinished(ctxErr)
+	}
+	return err
+}
+
+// notFinished returns the error reported for a function abandoned because the shutdown
+// context was done with err.
+func notFinished(err error) error {
+	return notFinishedError{err}
+}
+
+// notFinishedError is the error of a function abandoned because the shutdown context was
+// done. Past the shutdown deadline, it also wraps ErrTimeout.
+type notFinishedError struct{ err error }
+
+func (e notFinishedError) Error() string { return "did not finish: " + e.err.Error() }
+
+func (e notFinishedError) Unwrap() []error {
+	if errors.Is(e.err, context.DeadlineExceeded) {
+		return []error{e.err, ErrTimeout}
+	}
+	return []error{e.err}
+}
+
 // Closer manages a collection of closing functions and provides thread-safe operations
 // for adding and executing these functions.
 type Closer struct {
-	mu    sync.Mutex    // protects access to funcs slice
-	once  sync.Once     // ensures CloseAll is executed only once
-	done  chan struct{} // signals when all closing functions have completed
-	funcs []closeFunc   // collection of functions to be executed on close
+	// Configuration, immutable after New.
+	timeout    time.Duration           // bounds the whole shutdown, zero means unlimited
+	extension  time.Duration           // how much functions may extend the timeout in total, see WithDeadlineExtension
+	lifo       bool                    // run functions sequentially in reverse registration order
+	sequential bool                    // run functions sequentially in registration order
+	ordered    bool                    // start concurrent functions one after another in registration order
+	failFast   bool                    // skip the remaining functions once one has failed
+	intercept  []Interceptor           // wrap every registered function, outermost first
+	phases     map[string]int          // priorities of the named phases
+	budgets    map[int]time.Duration   // time budgets of the named phases by priority, nil if none
+	forceExit  *forceExit              // exit on a shutdown signal received during shutdown, nil to not exit
+	watchdog   *hardDeadline           // exit when shutdown takes too long, nil to not exit
+	panics     PanicPolicy             // panic policy of functions registered without one, zero to continue
+	grace      time.Duration           // cancel grace of functions registered without one, see WithCancelGrace
+	logSteps   bool                    // log the steps of the shutdown when it starts
+	logger     Logger                  // receives shutdown events, nil for slog.Default
+	metrics    Metrics                 // receives shutdown measurements, nil to not measure
+	tracer     Tracer                  // traces the shutdown, nil to not trace
+	limit      int                     // maximum number of functions running at once, zero means unlimited
+	late       LatePolicy              // what to do with functions registered once shutdown has started
+	reloadSig  os.Signal               // signal running the reload callbacks
+	notifier   Notifier                // source of the signals watched
+	drainDelay time.Duration           // delay between initiating shutdown and running closing functions
+	ignored    []error                 // errors treated as success
+	retry      *retryPolicy            // retry policy of functions registered without one, nil to not retry
+	progress   time.Duration           // interval between progress events during shutdown, zero to not log them
+	deadline   io.Writer               // destination of the stack dump written when the deadline passes, nil to skip it
+	onError    ErrorHandler            // receives the failures of functions instead of the logger, nil to log them
+	barriers   []barrier               // run before closing functions, see WithPreShutdownBarrier
+	collapse   int                     // number of failures with the same error past which they are logged once, negative to never
+	skipNil    bool                    // skip nil functions instead of panicking
+	dups       *duplicates             // function values registered so far, nil to not check for duplicates
+	required   []string                // names of functions expected to be registered, see Require
+	warnEmpty  bool                    // warn when shutdown finds no function registered
+	history    DurationStore           // durations of previous shutdowns, nil to not record them
+	pidFile    string                  // file holding the process ID, removed last during shutdown, empty if none
+	stateFile  string                  // file recording the progress of shutdown, empty if none
+	dirty      *Report                 // previous shutdown recorded in stateFile, nil if it was clean
+	exitCodes  exitCodes               // exit codes returned by ExitCode other than the defaults
+	clock      Clock                   // source of time, see WithClock
+	memory     *memoryWatchdog         // watchdog started again by Reset, nil if none
+	trigger    string                  // file watched again by Reset, empty if none
+	ctx        context.Context         // canceled together with setting closing
+	cancel     context.CancelCauseFunc // cancels ctx
+
+	funcs      registry                   // functions to be executed on close, with locks of its own
+	bound      atomic.Pointer[testLogger] // logger of the test bound with BindTest, nil if none
+	flushMu    sync.Mutex                 // serializes Flush calls with each other and with CloseAll
+	reloadMu   sync.Mutex                 // serializes reloads
+	restartMu  sync.Mutex                 // serializes restarts
+	keysMu     sync.Mutex                 // protects keys and serializes keyed registrations
+	quiesceMu  sync.Mutex                 // held while Quiesce runs quiescers
+	quiesced   atomic.Bool                // set once Quiesce has been called, reset by Reset
+	startMu    sync.Mutex                 // protects components and serializes Start calls
+	components []component                // components started with Start, in start order
+	keys       map[string]slot            // slots of the functions registered with AddKeyed
+	resources  map[any]bool               // resources registered with AddResource
+	tagsMu     sync.Mutex                 // protects tagged
+	tagged     map[string][]slot          // functions registered with WithTags, by tag
+
+	mu         sync.Mutex      // protects the fields below unless noted otherwise
+	reloads    []func() error  // callbacks registered with OnReload
+	held       *sync.Cond      // signaled when the last outstanding hold is released or task returns
+	holds      int             // number of outstanding holds delaying shutdown
+	closing    bool            // set once CloseAll has been requested
+	reason     Reason          // what initiated shutdown, set together with closing
+	tasks      int             // number of tasks started with Go that have not returned yet
+	taskErrs   []error         // errors to report for tasks that have returned
+	fatal      []error         // errors given to FailWith, prefixed
+	settled    bool            // set once the error of the shutdown is final, FailWith no longer adds to it
+	reports    *reportRing     // reports of the last shutdowns and rehearsals, nil to not keep them
+	startHooks []func(Reason)  // hooks run when shutdown is initiated
+	endHooks   []func(Report)  // hooks run when shutdown has completed
+	triggers   int             // number of requests to shut down, the first one running it, reset by Reset
+	done       chan struct{}   // closed once shutdown has completed, replaced by Reset
+	started    bool            // set once CloseAll has taken its snapshot of funcs
+	registered []entry         // snapshot of funcs taken by CloseAll, restored by Reset
+	closed     bool            // set once CloseAll has completed, before done is signaled
+	steps      []step          // shutdown sequence executed by CloseAll, set together with started
+	err        error           // errors returned by closing functions, set before done is signaled
+	report     Report          // shutdown summary without Funcs, set before done is signaled
+	results    []result        // outcome of every function in registration order, set with report
+	watcher    *signalWatcher  // dispatches OS signals, nil if no signals are watched
+	errs       chan error      // streams errors during shutdown, nil until Errors is called
+	subs       []chan Event    // channels returned by Subscribe, closed once shutdown completes
+	quiescence []result        // outcome of the quiescers run by Quiesce, in registration order
+	current    *collector      // outcome of the functions being run by CloseAll, nil otherwise
+	listeners  []keyedListener // listeners opened with Listen, handed over by Restart
 }
 
-// New creates a new Closer instance. If OS signals are provided, it will automatically
-// trigger CloseAll when any of these signals are received.
+// New creates a new Closer instance configured by opts. If WithSignals is given, it will
+// automatically trigger CloseAll when any of these signals are received.
 //
 // Example:
 //
-//	closer := New(syscall.SIGINT, syscall.SIGTERM)
-func New(sigs ...os.Signal) *Closer {
-	c := &Closer{done: make(chan struct{}, 1)}
-	if len(sigs) > 0 {
-		go func() {
-			ch := make(chan os.Signal, 1)
-			signal.Notify(ch, sigs...)
-			<-ch
-			signal.Stop(ch)
-			c.CloseAll()
-		}()
+//	closer := New(WithSignals(syscall.SIGINT, syscall.SIGTERM), WithTimeout(30*time.Second))
+func New(opts ...Option) *Closer {
+	o := newOptions(opts)
+	c := &Closer{
+		done:       make(chan struct{}),
+		timeout:    o.timeout,
+		extension:  o.extension,
+		lifo:       o.lifo,
+		sequential: o.sequential,
+		ordered:    o.ordered,
+		panics:     o.panics,
+		grace:      o.cancelGrace,
+		logSteps:   o.logPlan,
+		failFast:   o.failFast,
+		intercept:  o.interceptors,
+		phases:     o.phases,
+		budgets:    phaseBudgets(o.phases, o.budgets),
+		logger:     o.logger,
+		metrics:    o.metrics,
+		tracer:     o.tracer,
+		limit:      o.limit,
+		late:       o.late,
+		drainDelay: o.drainDelay,
+		ignored:    o.ignored,
+		retry:      o.retry,
+		progress:   o.progress,
+		deadline:   o.deadlineDump,
+		onError:    o.onError,
+		collapse:   o.collapse,
+		barriers:   o.barriers,
+		skipNil:    o.skipNil,
+		required:   o.required,
+		warnEmpty:  o.warnEmpty,
+		pidFile:    o.pidFile,
+		stateFile:  o.stateFile,
+		history:    o.history,
+		exitCodes:  o.exitCodes,
+		reports:    newReportRing(o.reportHistory),
+		clock:      o.clock,
+	}
+	if c.clock == nil {
+		c.clock = systemClock{}
+	}
+	c.notifier = o.notifier
+	if c.notifier == nil {
+		c.notifier = osNotifier{}
+	}
+	c.reloadSig = o.reloadSignal
+	if c.reloadSig == nil {
+		c.reloadSig = defaultReloadSignal
+	}
+	if o.forceExit {
+		c.forceExit = &forceExit{code: o.exitCode, dump: o.stackDump}
+	}
+	if o.hardDeadline != nil {
+		c.watchdog = o.hardDeadline
+		c.watchdog.dump = o.stackDump
+	}
+	if o.terminationLog != "" {
+		c.OnShutdownEnd(func(r Report) { c.writeTerminationLog(o.terminationLog, r) })
+	}
+	if o.jsonReport != nil {
+		c.OnShutdownEnd(func(r Report) { o.jsonReport.write(c.log(), r) })
+	}
+	if o.duplicates {
+		c.dups = newDuplicates()
+	}
+	if c.collapse == 0 {
+		c.collapse = defaultCollapse
+	}
+	c.held = sync.NewCond(&c.mu)
+	c.ctx, c.cancel = context.WithCancelCause(context.Background())
+	if c.pidFile != "" {
+		c.writePIDFile()
+	}
+	if c.stateFile != "" {
+		c.checkStateFile(c.stateFile)
+	}
+	if o.expvar != "" {
+		c.publishExpvar(o.expvar)
+	}
+	if o.systemd {
+		c.reportToSystemd(o.systemdExtend)
+	}
+	if len(o.signals) > 0 {
+		c.watcher = newSignalWatcher(c)
+		c.watcher.shutdownOn(o.signals)
+		if m := o.interruptMessage; m != nil && m.text != "" && slices.Contains(o.signals, os.Interrupt) {
+			c.OnSignal(os.Interrupt, m.print)
+		}
+	}
+	c.memory, c.trigger = o.memory, o.triggerFile
+	c.startWatchdogs()
+	if o.supervised {
+		c.superviseBy()
+	}
+	if o.restartSignal != nil {
+		timeout := o.restartTimeout
+		c.onSignal(o.restartSignal, func(c *Closer, _ os.Signal) { go c.restartOnSignal(timeout) })
 	}
 	return c
 }
 
+// NewWithContext creates a Closer like New that also initiates shutdown when ctx is done, in
+// addition to the signals given with WithSignals, for applications built around a root
+// context such as the one of a command-line framework. The shutdown is reported with
+// ReasonContext and the cause of the end of ctx. Its closing functions do not receive ctx,
+// which is already done, but a context bounded by WithTimeout as with CloseAll.
+//
+// Example:
+//
+//	c := closer.NewWithContext(cmd.Context(), closer.WithTimeout(10*time.Second))
+func NewWithContext(ctx context.Context, opts ...Option) *Closer {
+	c := New(opts...)
+	context.AfterFunc(ctx, func() {
+		c.closeAll(context.Background(), Reason{Kind: ReasonContext, Err: context.Cause(ctx)})
+	})
+	return c
+}
+
 // Add registers one or more closing functions to be executed when CloseAll is called.
 // This method is thread-safe and can be called concurrently.
 func (c *Closer) Add(f ...closeFunc) {
-	c.mu.Lock()
-	c.funcs = append(c.funcs, f...)
-	c.mu.Unlock()
+	c.add(nil, f...)
+}
+
+// AddNamed registers a single closing function under the given name. The name identifies
+// the function in log messages. Options configure how the function is executed.
+func (c *Closer) AddNamed(name string, f closeFunc, opts ...Option) {
+	c.add(append([]Option{withName(name)}, opts...), f)
+}
+
+// AddContext registers one or more closing functions that receive the shutdown context.
+// The context is canceled when the shutdown deadline passes, as set by WithTimeout on New or
+// by the context given to CloseAllContext, or when the function's own timeout elapses.
+//
+// Example:
+//
+//	c.AddContext(srv.Shutdown)
+func (c *Closer) AddContext(f ...contextFunc) {
+	c.addContext(nil, f...)
+}
+
+// add builds entries for the given functions using opts and stores them in the registry.
+// It returns the slot of the first function.
+func (c *Closer) add(opts []Option, fs ...closeFunc) slot {
+	fns := make([]contextFunc, 0, len(fs))
+	for _, f := range fs {
+		if c.valid(f == nil, funcValue(f)) {
+			fns = append(fns, withoutContext(f))
+		}
+	}
+	at, _ := c.register(opts, fns, false)
+	return at
+}
+
+// addContext is like add for functions that receive the shutdown context.
+func (c *Closer) addContext(opts []Option, fs ...contextFunc) slot {
+	at, _ := c.register(opts, c.validContextFuncs(fs), false)
+	return at
+}
+
+// register builds entries for fs using opts and stores them in the registry, returning the
+// slot of the first one. Once shutdown has taken its snapshot of funcs, it
+// returns ErrClosed without registering anything if strict is set, and otherwise hands the
+// functions to the late policy.
+func (c *Closer) register(opts []Option, fs []contextFunc, strict bool) (slot, error) {
+	o := newOptions(opts)
+	if o.phase != "" {
+		prio, ok := c.phases[o.phase]
+		if !ok {
+			panic("closer: unknown phase " + o.phase)
+		}
+		o.prio = prio
+	}
+	if o.retry == nil {
+		o.retry = c.retry
+	}
+	if o.panics == 0 {
+		o.panics = c.panics
+	}
+	if o.cancelGrace == 0 {
+		o.cancelGrace = c.grace
+	}
+	interceptors := append(c.intercept[:len(c.intercept):len(c.intercept)], o.interceptors...)
+	site := caller()
+	s, shard := c.funcs.lock()
+	late := s.sealed
+	if late && strict {
+		s.mu.Unlock()
+		return slot{}, ErrClosed
+	}
+	first := c.funcs.reserve(len(fs))
+	var lateFuncs []entry
+	for i, fn := range fs {
+		e := o.entry(fn)
+		e.caller = site
+		if o.retry != nil && o.retry.attempts > 1 {
+			e.tries = new(atomic.Int32)
+			e.retry = o.retry
+			e.fn = o.retry.wrap(fn, e.tries)
+		}
+		e.index = first + i
+		if len(interceptors) > 0 {
+			e.fn = intercept(interceptors, e.String(), e.fn)
+		}
+		if len(o.values) > 0 {
+			e.fn = withValues(e.fn, o.values)
+			if e.rehearse != nil {
+				e.rehearse = withValues(e.rehearse, o.values)
+			}
+		}
+		if late {
+			lateFuncs = append(lateFuncs, e)
+			continue
+		}
+		s.funcs = append(s.funcs, e)
+	}
+	s.mu.Unlock()
+
+	for _, e := range lateFuncs {
+		c.registeredLate(e)
+	}
+	if len(o.tags) > 0 && !late {
+		c.tag(o.tags, shard, first, len(fs))
+	}
+	return slot{shard: shard, index: first}, nil
+}
+
+// slot locates a registered function in the registry.
+type slot struct {
+	shard int // shard holding the function
+	index int // registration index of the function
 }
 
 // Wait blocks until all registered closing functions have completed execution.
 // This method is typically called after CloseAll to ensure all cleanup operations have finished.
-func (c *Closer) Wait() {
-	<-c.done
+// It returns the errors produced by the closing functions joined in registration order as a
+// *ShutdownError, or nil if all of them succeeded.
+func (c *Closer) Wait() error {
+	<-c.completed()
+	return c.result()
+}
+
+// completed returns the channel closed once the shutdown has completed. Any number of
+// goroutines may wait on it, before or after shutdown is initiated.
+func (c *Closer) completed() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done
+}
+
+// result returns the error of the completed shutdown.
+func (c *Closer) result() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// WaitContext is like Wait, but stops waiting when ctx is done and then returns ctx.Err().
+// The shutdown itself keeps running; WaitContext only lets the caller give up on it, for
+// example to alert or to force the process to exit.
+func (c *Closer) WaitContext(ctx context.Context) error {
+	select {
+	case <-c.completed():
+		return c.result()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitTimeout is like WaitContext with a context that expires after d, so it returns
+// context.DeadlineExceeded if the shutdown has not completed within d.
+func (c *Closer) WaitTimeout(d time.Duration) error {
+	ctx, cancel := withTimeout(withClock(context.Background(), c.clock), d)
+	defer cancel()
+	return c.WaitContext(ctx)
+}
+
+// Done returns a channel that is closed as soon as shutdown is initiated, by a call to
+// CloseAll or by a signal, before any closing function runs and even while holds delay them.
+// It lets long-running goroutines stop without being registered as closing functions.
+//
+// Example:
+//
+//	for {
+//		select {
+//		case <-c.Done():
+//			return
+//		case job := <-jobs:
+//			process(job)
+//		}
+//	}
+func (c *Closer) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Context returns a context that is canceled as soon as shutdown is initiated, when Done is
+// closed. It is meant as the root context of request handlers, consumers and background jobs,
+// so that they are canceled on shutdown. context.Cause reports ErrClosed for it.
+func (c *Closer) Context() context.Context {
+	return c.ctx
 }
 
 // CloseAll executes all registered closing functions concurrently.
 // It ensures that:
 // - Each function is executed exactly once
-// - All functions are executed concurrently
-// - Any errors returned by closing functions are logged
+// - All functions of the same priority are executed concurrently, highest priority first
+// - Any errors returned by closing functions are logged in registration order, see Logger
 // - The done channel is closed after all functions complete
-// This method is thread-safe and idempotent.
-func (c *Closer) CloseAll() {
-	c.once.Do(func() {
+// CloseAll returns the errors of the closing functions joined in registration order as a
+// *ShutdownError, each prefixed with the name and registration index of the function that
+// produced it; errors.Is and errors.As see through the prefixes. Every call returns the same error, the same one
+// Wait returns.
+// If holds are outstanding, CloseAll blocks until the last one is released before running
+// any function. If the Closer was created with WithTimeout, the shutdown is bounded as
+// described in CloseAllContext. This method is thread-safe and idempotent.
+func (c *Closer) CloseAll() error {
+	return c.CloseAllContext(context.Background())
+}
+
+// Close is CloseAll, so that a Closer is an io.Closer: it can be nested into another Closer
+// with AddCloser, or handed to any API closing an io.Closer.
+//
+// Example:
+//
+//	parent.AddCloser(child) // child shuts down as part of parent
+func (c *Closer) Close() error {
+	return c.CloseAll()
+}
+
+// CloseAllContext is like CloseAll, but bounds the shutdown by ctx as well as by the timeout
+// given to New. Once ctx is done, functions that are still running are abandoned, functions
+// that have not started yet are skipped, and all of them are reported by Wait with an error
+// wrapping ctx.Err(). Waiting for outstanding holds is also abandoned when ctx is done.
+// Only the first call to CloseAll or CloseAllContext starts the shutdown; later calls block
+// until it completes. All of them return the same error as CloseAll.
+func (c *Closer) CloseAllContext(ctx context.Context) error {
+	return c.closeAll(ctx, Reason{Kind: ReasonCall})
+}
+
+// CloseAllInitiated is like CloseAll, but also reports whether this call initiated the
+// shutdown, rather than a shutdown initiated otherwise being in progress or completed
+// already, in which case it waits for it as CloseAll does. TriggerCount tells how many
+// requests to shut down preceded it.
+//
+// Example:
+//
+//	if initiated, err := c.CloseAllInitiated(); !initiated {
+//		log.Printf("shutdown already initiated by %v", c.Reason())
+//	}
+func (c *Closer) CloseAllInitiated() (initiated bool, err error) {
+	return c.initiate(context.Background(), Reason{Kind: ReasonCall})
+}
+
+// TriggerCount returns the number of requests to shut down received, whether by a call to
+// CloseAll, a signal or any other means, including the one that initiated the shutdown. A
+// request received once shutdown is in progress or has completed is logged as an Info
+// "shutdown already in progress" event with the "trigger" number and its "reason".
+func (c *Closer) TriggerCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.triggers
+}
+
+// closeAll implements CloseAllContext, recording reason as what initiated the shutdown
+// if this call is the one starting it.
+func (c *Closer) closeAll(ctx context.Context, reason Reason) error {
+	_, err := c.initiate(ctx, reason)
+	return err
+}
+
+// initiate is closeAll, also reporting whether this call initiated the shutdown.
+func (c *Closer) initiate(ctx context.Context, reason Reason) (bool, error) {
+	c.mu.Lock()
+	c.triggers++
+	trigger, done := c.triggers, c.done
+	c.mu.Unlock()
+	if trigger > 1 {
+		c.log().Info("shutdown already in progress", "trigger", trigger, "reason", reason.String())
+		<-done
+		return false, c.result()
+	}
+	func() {
 		defer close(c.done)
+		defer c.armHardDeadline()()
+		ctx = withClock(context.WithValue(ctx, reasonKey{}, reason), c.clock)
+		var extendable *extendable
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			if c.extension > 0 {
+				extendable, cancel = withExtendableTimeout(ctx, c.clock, c.timeout, c.extension, c.log())
+				ctx = extendable
+			} else {
+				ctx, cancel = withTimeout(ctx, c.timeout)
+			}
+			defer cancel()
+		}
+
+		// Wake up the waits for holds and tasks below when ctx is done.
+		stop := context.AfterFunc(ctx, func() {
+			c.mu.Lock()
+			c.held.Broadcast()
+			c.mu.Unlock()
+		})
+		defer stop()
+
+		c.mu.Lock()
+		c.closing = true
+		c.reason = reason
+		c.cancel(ErrClosed)
+		startHooks := c.startHooks
+		var pub *publisher
+		if len(c.subs) > 0 {
+			pub = &publisher{subs: slices.Clone(c.subs), reason: reason}
+		}
+		c.mu.Unlock()
+		pub.publish(Event{Kind: ShutdownTriggered, Time: c.clock.Now()})
+		for _, hook := range startHooks {
+			hook(reason)
+		}
+		if c.drainDelay > 0 {
+			c.log().Info("draining before shutdown", "delay", c.drainDelay)
+			drain := c.clock.NewTimer(c.drainDelay)
+			select {
+			case <-drain.C():
+			case <-ctx.Done():
+				drain.Stop()
+			}
+		}
+
+		// Let a running Quiesce finish; later calls see closing and return.
+		c.quiesceMu.Lock()
+		c.quiesceMu.Unlock()
+
+		c.mu.Lock()
+		for c.holds > 0 && ctx.Err() == nil {
+			c.held.Wait()
+		}
+		funcs := c.funcs.snapshot(true)
+		steps := c.plan(funcs)
+		run, quiescence := steps, c.quiescence
+		if c.quiesced.Load() {
+			run = withoutQuiesced(steps, quiescence)
+		}
+		n := len(funcs)
+		errs := c.errs
+		c.steps = steps
+		c.started = true
+		c.registered = funcs
+		c.mu.Unlock()
+		c.shutdownStarted()
+		defer c.StopSignalHandling()
+		l := c.log()
+		l.Info("shutdown started", "reason", reason.String(), "funcs", n)
+		if c.logSteps {
+			c.logPlan(l, steps)
+		}
+		c.checkWiring(l, funcs)
+
+		taskErrs := c.waitTasks(ctx)
+		for _, err := range taskErrs {
+			stream(errs, err)
+		}
+		var barrierErrs []error
+		if len(c.barriers) > 0 {
+			barrierErrs = c.runBarriers(ctx, l)
+			for _, err := range barrierErrs {
+				stream(errs, err)
+			}
+		}
+		col := collector{
+			ignored:  c.ignored,
+			errs:     errs,
+			onError:  c.onError,
+			pub:      pub,
+			failFast: c.failFast,
+			results:  make([]result, 0, n),
+			running:  make(map[*entry]time.Time),
+			clock:    c.clock,
+		}
+		if c.stateFile != "" {
+			col.state = openStateFile(c.stateFile, l, c.clock, reason, funcs)
+		}
+		if debugEnabled(l) {
+			col.log = l
+		}
+		var history map[string][]time.Duration
+		if c.history != nil {
+			history = c.loadHistory(l)
+			col.slow, col.slowLog = slowThresholds(history), l
+		}
+		end := func(error) {}
+		if c.tracer != nil {
+			trace(c.tracer, steps)
+			ctx, end = c.tracer.Start(ctx, "shutdown")
+		}
+		// Wait for an in-flight Flush so that flushers never run twice in parallel.
+		c.flushMu.Lock()
+		start := c.clock.Now()
+		stopProgress := c.logProgress(&col, start)
 		c.mu.Lock()
-		funcs := c.funcs
-		c.funcs = nil
+		c.current = &col
 		c.mu.Unlock()
+		for _, r := range quiescence {
+			col.add(r)
+		}
+		execute(ctx, run, c.limit, c.budgets, &col)
+		c.mu.Lock()
+		c.current = nil
+		c.mu.Unlock()
+		stopProgress()
+		c.flushMu.Unlock()
+		if c.deadline != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			dumpStacks(c.deadline)
+		}
+
+		failures, warnings := col.failures()
+		if c.onError == nil {
+			logFailures(l.Error, "closer failed", "closers failed", failures, c.collapse)
+		}
+		logFailures(func(msg string, args ...any) { warn(l, msg, args...) },
+			"optional closer failed", "optional closers failed", warnings, c.collapse)
+		d := c.clock.Now().Sub(start)
+		l.Info("shutdown finished", "duration", d, "failures", len(failures))
 
-		wg := sync.WaitGroup{}
-		errs := make(chan error, len(funcs))
-		for _, fn := range funcs {
-			wg.Add(1)
-			go func(fn closeFunc) {
-				defer wg.Done()
-				errs <- fn()
-			}(fn)
+		c.report = Report{Reason: reason, Start: start, Duration: d}
+		if extendable != nil {
+			c.report.Extended = extendable.total()
 		}
+		c.results = col.sorted()
+		if c.history != nil {
+			c.saveHistory(l, history, c.results)
+		}
+		if c.metrics != nil {
+			observe(c.metrics, c.report, c.results, len(failures))
+		}
+		c.mu.Lock()
+		fatal := c.fatal
+		c.settled = true
+		c.mu.Unlock()
+		c.err = shutdownError(fatal, taskErrs, barrierErrs, failures)
+		end(c.err)
+
+		if c.reports != nil {
+			c.keepReport(c.buildReport())
+		}
+		c.mu.Lock()
+		endHooks := c.endHooks
+		c.mu.Unlock()
+		if len(endHooks) > 0 {
+			r := c.buildReport()
+			for _, hook := range endHooks {
+				hook(r)
+			}
+		}
+		col.state.complete()
+		c.removePIDFile()
+
+		c.mu.Lock()
+		c.closed = true
+		if c.errs != nil {
+			close(c.errs)
+		}
+		pub.publish(Event{Kind: ShutdownCompleted, Time: c.clock.Now(), Err: c.err})
+		for _, ch := range c.subs {
+			close(ch)
+		}
+		c.mu.Unlock()
+		if pe := repanic(c.results); pe != nil {
+			panic(pe)
+		}
+	}()
+	return true, c.result()
+}
+
+// execute runs steps one after another, running at most limit functions at a time if limit
+// is positive, and records the outcome of all their functions in
+// col. Once ctx is done, the functions of the remaining steps are recorded as not finished
+// without being started, except for critical steps, which run regardless of ctx and of
+// aborted shutdowns, with the values of ctx. Steps of phases with a budget in budgets, by
+// priority, are bounded by it as well.
+func execute(ctx context.Context, steps []step, limit int, budgets map[int]time.Duration, col *collector) {
+	b := budgeter{budgets: budgets}
+	defer b.end()
+	for _, s := range steps {
+		if s.critical {
+			s.run(context.WithoutCancel(ctx), limit, col)
+			continue
+		}
+		ctx := b.context(ctx, s)
+		if err := ctx.Err(); err != nil {
+			for i := range s.funcs {
+				col.record(&s.funcs[i], time.Time{}, notFinished(err))
+			}
+			continue
+		}
+		if col.aborted() {
+			for i := range s.funcs {
+				col.record(&s.funcs[i], time.Time{}, ErrSkipped)
+			}
+			continue
+		}
+		if !s.cycle {
+			s.run(ctx, limit, col)
+			continue
+		}
+		// Functions in a cycle still run, but each of them is reported as such.
+		cycle := collector{ignored: col.ignored, pub: col.pub, cycle: true, clock: col.clock}
+		s.run(ctx, limit, &cycle)
+		for _, r := range cycle.results {
+			r.err = errors.Join(ErrDependencyCycle, r.err)
+			col.add(r)
+		}
+	}
+}
+
+// run executes the functions of s, at most limit at a time if limit is positive, and
+// records their outcome in col.
+func (s step) run(ctx context.Context, limit int, col *collector) {
+	if s.sequential {
+		runSequentially(ctx, s.funcs, !s.critical, col)
+	} else {
+		runConcurrently(ctx, s.funcs, limit, s.ordered, col)
+	}
+}
+
+// runSequentially executes funcs one at a time in order and records their outcome in col.
+// Once ctx is done, the remaining functions are recorded as not finished without being run,
+// and once a function fails in a fail-fast shutdown that is abortable, as skipped.
+func runSequentially(ctx context.Context, funcs []entry, abortable bool, col *collector) {
+	for i := range funcs {
+		e := &funcs[i]
+		if err := ctx.Err(); err != nil {
+			col.record(e, time.Time{}, notFinished(err))
+			continue
+		}
+		if abortable && col.aborted() {
+			col.record(e, time.Time{}, ErrSkipped)
+			continue
+		}
+		start := col.now()
+		col.begin(e, start)
+		col.finish(e, start, e.run(ctx))
+	}
+}
+
+// defaultConcurrency is the number of goroutines running closing functions at the same time
+// unless WithMaxConcurrency is given. Closing functions mostly wait on I/O rather than use the
+// CPU, so it is large enough for usual sets of functions to all run at once, while very large
+// sets, such as one function per connection, run on a pool of that many goroutines.
+const defaultConcurrency = 1024
+
+// runConcurrently executes all funcs concurrently and records their outcome in col.
+// Functions sharing a serialization key run one at a time in registration order.
+// Functions are handed in registration order to a pool of limit goroutines, or
+// defaultConcurrency if limit is not positive, so that the number of goroutines does not
+// grow with the number of functions.
+// If ordered is set, every function waits for the previous one to have started before
+// starting, except for functions waiting for others sharing their serialization key.
+// If ctx is done before all functions finish, the unfinished ones are recorded as such, once
+// the longest cancel grace of funcs has passed, and any result they produce later is
+// discarded.
+func runConcurrently(ctx context.Context, funcs []entry, limit int, ordered bool, col *collector) {
+	// Functions sharing a serialization key form a chain that runs one function after another;
+	// every other function is a chain of its own. Chains are handed out by their first function.
+	var serial map[string][]int
+	chains := 0
+	for i, e := range funcs {
+		if e.serial == "" {
+			chains++
+			continue
+		}
+		if serial == nil {
+			serial = make(map[string][]int)
+		}
+		if _, ok := serial[e.serial]; !ok {
+			chains++
+		}
+		serial[e.serial] = append(serial[e.serial], i)
+	}
+	if chains == 0 {
+		return
+	}
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+	workers := min(limit, chains)
 
-		go func() {
-			wg.Wait()
-			close(errs)
+	var (
+		mu        sync.Mutex                           // protects running, finished and abandoned
+		running   = make([]runningFunc, workers)       // function run by every goroutine of the pool
+		finished  = make([]uint64, (len(funcs)+63)/64) // a bit for every function that finished
+		abandoned bool
+	)
+	// done is closed by the last chain to return, sparing a goroutine waiting for the others.
+	done := make(chan struct{})
+	var remaining atomic.Int64
+	remaining.Store(int64(chains))
+	finish := func() {
+		if remaining.Add(-1) == 0 {
+			close(done)
+		}
+	}
+	// started receives a value once the chain last handed out has started, or has been left
+	// unstarted because ctx is done, if ordered.
+	started := make(chan struct{})
+	run := func(w, first int) {
+		chain := serial[funcs[first].serial] // nil for a chain of a single function
+		n := max(len(chain), 1)
+		waiting := ordered
+		defer func() {
+			if waiting {
+				started <- struct{}{}
+			}
 		}()
+		for j := range n {
+			i := first
+			if chain != nil {
+				i = chain[j]
+			}
+			if ctx.Err() != nil {
+				// Leave the rest of the chain to be recorded as not finished.
+				return
+			}
+			start := col.now()
+			mu.Lock()
+			running[w] = runningFunc{index: i, start: start}
+			mu.Unlock()
+			col.begin(&funcs[i], start)
+			if waiting {
+				waiting = false
+				started <- struct{}{}
+			}
+			err := funcs[i].runWatched(ctx)
+			mu.Lock()
+			if abandoned {
+				mu.Unlock()
+				return
+			}
+			running[w] = runningFunc{}
+			finished[i/64] |= 1 << (i % 64)
+			col.finish(&funcs[i], start, err)
+			mu.Unlock()
+		}
+	}
+
+	// Hand every chain to an idle goroutine of the pool, starting a new one while the pool is
+	// not full, so that fast functions share goroutines instead of each getting its own.
+	work := make(chan int)
+	worker := func(w, first int) {
+		for ok := true; ok; first, ok = <-work {
+			run(w, first)
+			finish()
+		}
+	}
+	pool := 0
+	for i, e := range funcs {
+		if e.serial != "" && serial[e.serial][0] != i {
+			continue
+		}
+		select {
+		case work <- i:
+		default:
+			if pool < workers {
+				go worker(pool, i)
+				pool++
+				break
+			}
+			select {
+			case work <- i:
+			case <-ctx.Done():
+				// Leave the chain to be recorded as not finished.
+				finish()
+				continue
+			}
+		}
+		if ordered {
+			<-started
+		}
+	}
+	close(work)
 
-		for err := range errs {
-			if err != nil {
-				log.Println("error returned from closer")
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Give the functions with a cancel grace the longest of them to return.
+		var grace time.Duration
+		for i := range funcs {
+			grace = max(grace, funcs[i].grace)
+		}
+		if grace > 0 {
+			t := clockFrom(ctx).NewTimer(grace)
+			select {
+			case <-done:
+			case <-t.C():
 			}
+			t.Stop()
+		}
+	}
+	if ctx.Err() == nil {
+		return
+	}
+	mu.Lock()
+	abandoned = true
+	starts := make(map[int]time.Time, len(running))
+	for _, r := range running {
+		if !r.start.IsZero() {
+			starts[r.index] = r.start
 		}
+	}
+	for i := range funcs {
+		if finished[i/64]&(1<<(i%64)) == 0 {
+			col.record(&funcs[i], starts[i], notFinished(ctx.Err()))
+		}
+	}
+	mu.Unlock()
+}
 
-		c.done <- struct{}{}
+// runningFunc is the function run by a goroutine of the pool of runConcurrently, zero if
+// none.
+type runningFunc struct {
+	index int
+	start time.Time
+}
+
+// result is the outcome of a single closing function.
+type result struct {
+	entry    *entry        // entry of the function, kept by reference to save memory
+	start    time.Time     // when the function was started, zero if it never was
+	duration time.Duration // how long the function ran, or ran before being abandoned
+	err      error         // error returned by the function, without the entry prefix
+}
+
+// collector accumulates the outcome of concurrently running functions.
+type collector struct {
+	log      Logger       // receives an event for every function that succeeds, nil to not log
+	ignored  []error      // errors returned by functions that are recorded as success
+	errs     chan<- error // receives every error as it is recorded, nil to not stream them
+	onError  ErrorHandler // called with every error as it is recorded, nil if none
+	pub      *publisher   // receives an event for every function started and recorded, nil to not publish
+	cycle    bool         // results are added to another collector, which publishes them
+	failFast bool         // report that functions should no longer run once one has failed
+	failed   bool         // whether an error was recorded, protected by mu
+	abort    bool         // whether a function panicked under PanicAbort, protected by mu
+	mu       sync.Mutex
+	results  []result
+	running  map[*entry]time.Time     // start times of the functions running, nil to not track them
+	slow     map[string]time.Duration // durations past which named functions are logged as slow, nil if none
+	slowLog  Logger                   // receives the events of slow functions
+	state    *stateFile               // records every function recorded, nil to not record them
+	clock    Clock                    // source of the times of results, nil for the system clock
+}
+
+// now returns the current time of the clock of c.
+func (c *collector) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// begin notes that the function of e was started at start.
+func (c *collector) begin(e *entry, start time.Time) {
+	c.pub.funcEvent(FuncStarted, start, result{entry: e, start: start})
+	if c.running == nil {
+		return
+	}
+	c.mu.Lock()
+	c.running[e] = start
+	c.mu.Unlock()
+	if c.slow != nil {
+		c.watchSlow(e, start)
+	}
+}
+
+// finish records the outcome of the function of e, which was started at start and has just
+// returned err.
+func (c *collector) finish(e *entry, start time.Time, err error) {
+	d := c.now().Sub(start)
+	err = ignore(err, c.ignored)
+	if err == nil && c.log != nil {
+		c.log.Debug("closer finished", e.attrs("duration", d)...)
+	}
+	c.add(result{e, start, d, err})
+}
+
+// record stores err as the outcome of the function of e, which did not return it itself:
+// either it was never started, in which case start is zero, or it was abandoned.
+func (c *collector) record(e *entry, start time.Time, err error) {
+	var d time.Duration
+	if !start.IsZero() {
+		d = c.now().Sub(start)
+	}
+	c.add(result{e, start, d, err})
+}
+
+// add stores r.
+func (c *collector) add(r result) {
+	failed := r.err != nil && !r.entry.optional
+	c.mu.Lock()
+	c.results = append(c.results, r)
+	if failed {
+		c.failed = true
+		stream(c.errs, r.prefixed())
+	}
+	if r.err != nil && r.entry.panics == PanicAbort && panicked(r.err) != nil {
+		c.abort = true
+	}
+	if !c.cycle {
+		kind := FuncFinished
+		if r.err != nil {
+			kind = FuncFailed
+		}
+		c.pub.funcEvent(kind, c.now(), r)
+		c.state.mark(r)
+	}
+	if c.running != nil {
+		delete(c.running, r.entry)
+	}
+	c.mu.Unlock()
+	if failed && c.onError != nil {
+		c.onError(r.entry.key(), r.err)
+	}
+}
+
+// aborted reports whether the remaining functions should be skipped because one has failed
+// and fail-fast is enabled, or because one panicked under PanicAbort.
+func (c *collector) aborted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.abort || c.failFast && c.failed
+}
+
+// pending describes the functions still running at now, in registration order, with how
+// long each has been running.
+func (c *collector) pending(now time.Time) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]*entry, 0, len(c.running))
+	for e := range c.running {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].index < entries[j].index })
+	pending := make([]string, len(entries))
+	for i, e := range entries {
+		pending[i] = fmt.Sprintf("%s for %v", e, now.Sub(c.running[e]).Round(time.Millisecond))
+	}
+	return pending
+}
+
+// sorted returns the recorded results ordered by registration index, regardless of the
+// order in which the functions completed.
+func (c *collector) sorted() []result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sort.Slice(c.results, func(i, j int) bool {
+		return c.results[i].entry.index < c.results[j].entry.index
 	})
+	return c.results
+}
+
+// failures returns the results with an error of required functions and those of optional
+// ones, ordered by registration index.
+func (c *collector) failures() (required, optional []result) {
+	for _, r := range c.sorted() {
+		switch {
+		case r.err == nil:
+		case r.entry.optional:
+			optional = append(optional, r)
+		default:
+			required = append(required, r)
+		}
+	}
+	return required, optional
+}
+
+// errors returns the recorded errors ordered by registration index, each prefixed with
+// the identifier of the function that returned it.
+func (c *collector) errors() []error {
+	failures, _ := c.failures()
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f.prefixed()
+	}
+	return errs
 }
@@ -1,9 +1,16 @@
 package closer
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -100,7 +107,7 @@ func TestCleanupFunctionError(t *testing.T) {
 // It resets the globalCloser, adds a cleanup function, calls CloseAll, and waits for completion.
 func TestGlobalFunctions(t *testing.T) {
 	// Replace the globalCloser with a new instance for testing.
-	globalCloser = New()
+	ResetGlobal()
 	var varSet int32
 
 	simpleCleanup := func() error {
@@ -116,13 +123,52 @@ func TestGlobalFunctions(t *testing.T) {
 	}
 }
 
+// TestGlobalAddNamed verifies that errors of functions registered on the global closer
+// by name are attributed to them.
+func TestGlobalAddNamed(t *testing.T) {
+	ResetGlobal()
+	AddNamed("cache", func() error { return nil })
+	AddNamed("db", func() error { return errors.New("boom") })
+
+	err := CloseAll()
+	if err == nil || err.Error() != "db #1: boom" {
+		t.Errorf("expected error attributed to db #1, got %v", err)
+	}
+}
+
+// TestSetGlobal verifies that the package-level functions use the Closer set as global and
+// that the previous one is returned.
+func TestSetGlobal(t *testing.T) {
+	c := New(WithTimeout(time.Second))
+	prev := SetGlobal(c)
+	defer SetGlobal(prev)
+	if Global() != c {
+		t.Fatal("expected Global to return the Closer set")
+	}
+
+	Add(func() error { return nil })
+	CloseAll()
+	if !c.IsClosed() {
+		t.Error("expected the Closer set to be closed")
+	}
+	if got := SetGlobal(prev); got != c {
+		t.Errorf("expected %p to be returned, got %p", c, got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on nil Closer")
+		}
+	}()
+	SetGlobal(nil)
+}
+
 // TestCloserWithSignal simulates a signal-triggered close.
 //
 // NOTE: Actually sending a signal to the process might interfere with tests,
 // so we simulate the behavior by sending a signal on a separate goroutine.
 func TestCloserWithSignal(t *testing.T) {
 	// Create a new Closer that is watching for os.Interrupt.
-	c := New(os.Interrupt)
+	c := New(WithSignals(os.Interrupt))
 	var flag int32
 	cleanup := func() error {
 		atomic.AddInt32(&flag, 1)
@@ -149,3 +195,590 @@ func TestCloserWithSignal(t *testing.T) {
 		t.Errorf("expected cleanup function to execute once due to signal trigger, got %d", flag)
 	}
 }
+
+// TestWaitErrorOrder verifies that the aggregated error lists failing functions in
+// registration order, independently of the order in which they complete.
+func TestWaitErrorOrder(t *testing.T) {
+	var want string
+	for run := 0; run < 10; run++ {
+		c := New()
+		for i := 0; i < 5; i++ {
+			delay := time.Duration(5-i) * time.Millisecond
+			c.AddNamed(fmt.Sprintf("func-%d", i), func() error {
+				time.Sleep(delay)
+				return fmt.Errorf("failure %d", i)
+			})
+		}
+		c.CloseAll()
+		err := c.Wait()
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if run == 0 {
+			want = err.Error()
+			continue
+		}
+		if got := err.Error(); got != want {
+			t.Fatalf("expected identical errors across runs, got %q and %q", want, got)
+		}
+	}
+
+	expected := "func-0 #0: failure 0\nfunc-1 #1: failure 1\nfunc-2 #2: failure 2\nfunc-3 #3: failure 3\nfunc-4 #4: failure 4"
+	if want != expected {
+		t.Errorf("expected %q, got %q", expected, want)
+	}
+}
+
+// TestWaitNoError verifies that Wait returns nil when all functions succeed.
+func TestWaitNoError(t *testing.T) {
+	c := New()
+	c.Add(func() error { return nil })
+	c.CloseAll()
+	if err := c.Wait(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
+// TestResetGlobalConcurrent swaps the global closer while other goroutines use the
+// package-level functions. Run with -race to verify the swap is race-free.
+func TestResetGlobalConcurrent(t *testing.T) {
+	defer ResetGlobal()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Add(func() error { return nil })
+					CloseAll()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		prev := ResetGlobal()
+		if prev == nil {
+			t.Fatal("expected previous global closer")
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// TestResetGlobalAfterClose verifies that functions added after a reset run on the fresh
+// global closer, even though the previous one was already closed.
+func TestResetGlobalAfterClose(t *testing.T) {
+	ResetGlobal()
+	CloseAll()
+	Wait()
+
+	ResetGlobal()
+	var executed int32
+	Add(func() error {
+		atomic.AddInt32(&executed, 1)
+		return nil
+	})
+	CloseAll()
+	Wait()
+
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expected cleanup function executed once, got %d", n)
+	}
+}
+
+// TestCloseAllContextDeadline verifies that functions still running or not yet started when
+// the context expires are reported, while the others complete normally.
+func TestCloseAllContextDeadline(t *testing.T) {
+	c := New()
+	block := make(chan struct{})
+	defer close(block)
+	c.AddNamed("fast", func() error { return nil })
+	c.AddNamed("hung", func() error {
+		<-block
+		return nil
+	})
+	c.AddNamed("later", func() error { return nil }, WithPriority(-1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.CloseAllContext(ctx)
+	err := c.Wait()
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	expected := "hung #1: did not finish: context deadline exceeded\nlater #2: did not finish: context deadline exceeded"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+// TestNewWithTimeout verifies that the timeout given to New bounds CloseAll.
+func TestNewWithTimeout(t *testing.T) {
+	c := New(WithTimeout(20 * time.Millisecond))
+	block := make(chan struct{})
+	defer close(block)
+	c.Add(func() error {
+		<-block
+		return nil
+	})
+
+	start := time.Now()
+	c.CloseAll()
+	if err := c.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected shutdown to be bounded by the timeout, took %v", elapsed)
+	}
+}
+
+// TestCloseAllContextHold verifies that waiting for outstanding holds is abandoned when the
+// context is done.
+func TestCloseAllContextHold(t *testing.T) {
+	c := New()
+	release, _ := c.Hold()
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.CloseAllContext(ctx)
+	if err := c.Wait(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
+// TestCloseAllReturnsErrors verifies that CloseAll returns the joined errors with the
+// failing function attributed, and that every call returns the same error as Wait.
+func TestCloseAllReturnsErrors(t *testing.T) {
+	c := New()
+	errDB := errors.New("connection reset")
+	c.AddNamed("db", func() error { return errDB })
+	c.AddNamed("cache", func() error { return nil })
+
+	err := c.CloseAll()
+	if !errors.Is(err, errDB) {
+		t.Fatalf("expected %v, got %v", errDB, err)
+	}
+	if expected := "db #0: connection reset"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+	if again := c.CloseAll(); again != err {
+		t.Errorf("expected repeated CloseAll to return %v, got %v", err, again)
+	}
+	if waited := c.Wait(); waited != err {
+		t.Errorf("expected Wait to return %v, got %v", err, waited)
+	}
+}
+
+// TestAddContext verifies that context-aware functions receive the shutdown deadline and
+// that their context is canceled when it passes.
+func TestAddContext(t *testing.T) {
+	c := New(WithTimeout(20 * time.Millisecond))
+	type result struct {
+		hasDeadline bool
+		err         error
+	}
+	results := make(chan result, 1)
+	c.AddContext(func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		<-ctx.Done()
+		results <- result{hasDeadline, ctx.Err()}
+		return ctx.Err()
+	})
+
+	c.CloseAll()
+	r := <-results
+
+	if !r.hasDeadline {
+		t.Error("expected context to carry the shutdown deadline")
+	}
+	if !errors.Is(r.err, context.DeadlineExceeded) {
+		t.Errorf("expected context to be canceled with deadline exceeded, got %v", r.err)
+	}
+}
+
+// TestWithMaxConcurrency verifies that no more than the given number of functions run at
+// the same time, and that all of them still run.
+func TestWithMaxConcurrency(t *testing.T) {
+	c := New(WithMaxConcurrency(3))
+	var running, peak, executed int32
+	for range 20 {
+		c.Add(func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&executed, 1)
+			return nil
+		})
+	}
+	c.CloseAll()
+
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Errorf("expected at most 3 functions at once, got %d", p)
+	}
+	if n := atomic.LoadInt32(&executed); n != 20 {
+		t.Errorf("expected 20 functions executed, got %d", n)
+	}
+}
+
+// TestWithMaxConcurrencyDeadline verifies that functions still waiting for a free goroutine
+// when the deadline passes are not started and are reported.
+func TestWithMaxConcurrencyDeadline(t *testing.T) {
+	c := New(WithMaxConcurrency(1), WithTimeout(20*time.Millisecond))
+	var started int32
+	for range 3 {
+		c.Add(func() error {
+			atomic.AddInt32(&started, 1)
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+	}
+
+	err := c.CloseAll()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n := atomic.LoadInt32(&started); n != 1 {
+		t.Errorf("expected 1 function started, got %d", n)
+	}
+}
+
+// TestDone verifies that Done is closed once shutdown is initiated, before closing functions
+// run and while a hold delays them.
+func TestDone(t *testing.T) {
+	c := New()
+	release, _ := c.Hold()
+	c.Add(func() error {
+		select {
+		case <-c.Done():
+			return nil
+		default:
+			return errors.New("Done not closed before closing functions")
+		}
+	})
+
+	select {
+	case <-c.Done():
+		t.Fatal("expected Done to be open before shutdown")
+	default:
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- c.CloseAll() }()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected Done to be closed while held")
+	}
+
+	release()
+	if err := <-errc; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestContext verifies that the context is canceled with ErrClosed once shutdown starts.
+func TestContext(t *testing.T) {
+	c := New()
+	ctx := c.Context()
+	if ctx.Err() != nil {
+		t.Fatalf("expected live context before shutdown, got %v", ctx.Err())
+	}
+
+	c.CloseAll()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, ctx.Err())
+	}
+	if cause := context.Cause(ctx); cause != ErrClosed {
+		t.Errorf("expected cause %v, got %v", ErrClosed, cause)
+	}
+}
+
+// TestWaitTimeout verifies that waiting can be given up on while shutdown is stuck, and that
+// it returns the shutdown error once it completes.
+func TestWaitTimeout(t *testing.T) {
+	c := New()
+	unblock := make(chan struct{})
+	c.Add(func() error {
+		<-unblock
+		return errors.New("boom")
+	})
+	go c.CloseAll()
+
+	if err := c.WaitTimeout(10 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.WaitContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	close(unblock)
+	if err := c.WaitTimeout(time.Second); err == nil || err.Error() != "#0: boom" {
+		t.Errorf("expected shutdown error, got %v", err)
+	}
+}
+
+// TestWithDrainDelay verifies that closing functions only run once the drain delay has
+// elapsed, while Done is closed right away.
+func TestWithDrainDelay(t *testing.T) {
+	c := New(WithDrainDelay(30 * time.Millisecond))
+	var closedAt time.Time
+	c.Add(func() error {
+		closedAt = time.Now()
+		return nil
+	})
+
+	start := time.Now()
+	go c.CloseAll()
+	<-c.Done()
+	if d := time.Since(start); d > 20*time.Millisecond {
+		t.Errorf("expected Done to be closed right away, took %v", d)
+	}
+	c.Wait()
+	if d := closedAt.Sub(start); d < 30*time.Millisecond {
+		t.Errorf("expected closing function to run after the drain delay, ran after %v", d)
+	}
+}
+
+// TestWithDeadlineDump verifies that the goroutine stacks are dumped when the deadline passes
+// with a function still running, and not when the shutdown completes in time.
+func TestWithDeadlineDump(t *testing.T) {
+	var dump syncBuffer
+	block := make(chan struct{})
+	defer close(block)
+	c := New(WithTimeout(10*time.Millisecond), WithDeadlineDump(&dump))
+	c.AddNamed("stuck", func() error {
+		<-block
+		return nil
+	})
+	c.CloseAll()
+	if out := dump.String(); !strings.Contains(out, "goroutine ") || !strings.Contains(out, "TestWithDeadlineDump") {
+		t.Errorf("expected stack dump showing the stuck function, got %q", out)
+	}
+
+	var none syncBuffer
+	c = New(WithTimeout(time.Second), WithDeadlineDump(&none))
+	c.Add(func() error { return nil })
+	c.CloseAll()
+	if out := none.String(); out != "" {
+		t.Errorf("expected no stack dump, got %q", out)
+	}
+}
+
+// TestCloseIsCloseAll verifies that a Closer is an io.Closer closing like CloseAll, so that it
+// can be nested into another Closer.
+func TestCloseIsCloseAll(t *testing.T) {
+	parent, child := New(), New()
+	fail := errors.New("child failed")
+	child.AddNamed("db", func() error { return fail })
+	var _ io.Closer = child
+	parent.AddCloser(child)
+
+	if err := parent.CloseAll(); !errors.Is(err, fail) {
+		t.Errorf("expected parent to report %v, got %v", fail, err)
+	}
+	if !child.IsClosing() {
+		t.Error("expected child to be closed")
+	}
+	if err := child.Close(); !errors.Is(err, fail) {
+		t.Errorf("expected Close to return the same error, got %v", err)
+	}
+}
+
+// TestWaitMany verifies that any number of goroutines can wait for the shutdown, before and
+// after it completes, and all get its error.
+func TestWaitMany(t *testing.T) {
+	errA := errors.New("a failed")
+	c := New()
+	c.Add(func() error { return errA })
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Wait()
+		}()
+	}
+	c.CloseAll()
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Wait()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if !errors.Is(err, errA) {
+			t.Errorf("expected %v, got %v", errA, err)
+		}
+	}
+}
+
+// BenchmarkCloseAll measures the time and memory taken to shut down 1, 100 and 100k no-op
+// closing functions, bounded by a timeout as in most services.
+func BenchmarkCloseAll(b *testing.B) {
+	noop := func() error { return nil }
+	for _, n := range []int{1, 100, 100000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				c := New(WithSlog(slog.New(slog.NewTextHandler(io.Discard, nil))), WithTimeout(time.Minute))
+				for j := 0; j < n; j++ {
+					c.Add(noop)
+				}
+				b.StartTimer()
+				c.CloseAll()
+			}
+		})
+	}
+}
+
+// TestCloseAllInitiated verifies that only the first request to shut down reports having
+// initiated it, that later ones are counted and logged, and that Reset starts over.
+func TestCloseAllInitiated(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	boom := errors.New("boom")
+	c.Add(func() error { return boom })
+
+	if initiated, err := c.CloseAllInitiated(); !initiated || !errors.Is(err, boom) {
+		t.Fatalf("expected initiated with %v, got %v, %v", boom, initiated, err)
+	}
+	c.CloseAll()
+	if initiated, err := c.CloseAllInitiated(); initiated || !errors.Is(err, boom) {
+		t.Errorf("expected not initiated with %v, got %v, %v", boom, initiated, err)
+	}
+	if n := c.TriggerCount(); n != 3 {
+		t.Errorf("expected 3 triggers, got %d", n)
+	}
+	want := "INFO shutdown already in progress trigger=2 reason=CloseAll call"
+	if !slices.Contains(l.events, want) {
+		t.Errorf("expected %q, got %v", want, l.events)
+	}
+
+	c.Reset()
+	if n := c.TriggerCount(); n != 0 {
+		t.Errorf("expected no trigger after Reset, got %d", n)
+	}
+	if initiated, _ := c.CloseAllInitiated(); !initiated {
+		t.Error("expected the shutdown after Reset to be initiated")
+	}
+}
+
+// TestRunWatchedAfterDeadline verifies that a function returning, even successfully, after
+// the shutdown deadline is reported as not finished and as timed out.
+func TestRunWatchedAfterDeadline(t *testing.T) {
+	e := entry{fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := e.runWatched(ctx)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected an error wrapping %v and %v, got %v", ErrTimeout, context.DeadlineExceeded, err)
+	}
+	if want := "did not finish: context deadline exceeded"; err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+
+	c := New(WithTimeout(10 * time.Millisecond))
+	c.AddContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err := c.CloseAll(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected an error wrapping %v, got %v", ErrTimeout, err)
+	}
+	if r := c.Report(); len(r.Funcs) != 1 || !r.Funcs[0].TimedOut {
+		t.Errorf("expected the function reported as timed out, got %+v", r.Funcs)
+	}
+}
+
+// TestOrderedMaxConcurrencyDeadline verifies that with WithRegistrationOrder and
+// WithMaxConcurrency, functions skipped once the deadline has passed do not leave the
+// goroutines waiting for their turn blocked.
+func TestOrderedMaxConcurrencyDeadline(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := range 50 {
+		c := New(WithRegistrationOrder(), WithMaxConcurrency(8), WithTimeout(time.Millisecond))
+		for range 200 {
+			c.Add(func() error {
+				time.Sleep(50 * time.Microsecond)
+				return nil
+			})
+		}
+		ctx := context.Background()
+		if i%2 == 1 {
+			// Expired before the functions are handed to the pool.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, -time.Second)
+			defer cancel()
+		}
+		if err := c.CloseAllContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestCloseAllBoundedGoroutines verifies that closing functions run on a bounded pool of
+// goroutines however many are registered, and however long they run.
+func TestCloseAllBoundedGoroutines(t *testing.T) {
+	for _, limit := range []int{0, 4} {
+		t.Run(strconv.Itoa(limit), func(t *testing.T) {
+			c := New(WithMaxConcurrency(limit), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			var peak atomic.Int64
+			for range 3 * defaultConcurrency {
+				c.Add(func() error {
+					n := int64(runtime.NumGoroutine())
+					for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+					}
+					time.Sleep(time.Millisecond)
+					return nil
+				})
+			}
+			before := int64(runtime.NumGoroutine())
+			if err := c.CloseAll(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			pool := int64(limit)
+			if limit == 0 {
+				pool = defaultConcurrency
+			}
+			if p := peak.Load(); p > before+pool+10 {
+				t.Errorf("expected at most %d goroutines, got %d", before+pool+10, p)
+			}
+		})
+	}
+}
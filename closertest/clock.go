@@ -0,0 +1,187 @@
+package closertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// FakeClock is a closer.Clock whose time only passes when Advance is called, so that tests
+// of shutdown timeouts, delays and progress events run instantly and deterministically. Pass
+// it to closer.New with closer.WithClock.
+//
+// Example:
+//
+//	clock := closertest.NewFakeClock(time.Now())
+//	c := closer.New(closer.WithClock(clock), closer.WithTimeout(30*time.Second))
+//	c.Add(hangs)
+//	go c.CloseAll()
+//	clock.BlockUntil(1)
+//	clock.Advance(30 * time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond // signaled when timers are added
+	now     time.Time
+	timers  []*fakeTimer // pending timers and tickers
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time of f.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer returns a Timer firing once Advance has moved f by d.
+func (f *FakeClock) NewTimer(d time.Duration) closer.Timer {
+	t := &fakeTimer{clock: f, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker returns a Ticker firing every d as Advance moves f. It panics if d is not
+// positive, as time.NewTicker does.
+func (f *FakeClock) NewTicker(d time.Duration) closer.Ticker {
+	if d <= 0 {
+		panic("closertest: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: f, ch: make(chan time.Time, 1), period: d}
+	t.Reset(d)
+	return fakeTicker{t}
+}
+
+// AfterFunc returns a Timer calling fn once Advance has moved f by d. Advance calls fn
+// before returning, except when d is not positive, in which case fn is called at once in
+// its own goroutine.
+func (f *FakeClock) AfterFunc(d time.Duration, fn func()) closer.Timer {
+	t := &fakeTimer{clock: f, fn: fn}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves f forward by d, firing the timers and tickers due by then in the order of
+// their time, with the time of f set to theirs as each fires.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for {
+		t := f.next(end)
+		if t == nil {
+			break
+		}
+		f.now = t.when
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			f.remove(t)
+		}
+		if t.fn == nil {
+			t.send(f.now)
+			continue
+		}
+		f.mu.Unlock()
+		t.fn()
+		f.mu.Lock()
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending on f, so that a test
+// can wait for the code under test to arm its timer before calling Advance.
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+// Waiters returns the number of timers and tickers pending on f.
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// next returns the earliest pending timer due by end, nil if none.
+func (f *FakeClock) next(end time.Time) *fakeTimer {
+	var first *fakeTimer
+	for _, t := range f.timers {
+		if !t.when.After(end) && (first == nil || t.when.Before(first.when)) {
+			first = t
+		}
+	}
+	return first
+}
+
+// remove removes t from the pending timers and reports whether it was pending.
+func (f *FakeClock) remove(t *fakeTimer) bool {
+	for i, p := range f.timers {
+		if p == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a Timer or Ticker of a FakeClock.
+type fakeTimer struct {
+	clock  *FakeClock
+	ch     chan time.Time // nil for AfterFunc
+	fn     func()         // nil for NewTimer and NewTicker
+	period time.Duration  // zero for a Timer
+	when   time.Time      // guarded by clock.mu
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending := f.remove(t)
+	if d <= 0 && t.period == 0 {
+		if t.fn != nil {
+			go t.fn()
+		} else {
+			t.send(f.now)
+		}
+		return pending
+	}
+	t.when = f.now.Add(d)
+	f.timers = append(f.timers, t)
+	f.changed.Broadcast()
+	return pending
+}
+
+// send sends now on the channel of t unless a previous time is still unread, as the
+// channels of time.Timer and time.Ticker do.
+func (t *fakeTimer) send(now time.Time) {
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// fakeTicker is a Ticker of a FakeClock.
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.ch }
+
+func (t fakeTicker) Stop() { t.t.Stop() }
@@ -0,0 +1,96 @@
+package closertest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// TestFakeClockTimers verifies that timers, tickers and functions fire only once Advance
+// reaches their time, and not once stopped.
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+	ticker := clock.NewTicker(400 * time.Millisecond)
+	var called atomic.Int32
+	clock.AfterFunc(time.Second, func() { called.Add(1) })
+	stopped := clock.AfterFunc(time.Second, func() { called.Add(10) })
+	stopped.Stop()
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("expected the timer not to fire before its time")
+	default:
+	}
+	if n := called.Load(); n != 0 {
+		t.Errorf("expected no function called before its time, got %d", n)
+	}
+	<-ticker.C() // fired at 400ms and 800ms, the second tick dropped like with time.Ticker
+
+	clock.Advance(time.Millisecond)
+	if at := <-timer.C(); !at.Equal(start.Add(time.Second)) {
+		t.Errorf("expected the timer to fire at %v, got %v", start.Add(time.Second), at)
+	}
+	if n := called.Load(); n != 1 {
+		t.Errorf("expected the function called once by Advance, got %d", n)
+	}
+	clock.Advance(200 * time.Millisecond)
+	if at := <-ticker.C(); !at.Equal(start.Add(1200 * time.Millisecond)) {
+		t.Errorf("expected the ticker to fire at %v, got %v", start.Add(1200*time.Millisecond), at)
+	}
+	ticker.Stop()
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("expected no pending timers, got %d", n)
+	}
+}
+
+// TestFakeClockShutdownTimeout verifies that a Closer with a FakeClock times out its
+// shutdown once the clock is advanced past the timeout, without waiting for it.
+func TestFakeClockShutdownTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	c := closer.New(closer.WithClock(clock), closer.WithTimeout(time.Hour))
+	c.AddContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	go c.CloseAll()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	if err := c.WaitTimeout(5 * time.Second); err == nil {
+		t.Error("expected the shutdown to time out, got no error")
+	}
+	if d := c.Report().Duration; d != time.Hour {
+		t.Errorf("expected the shutdown to last %v, got %v", time.Hour, d)
+	}
+}
+
+// TestFakeClockDrainDelay verifies that closing functions wait for the drain delay to pass
+// on the FakeClock.
+func TestFakeClockDrainDelay(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	c := closer.New(closer.WithClock(clock), closer.WithDrainDelay(time.Minute))
+	var ran atomic.Bool
+	c.Add(func() error {
+		ran.Store(true)
+		return nil
+	})
+	go c.CloseAll()
+	clock.BlockUntil(1)
+	if ran.Load() {
+		t.Fatal("expected the function not to run during the drain delay")
+	}
+	clock.Advance(time.Minute)
+
+	if err := c.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !ran.Load() {
+		t.Error("expected the function to run once the drain delay passed")
+	}
+}
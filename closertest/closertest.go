@@ -0,0 +1,86 @@
+// Package closertest provides helpers for using closers in tests.
+package closertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// DefaultTimeout is the shutdown timeout used by NewForTest unless WithTimeout is given.
+const DefaultTimeout = 10 * time.Second
+
+// Option configures a Closer created by NewForTest.
+type Option func(*config)
+
+// config holds the settings collected from a list of Option values.
+type config struct {
+	timeout time.Duration
+	opts    []closer.Option
+}
+
+// WithTimeout sets how long the shutdown at the end of the test may take before the test
+// is failed. A hung closing function then fails the test instead of wedging the test binary.
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = d
+	}
+}
+
+// WithCloserOptions passes opts to closer.New when NewForTest creates the Closer, so that the
+// Closer of a test can be configured like the one of the code under test. Options given by
+// several calls are all passed, in order.
+func WithCloserOptions(opts ...closer.Option) Option {
+	return func(cfg *config) {
+		cfg.opts = append(cfg.opts, opts...)
+	}
+}
+
+// NewForTest creates a Closer that is shut down automatically when the test and all its
+// subtests complete. The test fails if any closing function returns an error or if the
+// shutdown does not complete within the timeout. Each subtest may own its own Closer.
+// Options of closer.New are given with WithCloserOptions.
+//
+// Example:
+//
+//	c := closertest.NewForTest(t)
+//	dir := createTempDir(t)
+//	c.Add(func() error { return os.RemoveAll(dir) })
+func NewForTest(t testing.TB, opts ...Option) *closer.Closer {
+	t.Helper()
+	cfg := config{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	c := closer.New(cfg.opts...)
+	t.Cleanup(func() {
+		go c.CloseAll()
+		if err := AssertClosedWithin(t, c, cfg.timeout); err != nil {
+			t.Errorf("closer: shutdown failed: %v", err)
+		}
+	})
+	return c
+}
+
+// AssertClosedWithin waits for the shutdown of c to complete and fails t if it does not
+// within d, measured from the call. It returns the error returned by the closing functions,
+// or nil if the shutdown did not complete. It does not initiate shutdown itself.
+func AssertClosedWithin(t testing.TB, c *closer.Closer, d time.Duration) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		t.Errorf("closer: shutdown did not complete within %v", d)
+		return nil
+	}
+}
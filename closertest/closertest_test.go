@@ -0,0 +1,148 @@
+package closertest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// fakeTB captures cleanups and errors instead of failing the test running it.
+type fakeTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// finish runs the registered cleanups in reverse order, like the testing package does.
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// TestNewForTestClosesAtCleanup verifies that closing functions run when the test finishes.
+func TestNewForTestClosesAtCleanup(t *testing.T) {
+	tb := &fakeTB{}
+	c := NewForTest(tb)
+	var executed int32
+	c.Add(func() error {
+		atomic.AddInt32(&executed, 1)
+		return nil
+	})
+
+	if n := atomic.LoadInt32(&executed); n != 0 {
+		t.Fatalf("expected no cleanup before the test finishes, got %d", n)
+	}
+	tb.finish()
+
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expected cleanup function executed once, got %d", n)
+	}
+	if len(tb.errors) != 0 {
+		t.Errorf("expected no errors, got %v", tb.errors)
+	}
+}
+
+// TestNewForTestReportsErrors verifies that a failing closing function fails the test.
+func TestNewForTestReportsErrors(t *testing.T) {
+	tb := &fakeTB{}
+	c := NewForTest(tb)
+	c.Add(func() error { return errors.New("container still running") })
+	tb.finish()
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "container still running") {
+		t.Errorf("expected shutdown error to be reported, got %v", tb.errors)
+	}
+}
+
+// TestNewForTestCloserOptions verifies that closer options are passed to the Closer.
+func TestNewForTestCloserOptions(t *testing.T) {
+	tb := &fakeTB{}
+	c := NewForTest(tb, WithCloserOptions(closer.WithRetry(2, 0)), WithTimeout(time.Second))
+	var calls int32
+	c.Add(func() error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return errors.New("container still running")
+		}
+		return nil
+	})
+	tb.finish()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected cleanup function retried once, got %d calls", n)
+	}
+	if len(tb.errors) != 0 {
+		t.Errorf("expected no errors, got %v", tb.errors)
+	}
+}
+
+// TestNewForTestTimeout verifies that a hung closing function fails the test
+// once the timeout elapses.
+func TestNewForTestTimeout(t *testing.T) {
+	tb := &fakeTB{}
+	c := NewForTest(tb, WithTimeout(10*time.Millisecond))
+	block := make(chan struct{})
+	defer close(block)
+	c.Add(func() error {
+		<-block
+		return nil
+	})
+	tb.finish()
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "did not complete") {
+		t.Errorf("expected timeout to be reported, got %v", tb.errors)
+	}
+}
+
+// TestNewForTestSubtests verifies that each subtest's Closer is shut down when that
+// subtest completes.
+func TestNewForTestSubtests(t *testing.T) {
+	var executed [2]int32
+	for i := range executed {
+		t.Run(fmt.Sprintf("subtest-%d", i), func(t *testing.T) {
+			c := NewForTest(t)
+			c.Add(func() error {
+				atomic.AddInt32(&executed[i], 1)
+				return nil
+			})
+		})
+		if n := atomic.LoadInt32(&executed[i]); n != 1 {
+			t.Errorf("expected subtest %d cleanup executed once, got %d", i, n)
+		}
+	}
+}
+
+// TestAssertClosedWithin verifies that a shutdown that does not complete in time fails the
+// test, and that the error of one that does is returned.
+func TestAssertClosedWithin(t *testing.T) {
+	tb := &fakeTB{}
+	c := closer.New()
+	if err := AssertClosedWithin(tb, c, 10*time.Millisecond); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "did not complete within 10ms") {
+		t.Errorf("expected timeout to be reported, got %v", tb.errors)
+	}
+
+	boom := errors.New("boom")
+	c.Add(func() error { return boom })
+	go c.CloseAll()
+	if err := AssertClosedWithin(t, c, time.Second); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
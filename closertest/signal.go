@@ -0,0 +1,74 @@
+package closertest
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Notifier is a closer.Notifier that delivers the signals sent with Send instead of those
+// received by the process, so that tests can exercise signal handling deterministically
+// without signaling the test binary. Pass it to closer.New with closer.WithNotifier.
+// The zero value is ready to use.
+type Notifier struct {
+	mu   sync.Mutex
+	subs map[chan<- os.Signal][]os.Signal
+}
+
+// NewNotifier returns a Notifier without subscribers.
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Notify subscribes c to sigs, like signal.Notify.
+func (n *Notifier) Notify(c chan<- os.Signal, sigs ...os.Signal) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.subs == nil {
+		n.subs = make(map[chan<- os.Signal][]os.Signal)
+	}
+	n.subs[c] = append(n.subs[c], sigs...)
+}
+
+// Stop unsubscribes c, like signal.Stop.
+func (n *Notifier) Stop(c chan<- os.Signal) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.subs, c)
+}
+
+// Send delivers sig to every channel subscribed to it and reports whether there was any.
+// Like the signal package, it does not block: a channel whose buffer is full misses sig.
+func (n *Notifier) Send(sig os.Signal) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := false
+	for c, sigs := range n.subs {
+		if !slices.Contains(sigs, sig) {
+			continue
+		}
+		select {
+		case c <- sig:
+		default:
+		}
+		sent = true
+	}
+	return sent
+}
+
+// TriggerSignal sends sig through n and fails t if no Closer is subscribed to it, for
+// example because it was not given to closer.WithSignals or shutdown has already started.
+//
+// Example:
+//
+//	n := closertest.NewNotifier()
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithNotifier(n))
+//	closertest.TriggerSignal(t, n, syscall.SIGTERM)
+//	closertest.AssertClosedWithin(t, c, time.Second)
+func TriggerSignal(t testing.TB, n *Notifier, sig os.Signal) {
+	t.Helper()
+	if !n.Send(sig) {
+		t.Errorf("closer: no subscriber for signal %v", sig)
+	}
+}
@@ -0,0 +1,40 @@
+package closertest
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// testSignal is an os.Signal that is never delivered by the OS.
+type testSignal string
+
+func (s testSignal) String() string { return string(s) }
+func (s testSignal) Signal()        {}
+
+// TestTriggerSignal verifies that a signal sent through a Notifier shuts the Closer down and
+// that the Notifier is released afterwards.
+func TestTriggerSignal(t *testing.T) {
+	term := testSignal("term")
+	n := NewNotifier()
+	c := closer.New(closer.WithSignals(term), closer.WithNotifier(n))
+	var got closer.Reason
+	c.OnShutdownStart(func(r closer.Reason) { got = r })
+
+	TriggerSignal(t, n, term)
+	if err := AssertClosedWithin(t, c, time.Second); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if got.Signal != os.Signal(term) {
+		t.Errorf("expected shutdown on term, got %v", got)
+	}
+
+	tb := &fakeTB{}
+	TriggerSignal(tb, n, term)
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "no subscriber") {
+		t.Errorf("expected missing subscriber to be reported, got %v", tb.errors)
+	}
+}
@@ -0,0 +1,146 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Connections holds long-lived connections, such as WebSockets, server-sent event streams or
+// gRPC streams, so that shutdown asks them to go away before closing them. Connections are
+// created with Closer.Connections.
+type Connections struct {
+	window   time.Duration // how long connections are given to go away once notified
+	log      func() Logger
+	mu       sync.Mutex
+	conns    map[*connection]struct{}
+	draining bool          // set once the drain function has started, new connections are refused
+	idle     chan struct{} // closed once the last connection ends while draining
+}
+
+// connection is a connection registered with Connections.
+type connection struct {
+	goingAway  func()
+	forceClose func() error
+}
+
+// Connections returns a new Connections and registers a closing function, named "connections"
+// and configured by opts, that notifies every connection registered with it that the server is
+// going away, gives them window to end by themselves, then force-closes those still open. It
+// logs an Info "connections drained" event with the number of connections "notified" and
+// "forced" to close, and fails with the errors of the force-closes. Once that function has
+// started, Register refuses new connections. The function runs before all others, or in
+// PhaseRequests if c declares it, as NewWebService does, unless opts give it another priority
+// or phase.
+//
+// Example:
+//
+//	conns := c.Connections(5 * time.Second)
+//	...
+//	done, ok := conns.Register(func() {
+//		ws.WriteControl(websocket.CloseMessage, goingAway, deadline)
+//	}, ws.Close)
+//	if !ok {
+//		ws.Close()
+//		return
+//	}
+//	defer done()
+func (c *Closer) Connections(window time.Duration, opts ...Option) *Connections {
+	cs := &Connections{window: window, log: c.log, conns: make(map[*connection]struct{})}
+	c.addContext(append([]Option{withName("connections"), c.phaseOr(PhaseRequests, WithPriority(math.MaxInt))}, opts...), cs.drain)
+	return cs
+}
+
+// Register adds a connection, notified by goingAway when shutdown starts draining and closed
+// by forceClose if it is still open once the drain window has passed, and reports whether it
+// was added. Once draining has started, it returns false and the connection must be closed
+// by the caller. Otherwise, done must be called once the connection has ended.
+func (cs *Connections) Register(goingAway func(), forceClose func() error) (done func(), ok bool) {
+	mustNotBeNil(goingAway, "going-away callback")
+	mustNotBeNil(forceClose, "force-close function")
+	conn := &connection{goingAway: goingAway, forceClose: forceClose}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.draining {
+		return func() {}, false
+	}
+	cs.conns[conn] = struct{}{}
+	return func() { cs.remove(conn) }, true
+}
+
+// Open returns the number of connections open.
+func (cs *Connections) Open() int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return len(cs.conns)
+}
+
+// remove forgets conn, which has ended.
+func (cs *Connections) remove(conn *connection) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if _, ok := cs.conns[conn]; !ok {
+		return
+	}
+	delete(cs.conns, conn)
+	if cs.draining && len(cs.conns) == 0 {
+		close(cs.idle)
+	}
+}
+
+// open reports whether conn has not ended.
+func (cs *Connections) open(conn *connection) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	_, ok := cs.conns[conn]
+	return ok
+}
+
+// drain refuses new connections, notifies the open ones, waits for them to end until the
+// drain window has passed or ctx is done, and force-closes the rest.
+func (cs *Connections) drain(ctx context.Context) error {
+	cs.mu.Lock()
+	cs.draining = true
+	conns := make([]*connection, 0, len(cs.conns))
+	for conn := range cs.conns {
+		conns = append(conns, conn)
+	}
+	if len(conns) == 0 {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.idle = make(chan struct{})
+	cs.mu.Unlock()
+
+	for _, conn := range conns {
+		// Spare the connections that ended meanwhile a notification they cannot receive.
+		if cs.open(conn) {
+			conn.goingAway()
+		}
+	}
+	window := clockFrom(ctx).NewTimer(cs.window)
+	defer window.Stop()
+	select {
+	case <-cs.idle:
+	case <-window.C():
+	case <-ctx.Done():
+	}
+
+	cs.mu.Lock()
+	stragglers := make([]*connection, 0, len(cs.conns))
+	for conn := range cs.conns {
+		stragglers = append(stragglers, conn)
+	}
+	clear(cs.conns)
+	cs.mu.Unlock()
+	var errs []error
+	for _, conn := range stragglers {
+		if err := conn.forceClose(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	cs.log().Info("connections drained", "notified", len(conns), "forced", len(stragglers))
+	return errors.Join(errs...)
+}
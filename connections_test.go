@@ -0,0 +1,68 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConnections verifies that connections are notified on shutdown, that those that end
+// within the drain window are not force-closed, that the others are, and that new
+// connections are refused.
+func TestConnections(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	conns := c.Connections(50 * time.Millisecond)
+	var notified, forced atomic.Int32
+
+	polite, ok := conns.Register(func() { notified.Add(1) }, func() error { forced.Add(1); return nil })
+	if !ok {
+		t.Fatal("expected the connection to be registered")
+	}
+	var done func()
+	done, _ = conns.Register(func() {
+		notified.Add(1)
+		go done()
+	}, func() error { forced.Add(1); return nil })
+	boom := errors.New("boom")
+	conns.Register(func() { notified.Add(1) }, func() error { forced.Add(1); return boom })
+	polite()
+	if n := conns.Open(); n != 2 {
+		t.Fatalf("expected 2 open connections, got %d", n)
+	}
+
+	if err := c.CloseAll(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if n := notified.Load(); n != 2 {
+		t.Errorf("expected 2 connections notified, got %d", n)
+	}
+	if n := forced.Load(); n != 1 {
+		t.Errorf("expected 1 connection force-closed, got %d", n)
+	}
+	if !slices.Contains(l.events, "INFO connections drained notified=2 forced=1") {
+		t.Errorf("expected counts to be logged, got %v", l.events)
+	}
+	if _, ok := conns.Register(func() {}, func() error { return nil }); ok {
+		t.Error("expected connections to be refused once draining")
+	}
+}
+
+// TestConnectionsIdle verifies that shutdown does not wait for the drain window once every
+// connection has ended.
+func TestConnectionsIdle(t *testing.T) {
+	c := New()
+	conns := c.Connections(time.Minute)
+	var done func()
+	done, _ = conns.Register(func() { go done() }, func() error { return errors.New("forced") })
+
+	start := time.Now()
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("expected shutdown not to wait for the window, took %v", d)
+	}
+}
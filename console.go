@@ -0,0 +1,23 @@
+package closer
+
+import (
+	"os"
+	"syscall"
+)
+
+// WithConsoleEvents makes New watch the signals a console application receives when it is
+// asked to stop, as WithSignals does: os.Interrupt and SIGTERM. On Windows, the Go runtime
+// handles console control events with SetConsoleCtrlHandler and delivers CTRL_C_EVENT and
+// CTRL_BREAK_EVENT as os.Interrupt, and CTRL_CLOSE_EVENT, CTRL_LOGOFF_EVENT and
+// CTRL_SHUTDOWN_EVENT as SIGTERM, so that closing the console window, logging off or shutting
+// down the system also trigger CloseAll, which watching os.Interrupt alone misses. While
+// SIGTERM is watched, the runtime keeps the process alive after such an event until the
+// shutdown completes or Windows gives up on it, after about 5 seconds for a closed window;
+// WithTimeout should stay below that.
+//
+// Example:
+//
+//	c := closer.New(closer.WithConsoleEvents(), closer.WithTimeout(4*time.Second))
+func WithConsoleEvents() Option {
+	return WithSignals(os.Interrupt, syscall.SIGTERM)
+}
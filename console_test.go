@@ -0,0 +1,16 @@
+package closer
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+// TestWithConsoleEvents verifies the signals watched for console events.
+func TestWithConsoleEvents(t *testing.T) {
+	o := newOptions([]Option{WithConsoleEvents()})
+	if expected := []os.Signal{os.Interrupt, syscall.SIGTERM}; !reflect.DeepEqual(o.signals, expected) {
+		t.Errorf("expected %v, got %v", expected, o.signals)
+	}
+}
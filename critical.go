@@ -0,0 +1,39 @@
+package closer
+
+import "time"
+
+// Critical marks closing functions that must run even when the shutdown has run out of time,
+// such as releasing a distributed lock or flushing a write-ahead log. Critical functions run
+// after all others, in steps of their own ordered like the rest of the shutdown, and they run
+// even once the shutdown deadline has passed or WithFailFast has skipped the others. Instead
+// of the shutdown deadline, each of them is bounded by budget, or by its own WithTimeout if
+// shorter. Reserve budget when sizing the shutdown timeout of the orchestrator.
+//
+// Example:
+//
+//	c.AddNamed("lock", lock.Release, closer.Critical(2*time.Second))
+func Critical(budget time.Duration) Option {
+	return func(o *options) {
+		o.critical = budget
+	}
+}
+
+// split returns the functions of funcs that do not match and those that do, without
+// modifying funcs. Both are nil if no function matches, sparing a copy.
+func split(funcs []entry, match func(e *entry) bool) (others, matching []entry) {
+	for i := range funcs {
+		if !match(&funcs[i]) {
+			continue
+		}
+		others = append(others, funcs[:i]...)
+		for j := i; j < len(funcs); j++ {
+			if match(&funcs[j]) {
+				matching = append(matching, funcs[j])
+			} else {
+				others = append(others, funcs[j])
+			}
+		}
+		return others, matching
+	}
+	return nil, nil
+}
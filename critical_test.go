@@ -0,0 +1,56 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCritical verifies that a critical function runs last and after the shutdown deadline,
+// bounded by its budget instead.
+func TestCritical(t *testing.T) {
+	c := New(WithTimeout(20 * time.Millisecond))
+	var deadline time.Time
+	released := false
+	c.addContext([]Option{Critical(time.Second)}, func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		released = true
+		return nil
+	})
+	c.AddNamed("stuck", func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+
+	plan := c.Plan()
+	if len(plan) != 2 || !plan[1].Critical || plan[1].Funcs[0].Timeout != time.Second {
+		t.Fatalf("expected a final critical step with a 1s budget, got %+v", plan)
+	}
+	start := time.Now()
+	if err := c.CloseAll(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if !released {
+		t.Fatal("expected the critical function to run")
+	}
+	if d := deadline.Sub(start); d < 500*time.Millisecond {
+		t.Errorf("expected the critical function to get its budget, got %v", d)
+	}
+}
+
+// TestCriticalFailFast verifies that a critical function is not skipped by WithFailFast.
+func TestCriticalFailFast(t *testing.T) {
+	c := New(WithFailFast(), WithSequential())
+	c.Add(func() error { return errors.New("boom") })
+	ran := false
+	c.AddNamed("lock", func() error {
+		ran = true
+		return nil
+	}, Critical(time.Second))
+
+	c.CloseAll()
+	if !ran {
+		t.Error("expected the critical function to run")
+	}
+}
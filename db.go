@@ -0,0 +1,48 @@
+package closer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// dbPollInterval is how often AddDB checks whether connections are still in use.
+const dbPollInterval = 10 * time.Millisecond
+
+// AddDB registers db to be closed once the connections in use have been returned, configured
+// by opts. Connections returned during the drain are closed instead of kept idle. If
+// connections are still in use at the deadline, the pool is closed anyway and the number of
+// connections dropped is logged as an Error event and reported along with the deadline error,
+// when the function has not been abandoned already. database/sql cannot refuse new
+// connections, so register whatever issues queries, such as HTTPMiddleware, to close before
+// db, for example with After. The function is named "sql-db". It panics if db is nil.
+//
+// Example:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	...
+//	c.AddDB(db, closer.WithTimeout(5*time.Second))
+func (c *Closer) AddDB(db *sql.DB, opts ...Option) {
+	mustNotBeNil(db, "sql.DB")
+	opts = append([]Option{withName("sql-db")}, opts...)
+	c.addContext(opts, func(ctx context.Context) error {
+		db.SetMaxIdleConns(0)
+		ticker := clockFrom(ctx).NewTicker(dbPollInterval)
+		defer ticker.Stop()
+		for {
+			inUse := db.Stats().InUse
+			if inUse == 0 {
+				return db.Close()
+			}
+			select {
+			case <-ticker.C():
+			case <-ctx.Done():
+				c.log().Error("database connections dropped", "name", "sql-db", "dropped", inUse)
+				err := fmt.Errorf("%d connections dropped: %w", inUse, context.Cause(ctx))
+				return errors.Join(err, db.Close())
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package closer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeDriver opens connections that do nothing.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+// fakeConn is a connection that supports no statements or transactions.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.ErrUnsupported }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.ErrUnsupported }
+
+func init() {
+	sql.Register("closer-fake", fakeDriver{})
+}
+
+// TestAddDB verifies that the pool is closed only once the connection in use is returned.
+func TestAddDB(t *testing.T) {
+	db, _ := sql.Open("closer-fake", "")
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.AddDB(db)
+
+	errc := make(chan error, 1)
+	go func() { errc <- c.CloseAll() }()
+	time.Sleep(3 * dbPollInterval)
+	if err := db.Ping(); err != nil {
+		t.Errorf("expected pool to stay open while a connection is in use, got %v", err)
+	}
+	conn.Close()
+
+	if err := <-errc; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Error("expected pool to be closed")
+	}
+}
+
+// TestAddDBDeadline verifies that the pool is closed at the deadline and that the dropped
+// connections are reported.
+func TestAddDBDeadline(t *testing.T) {
+	db, _ := sql.Open("closer-fake", "")
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	c.AddDB(db, WithTimeout(20*time.Millisecond))
+
+	if err := c.CloseAll(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected %v, got %v", ErrTimeout, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for db.Ping() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := db.Ping(); err == nil {
+		t.Error("expected pool to be closed")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !slices.Contains(l.events, "ERROR database connections dropped name=sql-db dropped=1") {
+		t.Errorf("expected dropped connection to be logged, got %v", l.events)
+	}
+}
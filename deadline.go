@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"io"
+	"time"
+)
+
+// WithHardDeadline makes New create a Closer that terminates the process with code if a
+// shutdown has not completed d after it was initiated, whatever it is stuck on: a hold, a
+// task or a closing function ignoring its context. Before exiting, it logs an Error "shutdown
+// hard deadline passed" event with the functions still running, and writes the stack traces
+// of all goroutines to the writer given to WithStackDump, if any. Set d below the grace period
+// of the orchestrator, so that the process exits on its own terms rather than being killed.
+// Unlike WithTimeout, which abandons stuck functions and lets CloseAll return, the hard
+// deadline ends the process, so it should be longer than the timeout.
+//
+// Example:
+//
+//	c := closer.New(
+//		closer.WithTimeout(20*time.Second),
+//		closer.WithHardDeadline(25*time.Second, 2),
+//	)
+func WithHardDeadline(d time.Duration, code int) Option {
+	return func(o *options) {
+		o.hardDeadline = &hardDeadline{d: d, code: code}
+	}
+}
+
+// hardDeadline configures the exit of a shutdown that takes too long.
+type hardDeadline struct {
+	d    time.Duration
+	code int
+	dump io.Writer // destination of the goroutine stack dump, nil to skip it
+}
+
+// armHardDeadline starts the watchdog of the hard deadline of c, if any, and returns a function
+// to stop it once the shutdown has completed.
+func (c *Closer) armHardDeadline() (disarm func()) {
+	h := c.watchdog
+	if h == nil {
+		return func() {}
+	}
+	t := c.clock.AfterFunc(h.d, func() {
+		var pending []string
+		c.mu.Lock()
+		if col := c.current; col != nil {
+			pending = col.pending(c.clock.Now())
+		}
+		c.mu.Unlock()
+		c.log().Error("shutdown hard deadline passed", "deadline", h.d, "pending", pending)
+		if h.dump != nil {
+			dumpStacks(h.dump)
+		}
+		exit(h.code)
+	})
+	return func() { t.Stop() }
+}
@@ -0,0 +1,54 @@
+package closer
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithHardDeadline verifies that a stuck shutdown logs what it waits on, dumps the
+// goroutine stacks and exits with the configured code, and that a completed one does not.
+func TestWithHardDeadline(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	l := &recordLogger{}
+	var dump syncBuffer
+	c := New(WithLogger(l), WithHardDeadline(20*time.Millisecond, 3), WithStackDump(&dump))
+	block := make(chan struct{})
+	c.AddNamed("stuck", func() error {
+		<-block
+		return nil
+	})
+	go c.CloseAll()
+
+	select {
+	case code := <-codes:
+		if code != 3 {
+			t.Errorf("expected exit code 3, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the process to exit at the hard deadline")
+	}
+	if !strings.Contains(dump.String(), "goroutine") {
+		t.Error("expected a stack dump")
+	}
+	l.mu.Lock()
+	events := strings.Join(l.events, "\n")
+	l.mu.Unlock()
+	if !strings.Contains(events, "ERROR shutdown hard deadline passed") || !strings.Contains(events, "stuck #0") {
+		t.Errorf("expected the stuck function to be logged, got %q", events)
+	}
+	close(block)
+	c.Wait()
+
+	c = New(WithHardDeadline(20*time.Millisecond, 3))
+	c.CloseAll()
+	select {
+	case code := <-codes:
+		t.Errorf("expected no exit after a completed shutdown, got %d", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
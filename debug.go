@@ -0,0 +1,157 @@
+package closer
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DebugHandler returns an HTTP handler describing c, suitable for mounting next to pprof:
+// its state, the registered functions with the steps they run in and where they were
+// registered, the functions a running shutdown is waiting on, and the report of the
+// completed shutdown, as well as the history kept with WithReportHistory. It responds with HTML, or with JSON if the request accepts
+// application/json or has a format=json query parameter. The handler exposes the call sites
+// of the application, so mount it on an internal port only.
+//
+// Example:
+//
+//	mux.Handle("/debug/closer", c.DebugHandler())
+func (c *Closer) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := c.debugView()
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(v)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugPage.Execute(w, v)
+	})
+}
+
+// debugView is what DebugHandler renders.
+type debugView struct {
+	State    string      `json:"state"`
+	Reason   string      `json:"reason,omitempty"`
+	Funcs    []debugFunc `json:"funcs"`
+	Pending  []debugFunc `json:"pending,omitempty"`
+	Duration string      `json:"duration,omitempty"` // of the completed shutdown
+	Results  []debugFunc `json:"results,omitempty"`  // of the completed shutdown
+	History  []debugRun  `json:"history,omitempty"`  // kept with WithReportHistory, oldest first
+}
+
+// debugRun summarizes a past shutdown or rehearsal in a debugView.
+type debugRun struct {
+	Reason   string `json:"reason"`
+	Start    string `json:"start"`
+	Duration string `json:"duration"`
+	Funcs    int    `json:"funcs"`
+	Failures int    `json:"failures"`
+}
+
+// debugFunc describes a closing function in a debugView.
+type debugFunc struct {
+	Index    int    `json:"index"`
+	Name     string `json:"name,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Step     int    `json:"step"`
+	Caller   string `json:"caller,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Err      string `json:"error,omitempty"`
+}
+
+// debugView describes c as rendered by DebugHandler.
+func (c *Closer) debugView() debugView {
+	state := c.State()
+	v := debugView{State: state.String(), Funcs: []debugFunc{}}
+	if state != StateIdle {
+		v.Reason = c.Reason().String()
+	}
+	callers := make(map[int]string)
+	for _, e := range c.registeredFuncs() {
+		callers[e.index] = e.caller
+	}
+	steps := make(map[int]int)
+	for _, s := range c.Plan() {
+		for _, f := range s.Funcs {
+			steps[f.Index] = s.Index
+			v.Funcs = append(v.Funcs, debugFunc{Index: f.Index, Name: f.Name, Label: f.Label, Step: s.Index, Caller: callers[f.Index]})
+		}
+	}
+	for _, f := range c.Pending() {
+		v.Pending = append(v.Pending, debugReport(f, steps))
+	}
+	if state == StateClosed {
+		r := c.Report()
+		v.Duration = r.Duration.Round(time.Microsecond).String()
+		for _, f := range r.Funcs {
+			v.Results = append(v.Results, debugReport(f, steps))
+		}
+	}
+	for _, r := range c.History() {
+		run := debugRun{
+			Reason:   r.Reason.String(),
+			Start:    r.Start.Format(time.RFC3339Nano),
+			Duration: r.Duration.Round(time.Microsecond).String(),
+			Funcs:    len(r.Funcs),
+		}
+		for _, f := range r.Funcs {
+			if f.Err != nil && !f.Optional {
+				run.Failures++
+			}
+		}
+		v.History = append(v.History, run)
+	}
+	return v
+}
+
+// debugReport describes f, which runs in the step given by steps, in a debugView.
+func debugReport(f FuncReport, steps map[int]int) debugFunc {
+	d := debugFunc{
+		Index:    f.Index,
+		Name:     f.Name,
+		Label:    f.Label,
+		Step:     steps[f.Index],
+		Caller:   f.Caller,
+		Duration: f.Duration.Round(time.Microsecond).String(),
+	}
+	if f.Err != nil {
+		d.Err = f.Err.Error()
+	}
+	return d
+}
+
+// debugPage renders a debugView as HTML.
+var debugPage = template.Must(template.New("closer").Parse(`<!DOCTYPE html>
+<html>
+<head><title>closer</title></head>
+<body>
+<h1>closer: {{.State}}</h1>
+{{with .Reason}}<p>Initiated by {{.}}.</p>{{end}}
+{{with .Pending}}<h2>Running</h2>
+<table>
+<tr><th>Index</th><th>Name</th><th>Label</th><th>Step</th><th>Running for</th><th>Registered at</th></tr>
+{{range .}}<tr><td>{{.Index}}</td><td>{{.Name}}</td><td>{{.Label}}</td><td>{{.Step}}</td><td>{{.Duration}}</td><td>{{.Caller}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Results}}<h2>Last shutdown, {{$.Duration}}</h2>
+<table>
+<tr><th>Index</th><th>Name</th><th>Label</th><th>Step</th><th>Duration</th><th>Error</th><th>Registered at</th></tr>
+{{range .}}<tr><td>{{.Index}}</td><td>{{.Name}}</td><td>{{.Label}}</td><td>{{.Step}}</td><td>{{.Duration}}</td><td>{{.Err}}</td><td>{{.Caller}}</td></tr>
+{{end}}</table>
+{{end}}{{with .History}}<h2>History</h2>
+<table>
+<tr><th>Reason</th><th>Started</th><th>Duration</th><th>Functions</th><th>Failures</th></tr>
+{{range .}}<tr><td>{{.Reason}}</td><td>{{.Start}}</td><td>{{.Duration}}</td><td>{{.Funcs}}</td><td>{{.Failures}}</td></tr>
+{{end}}</table>
+{{end}}<h2>Registered functions</h2>
+<table>
+<tr><th>Index</th><th>Name</th><th>Label</th><th>Step</th><th>Registered at</th></tr>
+{{range .Funcs}}<tr><td>{{.Index}}</td><td>{{.Name}}</td><td>{{.Label}}</td><td>{{.Step}}</td><td>{{.Caller}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,46 @@
+package closer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestDebugHandler verifies the JSON and HTML descriptions of a Closer before and after
+// shutdown.
+func TestDebugHandler(t *testing.T) {
+	c := New()
+	c.AddNamed("db", func() error { return errors.New("busy") })
+	h := c.DebugHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/closer?format=json", nil))
+	var v debugView
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.State != "idle" || len(v.Funcs) != 1 || v.Funcs[0].Name != "db" || !strings.Contains(v.Funcs[0].Caller, "debug_test.go") {
+		t.Errorf("expected idle with db registered here, got %+v", v)
+	}
+
+	c.CloseAll()
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/debug/closer", nil)
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(rec, req)
+	v = debugView{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.State != "closed" || len(v.Results) != 1 || v.Results[0].Err != "busy" {
+		t.Errorf("expected closed with the failure of db, got %+v", v)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/closer", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "closer: closed") || !strings.Contains(body, "<td>busy</td>") {
+		t.Errorf("expected an HTML page with the failure, got %s", body)
+	}
+}
@@ -0,0 +1,48 @@
+package closer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/pprof"
+	"syscall"
+)
+
+// OnDiagnostics makes sig, SIGQUIT if nil, write diagnostics to w with WriteDiagnostics each
+// time it is received, without shutting down, as operators expect from SIGQUIT. If sig was
+// given to WithSignals or Notify, it no longer triggers CloseAll. Like other signal
+// callbacks, diagnostics are written until shutdown starts, and sig is ignored during it.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM))
+//	c.OnDiagnostics(nil, os.Stderr) // kill -QUIT dumps goroutines and heap, keeps running
+func (c *Closer) OnDiagnostics(sig os.Signal, w io.Writer) {
+	mustNotBeNil(w, "diagnostics writer")
+	if sig == nil {
+		sig = syscall.SIGQUIT
+	}
+	// Register the callback first, so that sig stays subscribed while it is ignored.
+	c.onSignal(sig, func(c *Closer, sig os.Signal) {
+		if err := WriteDiagnostics(w); err != nil {
+			c.log().Error("diagnostics not written", "signal", sig, "error", err)
+		}
+	})
+	c.Ignore(sig)
+}
+
+// WriteDiagnostics writes the stack traces of all goroutines, in the format of an unrecovered
+// panic, followed by the heap profile in text form, to w.
+func WriteDiagnostics(w io.Writer) error {
+	_, err := fmt.Fprintln(w, "=== goroutines")
+	if err == nil {
+		err = pprof.Lookup("goroutine").WriteTo(w, 2)
+	}
+	if err == nil {
+		_, err = fmt.Fprintln(w, "\n=== heap")
+	}
+	if err == nil {
+		err = pprof.Lookup("heap").WriteTo(w, 1)
+	}
+	return err
+}
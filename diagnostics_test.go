@@ -0,0 +1,46 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestOnDiagnostics verifies that the diagnostics signal writes goroutines and heap without
+// shutting down, even when it was given to WithSignals.
+func TestOnDiagnostics(t *testing.T) {
+	term, quit := testSignal("term"), testSignal("quit")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term, quit), WithNotifier(n))
+	var buf syncBuffer
+	c.OnDiagnostics(quit, &buf)
+
+	if !n.send(quit) {
+		t.Fatal("expected quit to be subscribed")
+	}
+	if !n.send(term) {
+		t.Fatal("expected term to be subscribed")
+	}
+	c.Wait()
+	if r := c.Reason(); r.Signal != term {
+		t.Errorf("expected shutdown on term, got %v", r)
+	}
+	out := buf.String()
+	for _, want := range []string{"=== goroutines", "goroutine ", "=== heap", "heap profile"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected diagnostics to contain %q, got %q", want, out)
+		}
+	}
+}
+
+// failingWriter is an io.Writer that always fails.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+// TestWriteDiagnosticsError verifies that write errors are returned.
+func TestWriteDiagnosticsError(t *testing.T) {
+	if err := WriteDiagnostics(failingWriter{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
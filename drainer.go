@@ -0,0 +1,39 @@
+package closer
+
+import (
+	"context"
+	"errors"
+)
+
+// Drainer is implemented by message consumers, such as wrappers of Kafka, NATS or SQS
+// clients, to take part in the phases of a shutdown.
+type Drainer interface {
+	// Pause stops fetching new messages.
+	Pause()
+	// Drain waits for the messages already fetched to be processed and acknowledged, or for
+	// ctx to be done.
+	Drain(ctx context.Context) error
+	// Close releases the connection to the broker.
+	Close() error
+}
+
+// AddDrainer registers d to take part in shutdown, configured by opts: it is paused as a
+// quiescer, before the other closing functions or when Quiesce is called, then drained with
+// the shutdown context in a closing function that closes it afterwards, whether or not the
+// drain succeeded. The functions are named after the dynamic type of d. It panics if d is nil.
+//
+// Example:
+//
+//	c.AddDrainer(consumer, closer.WithPriority(10))
+//	c.AddNamed("db", db.Close)
+func (c *Closer) AddDrainer(d Drainer, opts ...Option) {
+	mustNotBeNil(d, "Drainer")
+	opts = append([]Option{withName(typeName(d))}, opts...)
+	c.add(append(opts, asQuiescer()), func() error {
+		d.Pause()
+		return nil
+	})
+	c.addContext(opts, func(ctx context.Context) error {
+		return errors.Join(d.Drain(ctx), d.Close())
+	})
+}
@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeDrainer records the calls made to a Drainer.
+type fakeDrainer struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (d *fakeDrainer) record(call string) {
+	d.mu.Lock()
+	d.calls = append(d.calls, call)
+	d.mu.Unlock()
+}
+
+func (d *fakeDrainer) Pause() { d.record("pause") }
+
+func (d *fakeDrainer) Drain(context.Context) error {
+	d.record("drain")
+	return d.err
+}
+
+func (d *fakeDrainer) Close() error {
+	d.record("close")
+	return nil
+}
+
+// TestAddDrainer verifies that a drainer is paused when quiescing, then drained and closed
+// even if the drain fails.
+func TestAddDrainer(t *testing.T) {
+	c := New()
+	boom := errors.New("boom")
+	d := &fakeDrainer{err: boom}
+	c.AddDrainer(d)
+
+	c.Quiesce()
+	if !slices.Equal(d.calls, []string{"pause"}) {
+		t.Errorf("expected only pause on Quiesce, got %v", d.calls)
+	}
+	if err := c.Terminate(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if !slices.Equal(d.calls, []string{"pause", "drain", "close"}) {
+		t.Errorf("expected pause, drain and close, got %v", d.calls)
+	}
+	if names := c.Names(); len(names) != 2 || names[1] != "*closer.fakeDrainer" {
+		t.Errorf("expected functions named after the type, got %v", names)
+	}
+}
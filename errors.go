@@ -0,0 +1,84 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+)
+
+// ShutdownError is the error returned by CloseAll and Wait when tasks or closing functions
+// failed. Its message lists every failure, as errors.Join does, and errors.Is and errors.As
+// see through it to each of them.
+//
+// Example:
+//
+//	var se *closer.ShutdownError
+//	if errors.As(c.Wait(), &se) {
+//		if err, ok := se.Errors()["db"]; ok {
+//			alert("database not closed cleanly", err)
+//		}
+//	}
+type ShutdownError struct {
+	errs   []error          // every failure, prefixed with what failed, in reporting order
+	byName map[string]error // failures by name, without the prefix of closing functions
+}
+
+// Error returns the messages of all failures, one per line.
+func (e *ShutdownError) Error() string {
+	var b strings.Builder
+	for i, err := range e.errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the failures, each prefixed with what failed.
+func (e *ShutdownError) Unwrap() []error {
+	return e.errs
+}
+
+// Errors returns the failures by what failed: the name of the closing function, its
+// identifier as it appears in log messages if it has no name, such as "kafka #3", "task"
+// for tasks started with Go, "barrier" for barriers given with WithPreShutdownBarrier, or
+// "fatal" for errors given to FailWith.
+// Failures sharing a name are joined.
+func (e *ShutdownError) Errors() map[string]error {
+	m := make(map[string]error, len(e.byName))
+	for name, err := range e.byName {
+		m[name] = err
+	}
+	return m
+}
+
+// add records err, the failure of name, reported as prefixed.
+func (e *ShutdownError) add(name string, err, prefixed error) {
+	e.errs = append(e.errs, prefixed)
+	if prev, ok := e.byName[name]; ok {
+		err = errors.Join(prev, err)
+	}
+	e.byName[name] = err
+}
+
+// shutdownError returns the error reporting fatal errors, taskErrs and failures, nil if
+// there are none.
+func shutdownError(fatal, taskErrs, barrierErrs []error, failures []result) error {
+	if len(fatal) == 0 && len(taskErrs) == 0 && len(barrierErrs) == 0 && len(failures) == 0 {
+		return nil
+	}
+	e := &ShutdownError{byName: make(map[string]error)}
+	for _, err := range fatal {
+		e.add("fatal", errors.Unwrap(err), err)
+	}
+	for _, err := range taskErrs {
+		e.add("task", err, err)
+	}
+	for _, err := range barrierErrs {
+		e.add("barrier", errors.Unwrap(err), err)
+	}
+	for _, r := range failures {
+		e.add(r.entry.key(), r.err, r.prefixed())
+	}
+	return e
+}
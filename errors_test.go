@@ -0,0 +1,41 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestShutdownError verifies that failures can be looked up by name, and that the error reads
+// and unwraps like joined errors.
+func TestShutdownError(t *testing.T) {
+	c := New()
+	dbErr, cacheErr := errors.New("db busy"), errors.New("cache busy")
+	c.AddNamed("db", func() error { return dbErr })
+	c.Group(WithLabel("cache")).Add(func() error { return cacheErr })
+	c.AddNamed("queue", func() error { return nil })
+
+	err := c.CloseAll()
+	var se *ShutdownError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected a *ShutdownError, got %T", err)
+	}
+	errs := se.Errors()
+	if len(errs) != 2 || errs["db"] != dbErr || errs["cache #1"] != cacheErr {
+		t.Errorf("expected db and cache #1 failures, got %v", errs)
+	}
+	if !errors.Is(err, dbErr) || !errors.Is(err, cacheErr) {
+		t.Errorf("expected both failures to be unwrapped, got %v", err)
+	}
+	if want := "db #0: db busy\ncache #1: cache busy"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+// TestShutdownErrorNil verifies that a clean shutdown returns a nil error interface.
+func TestShutdownErrorNil(t *testing.T) {
+	c := New()
+	c.Add(func() error { return nil })
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
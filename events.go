@@ -0,0 +1,109 @@
+package closer
+
+import (
+	"strconv"
+	"time"
+)
+
+// eventsBuffer is the capacity of the channels returned by Subscribe.
+const eventsBuffer = 256
+
+// EventKind identifies what an Event reports.
+type EventKind int
+
+const (
+	// ShutdownTriggered reports that shutdown was initiated, with its Reason.
+	ShutdownTriggered EventKind = iota + 1
+	// FuncStarted reports that a closing function was started, described by Func.
+	FuncStarted
+	// FuncFinished reports that a closing function succeeded, described by Func.
+	FuncFinished
+	// FuncFailed reports that a closing function failed, was abandoned or was not run at all,
+	// described by Func.
+	FuncFailed
+	// ShutdownCompleted reports that all closing functions have finished, with the error
+	// returned by CloseAll in Err.
+	ShutdownCompleted
+)
+
+// String returns the name of the kind.
+func (k EventKind) String() string {
+	switch k {
+	case ShutdownTriggered:
+		return "ShutdownTriggered"
+	case FuncStarted:
+		return "FuncStarted"
+	case FuncFinished:
+		return "FuncFinished"
+	case FuncFailed:
+		return "FuncFailed"
+	case ShutdownCompleted:
+		return "ShutdownCompleted"
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Event is a step of a shutdown, as delivered to subscribers.
+type Event struct {
+	Kind   EventKind
+	Time   time.Time  // when the event occurred
+	Reason Reason     // what initiated the shutdown
+	Func   FuncReport // function the event is about, for FuncStarted, FuncFinished and FuncFailed
+	Err    error      // error returned by CloseAll, for ShutdownCompleted
+}
+
+// Subscribe returns a channel receiving the events of the shutdown of c as they occur, so
+// that dashboards or sidecars can follow the teardown without parsing logs. The channel is
+// closed after the ShutdownCompleted event. Sending never blocks the shutdown: events
+// occurring while the channel holds 256 unreceived events are dropped. Every call returns a
+// new channel; to receive all events, subscribe before shutdown starts. Subscribing once the
+// shutdown has completed returns a closed channel.
+//
+// Example:
+//
+//	events := c.Subscribe()
+//	go func() {
+//		for ev := range events {
+//			dashboard.Push(ev.Kind.String(), ev.Func.Name)
+//		}
+//	}()
+func (c *Closer) Subscribe() <-chan Event {
+	ch := make(chan Event, eventsBuffer)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		close(ch)
+		return ch
+	}
+	c.subs = append(c.subs, ch)
+	return ch
+}
+
+// publisher delivers events to the subscribers of a shutdown.
+type publisher struct {
+	subs   []chan Event
+	reason Reason
+}
+
+// publish sends ev to every subscriber without blocking, completing it with the reason of
+// the shutdown. It does nothing on a nil publisher.
+func (p *publisher) publish(ev Event) {
+	if p == nil {
+		return
+	}
+	ev.Reason = p.reason
+	for _, ch := range p.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// funcEvent publishes the event of kind about the function of r.
+func (p *publisher) funcEvent(kind EventKind, at time.Time, r result) {
+	if p == nil {
+		return
+	}
+	p.publish(Event{Kind: kind, Time: at, Func: r.report()})
+}
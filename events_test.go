@@ -0,0 +1,61 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestSubscribe verifies the sequence of events of a shutdown and that the channel is closed
+// after the last one.
+func TestSubscribe(t *testing.T) {
+	c := New(WithSequential())
+	events := c.Subscribe()
+	boom := errors.New("boom")
+	c.AddNamed("cache", func() error { return nil })
+	c.AddNamed("db", func() error { return boom })
+	err := c.CloseAll()
+
+	var got []string
+	var last Event
+	for ev := range events {
+		got = append(got, ev.Kind.String()+" "+ev.Func.Name)
+		if ev.Reason.Kind != ReasonCall {
+			t.Errorf("expected %v, got %v", ReasonCall, ev.Reason.Kind)
+		}
+		last = ev
+	}
+	expected := []string{
+		"ShutdownTriggered ",
+		"FuncStarted cache",
+		"FuncFinished cache",
+		"FuncStarted db",
+		"FuncFailed db",
+		"ShutdownCompleted ",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if last.Err != err || !errors.Is(last.Err, boom) {
+		t.Errorf("expected completion with %v, got %v", err, last.Err)
+	}
+}
+
+// TestSubscribeAfterShutdown verifies that subscribing once shutdown has completed returns
+// a closed channel, and that every subscriber gets its own channel.
+func TestSubscribeAfterShutdown(t *testing.T) {
+	c := New()
+	a, b := c.Subscribe(), c.Subscribe()
+	if a == b {
+		t.Error("expected a channel per subscriber")
+	}
+	c.CloseAll()
+	for _, ch := range []<-chan Event{a, b} {
+		if n := len(ch); n != 2 {
+			t.Errorf("expected 2 events, got %d", n)
+		}
+	}
+	if _, ok := <-c.Subscribe(); ok {
+		t.Error("expected a closed channel")
+	}
+}
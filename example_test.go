@@ -0,0 +1,47 @@
+package closer_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/nzb3/closer"
+)
+
+// *http.Server satisfies the Shutdowner interface.
+var _ closer.Shutdowner = (*http.Server)(nil)
+
+func ExampleCloser_AddShutdowner() {
+	srv := &http.Server{Addr: ":8080"}
+
+	// srv.Shutdown receives a context that expires with the 30 second shutdown deadline.
+	c := closer.New(closer.WithTimeout(30 * time.Second))
+	c.AddShutdowner(context.Background(), srv)
+
+	c.CloseAll()
+	c.Wait()
+	// Output:
+}
+
+func ExampleNew() {
+	c := closer.New(
+		closer.WithSignals(syscall.SIGINT, syscall.SIGTERM),
+		closer.WithTimeout(30*time.Second),
+		closer.WithMaxConcurrency(16),
+		closer.WithForceExit(1),
+		closer.WithSlog(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	c.AddNamed("db", func() error {
+		fmt.Println("closing db")
+		return nil
+	})
+
+	fmt.Println(c.CloseAll())
+	// Output:
+	// closing db
+	// <nil>
+}
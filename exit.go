@@ -0,0 +1,119 @@
+package closer
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// exit terminates the process. It is a variable so that tests can intercept it.
+var exit = os.Exit
+
+// WithSignalExitCode makes ExitCode return code instead of 0 when a shutdown initiated by sig
+// succeeds, for example 130 for SIGINT as shells do.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(os.Interrupt, syscall.SIGTERM), closer.WithSignalExitCode(os.Interrupt, 130))
+func WithSignalExitCode(sig os.Signal, code int) Option {
+	return func(o *options) {
+		if o.exitCodes.signals == nil {
+			o.exitCodes.signals = make(map[os.Signal]int)
+		}
+		o.exitCodes.signals[sig] = code
+	}
+}
+
+// WithErrorExitCode makes ExitCode return code instead of 1 when the shutdown error matches
+// target according to errors.Is. Targets are tried in the order they are given, and the
+// first match wins.
+//
+// Example:
+//
+//	c := closer.New(closer.WithErrorExitCode(context.DeadlineExceeded, 124))
+func WithErrorExitCode(target error, code int) Option {
+	return func(o *options) {
+		o.exitCodes.errs = append(o.exitCodes.errs, errorExitCode{target: target, code: code})
+	}
+}
+
+// exitCodes maps shutdown outcomes to exit codes other than the defaults of ExitCode.
+type exitCodes struct {
+	signals map[os.Signal]int // codes of successful shutdowns by initiating signal
+	errs    []errorExitCode   // codes of failed shutdowns, tried in order
+}
+
+// errorExitCode is the exit code of shutdowns failing with an error matching target.
+type errorExitCode struct {
+	target error
+	code   int
+}
+
+// code returns the exit code of a shutdown initiated for reason that failed with err.
+func (e exitCodes) code(reason Reason, err error) int {
+	if err != nil {
+		for _, ec := range e.errs {
+			if errors.Is(err, ec.target) {
+				return ec.code
+			}
+		}
+		return 1
+	}
+	if code, ok := e.signals[reason.Signal]; ok && reason.Kind == ReasonSignal {
+		return code
+	}
+	return 0
+}
+
+// ExitCode waits for the shutdown to complete, like Wait, and returns the exit code the
+// process should terminate with: 0 if every closing function succeeded, and 1 if any of them
+// failed, timed out or was abandoned, unless WithSignalExitCode or WithErrorExitCode map the
+// outcome to another code.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGINT, syscall.SIGTERM))
+//	...
+//	os.Exit(c.ExitCode())
+func (c *Closer) ExitCode() int {
+	err := c.Wait()
+	return c.exitCodes.code(c.Reason(), err)
+}
+
+// CloseAllAndExit runs CloseAll and terminates the process with the code returned by
+// ExitCode. Deferred functions of the calling goroutine do not run.
+func (c *Closer) CloseAllAndExit() {
+	c.CloseAll()
+	exit(c.ExitCode())
+}
+
+// Hold blocks until the global closer has shut down, typically after one of the signals it
+// watches, and returns the errors of its closing functions, as Wait does. It is meant to end
+// main once the application is running.
+//
+// Hold changes the global closer: if it watches no signal triggering shutdown, as is the case
+// of the default one, Hold first makes it watch SIGINT and SIGTERM, as Notify does, so that
+// Hold does not block forever. These signals stay watched after Hold returns. Give the global
+// closer a signal with WithSignals or Notify to keep SIGINT and SIGTERM alone.
+//
+// Example:
+//
+//	go srv.ListenAndServe()
+//	closer.AddNamed("http", srv.Close)
+//	if err := closer.Hold(); err != nil {
+//		log.Print(err)
+//	}
+func Hold() error {
+	c := globalCloser.Load()
+	c.notifyUnlessWatched(os.Interrupt, syscall.SIGTERM)
+	return c.Wait()
+}
+
+// HoldWithExit is like Hold, watching SIGINT and SIGTERM in the same way, but then terminates
+// the process with the code returned by ExitCode of the global closer, replacing the usual
+// Wait and os.Exit at the end of main. Deferred functions of the calling goroutine do not run.
+func HoldWithExit() {
+	c := globalCloser.Load()
+	c.notifyUnlessWatched(os.Interrupt, syscall.SIGTERM)
+	exit(c.ExitCode())
+}
@@ -0,0 +1,128 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestExitCode verifies the exit codes of clean and failed shutdowns.
+func TestExitCode(t *testing.T) {
+	c := New()
+	c.CloseAll()
+	if code := c.ExitCode(); code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+
+	c = New()
+	c.Add(func() error { return errors.New("boom") })
+	c.CloseAll()
+	if code := c.ExitCode(); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+// TestCloseAllAndExit verifies that the process exits with the code of the shutdown.
+func TestCloseAllAndExit(t *testing.T) {
+	code := -1
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	c := New()
+	c.Add(func() error { return errors.New("boom") })
+	c.CloseAllAndExit()
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+// TestExitCodeMapping verifies that signals and errors are mapped to the configured codes.
+func TestExitCodeMapping(t *testing.T) {
+	sigint, sigterm := testSignal("int"), testSignal("term")
+	boom := errors.New("boom")
+	opts := []Option{
+		WithSignals(sigint, sigterm),
+		WithSignalExitCode(sigint, 130),
+		WithErrorExitCode(context.DeadlineExceeded, 124),
+		WithErrorExitCode(boom, 3),
+	}
+	tests := map[string]struct {
+		sig  os.Signal
+		err  error
+		code int
+	}{
+		"mapped signal":   {sig: sigint, code: 130},
+		"unmapped signal": {sig: sigterm, code: 0},
+		"mapped error":    {sig: sigint, err: fmt.Errorf("close: %w", boom), code: 3},
+		"unmapped error":  {sig: sigint, err: errors.New("other"), code: 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(opts...)
+			c.Add(func() error { return tt.err })
+			c.watcher.ch <- tt.sig
+			if code := c.ExitCode(); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+// TestHold verifies that Hold and HoldWithExit block until the global closer has shut down,
+// and report its outcome.
+func TestHold(t *testing.T) {
+	code := -1
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+	defer SetGlobal(SetGlobal(New()))
+
+	boom := errors.New("boom")
+	AddNamed("db", func() error { return boom })
+	time.AfterFunc(10*time.Millisecond, func() { CloseAll() })
+	if err := Hold(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	HoldWithExit()
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+// TestHoldSignals verifies that Hold makes a global closer watching no shutdown signal watch
+// SIGINT and SIGTERM, and leaves the signals of another one alone.
+func TestHoldSignals(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		n := &fakeNotifier{}
+		defer SetGlobal(SetGlobal(New(WithNotifier(n))))
+		done := make(chan error)
+		go func() { done <- Hold() }()
+		for !n.send(syscall.SIGTERM) {
+			time.Sleep(time.Millisecond)
+		}
+		if err := <-done; err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if r := Global().Reason(); r.Signal != syscall.SIGTERM {
+			t.Errorf("expected shutdown on SIGTERM, got %v", r)
+		}
+	})
+	t.Run("watched", func(t *testing.T) {
+		n := &fakeNotifier{}
+		usr := testSignal("usr")
+		defer SetGlobal(SetGlobal(New(WithNotifier(n), WithSignals(usr))))
+		done := make(chan error)
+		go func() { done <- Hold() }()
+		time.Sleep(10 * time.Millisecond)
+		if n.send(syscall.SIGTERM) {
+			t.Error("expected SIGTERM not to be watched")
+		}
+		n.send(usr)
+		if err := <-done; err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
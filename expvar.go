@@ -0,0 +1,61 @@
+package closer
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// WithExpvar makes New create a Closer that publishes its statistics as the expvar variable
+// name, so that scrapers of /debug/vars pick them up: the number of registered functions, the
+// State, and for a completed shutdown its duration in seconds and the number of functions
+// that failed it. The values are computed on every read. A Closer created later with the same
+// name, such as by a process recreating its wiring, replaces c as the one published. Like
+// expvar.Publish, New panics if the name is already published by other means.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithExpvar("closer"))
+func WithExpvar(name string) Option {
+	return func(o *options) {
+		o.expvar = name
+	}
+}
+
+// expvarStats are the statistics published by WithExpvar.
+type expvarStats struct {
+	Registered int     `json:"registered"`
+	State      string  `json:"state"`
+	Duration   float64 `json:"last_shutdown_seconds"`
+	Errors     int     `json:"last_shutdown_errors"`
+}
+
+// expvarClosers holds the Closer whose statistics are published under each expvar name, as
+// *atomic.Pointer[Closer], since expvar variables cannot be unpublished.
+var expvarClosers sync.Map
+
+// publishExpvar publishes the statistics of c as the expvar variable name, replacing the
+// Closer published under name before, if any.
+func (c *Closer) publishExpvar(name string) {
+	published := new(atomic.Pointer[Closer])
+	published.Store(c)
+	if prev, loaded := expvarClosers.LoadOrStore(name, published); loaded {
+		prev.(*atomic.Pointer[Closer]).Store(c)
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		c := published.Load()
+		state := c.State()
+		stats := expvarStats{Registered: c.Len(), State: state.String()}
+		if state == StateClosed {
+			r := c.Report()
+			stats.Duration = r.Duration.Seconds()
+			for _, f := range r.Funcs {
+				if f.Err != nil && !f.Optional {
+					stats.Errors++
+				}
+			}
+		}
+		return stats
+	}))
+}
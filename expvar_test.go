@@ -0,0 +1,36 @@
+package closer
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+)
+
+// TestWithExpvar verifies the statistics published before and after shutdown.
+func TestWithExpvar(t *testing.T) {
+	c := New(WithExpvar("closer-test"))
+	c.Add(func() error { return errors.New("boom") })
+	c.Add(func() error { return nil })
+	c.AddOptional(func() error { return errors.New("ignored") })
+	read := func() expvarStats {
+		var stats expvarStats
+		if err := json.Unmarshal([]byte(expvar.Get("closer-test").String()), &stats); err != nil {
+			t.Fatal(err)
+		}
+		return stats
+	}
+
+	if stats := read(); stats.Registered != 3 || stats.State != "idle" || stats.Errors != 0 {
+		t.Errorf("expected 3 idle functions, got %+v", stats)
+	}
+	c.CloseAll()
+	if stats := read(); stats.State != "closed" || stats.Errors != 1 || stats.Duration <= 0 {
+		t.Errorf("expected a closed shutdown with 1 error, got %+v", stats)
+	}
+
+	New(WithExpvar("closer-test")).Add(func() error { return nil })
+	if stats := read(); stats.Registered != 1 || stats.State != "idle" {
+		t.Errorf("expected the statistics of the new Closer, got %+v", stats)
+	}
+}
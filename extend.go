@@ -0,0 +1,142 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WithDeadlineExtension makes New create a Closer whose closing functions may push back the
+// shutdown deadline set by WithTimeout with ExtendDeadline, by up to max in total, so that
+// work close to completion, such as a backup upload, is not abandoned because of a static
+// timeout. Every extension is logged as an Info "shutdown deadline extended" event and the
+// total is reported in Report.Extended. Deadlines of the context given to CloseAllContext
+// cannot be extended.
+//
+// Example:
+//
+//	c := closer.New(closer.WithTimeout(30*time.Second), closer.WithDeadlineExtension(time.Minute))
+func WithDeadlineExtension(max time.Duration) Option {
+	return func(o *options) {
+		o.extension = max
+	}
+}
+
+// ExtendDeadline asks for the deadline of the shutdown whose context is ctx, as received by
+// a closing function, to be pushed back by d, and returns the extension granted: less than d
+// once the limit given to WithDeadlineExtension is nearly used up, and zero if it is used up,
+// if the Closer allows no extension or if the deadline has already passed.
+//
+// Example:
+//
+//	c.AddContext(func(ctx context.Context) error {
+//		for part := range upload.Parts() {
+//			if upload.Done() > 0.9 {
+//				closer.ExtendDeadline(ctx, 10*time.Second)
+//			}
+//			...
+//		}
+//	})
+func ExtendDeadline(ctx context.Context, d time.Duration) time.Duration {
+	x, ok := ctx.Value(extendableKey{}).(*extendable)
+	if !ok || d <= 0 {
+		return 0
+	}
+	return x.extend(d)
+}
+
+// extendableKey is the context key of the extendable deadline of a shutdown.
+type extendableKey struct{}
+
+// extendable is a shutdown context whose deadline can be pushed back. It is done with
+// context.DeadlineExceeded once the deadline passes, and with the error of its parent if
+// the parent is done first. Contexts derived from it report context.Canceled as their Err,
+// but context.Cause tells deadlines apart.
+type extendable struct {
+	context.Context // canceled with the reason the shutdown context is done
+	cancel          context.CancelCauseFunc
+	clock           Clock
+	max             time.Duration // extension available in total, zero for a plain timeout
+	log             Logger
+
+	mu       sync.Mutex
+	deadline time.Time
+	timer    Timer
+	left     time.Duration // extension still available
+	extended time.Duration // extension granted so far
+}
+
+// withExtendableTimeout returns a context like context.WithTimeout(parent, timeout), with
+// the deadline measured by clock, whose deadline can be pushed back by up to max in total.
+func withExtendableTimeout(parent context.Context, clock Clock, timeout, max time.Duration, l Logger) (*extendable, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	x := &extendable{Context: ctx, cancel: cancel, clock: clock, max: max, log: l, deadline: clock.Now().Add(timeout), left: max}
+	x.timer = clock.AfterFunc(timeout, x.expire)
+	stop := context.AfterFunc(parent, func() { cancel(parent.Err()) })
+	return x, func() {
+		x.timer.Stop()
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// expire cancels x if its deadline has passed, which it may not have if it was pushed back
+// while the timer fired.
+func (x *extendable) expire() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if wait := x.deadline.Sub(x.clock.Now()); wait > 0 {
+		x.timer.Reset(wait)
+		return
+	}
+	x.cancel(context.DeadlineExceeded)
+}
+
+// extend pushes the deadline back by d, within the extension left, and returns by how much.
+func (x *extendable) extend(d time.Duration) time.Duration {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.Context.Err() != nil {
+		return 0
+	}
+	d = min(d, x.left)
+	if d <= 0 {
+		return 0
+	}
+	x.left -= d
+	x.extended += d
+	x.deadline = x.deadline.Add(d)
+	left := x.deadline.Sub(x.clock.Now())
+	x.timer.Reset(left)
+	x.log.Info("shutdown deadline extended", "by", d, "left", left.Round(time.Millisecond))
+	return d
+}
+
+// total returns the extension granted so far.
+func (x *extendable) total() time.Duration {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.extended
+}
+
+func (x *extendable) Deadline() (time.Time, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.deadline, true
+}
+
+func (x *extendable) Err() error {
+	err := x.Context.Err()
+	if err != nil && errors.Is(context.Cause(x.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+func (x *extendable) Value(key any) any {
+	if key == (extendableKey{}) && x.max > 0 {
+		return x
+	}
+	return x.Context.Value(key)
+}
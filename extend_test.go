@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExtendDeadline verifies that a function can extend the deadline up to the limit, and
+// that the extension is reported.
+func TestExtendDeadline(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithTimeout(20*time.Millisecond), WithDeadlineExtension(60*time.Millisecond), WithLogger(l))
+	var granted []time.Duration
+	c.AddContext(func(ctx context.Context) error {
+		granted = append(granted, ExtendDeadline(ctx, 40*time.Millisecond), ExtendDeadline(ctx, 40*time.Millisecond))
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected the extended deadline to be met, got %v", err)
+	}
+	if len(granted) != 2 || granted[0] != 40*time.Millisecond || granted[1] != 20*time.Millisecond {
+		t.Errorf("expected 40ms then 20ms to be granted, got %v", granted)
+	}
+	if r := c.Report(); r.Extended != 60*time.Millisecond {
+		t.Errorf("expected 60ms extension to be reported, got %v", r.Extended)
+	}
+	if !strings.HasPrefix(l.events[1], "INFO shutdown deadline extended by=40ms left=") {
+		t.Errorf("expected the extension to be logged, got %v", l.events)
+	}
+}
+
+// TestExtendDeadlineExpires verifies that the extended deadline still expires, with
+// context.DeadlineExceeded, and that nothing is granted without the option.
+func TestExtendDeadlineExpires(t *testing.T) {
+	c := New(WithTimeout(10*time.Millisecond), WithDeadlineExtension(10*time.Millisecond))
+	c.AddContext(func(ctx context.Context) error {
+		ExtendDeadline(ctx, time.Second)
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+			t.Errorf("expected cause context.DeadlineExceeded, got %v", context.Cause(ctx))
+		}
+		return nil
+	})
+	start := time.Now()
+	if err := c.CloseAll(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("expected the shutdown to last the extended 20ms, got %v", d)
+	}
+
+	c = New(WithTimeout(time.Second))
+	c.AddContext(func(ctx context.Context) error {
+		if d := ExtendDeadline(ctx, time.Second); d != 0 {
+			t.Errorf("expected no extension, got %v", d)
+		}
+		return nil
+	})
+	c.CloseAll()
+}
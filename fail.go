@@ -0,0 +1,32 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+)
+
+// FailWith initiates shutdown because of err, a fatal error of the application, so that it
+// is told apart from a clean shutdown: the shutdown is reported with ReasonFailure and err,
+// Wait, Hold and CloseAll return an error wrapping err, under the name "fatal" in a
+// ShutdownError, and ExitCode returns 1, or the code WithErrorExitCode maps err to, even if
+// every closing function succeeds. If shutdown was initiated otherwise, err is still reported,
+// unless the shutdown has completed. FailWith does not wait for the shutdown to complete. It
+// panics if err is nil.
+//
+// Example:
+//
+//	if err := migrate(db); err != nil {
+//		c.FailWith(fmt.Errorf("migration: %w", err))
+//		return
+//	}
+func (c *Closer) FailWith(err error) {
+	mustNotBeNil(err, "error")
+	c.mu.Lock()
+	if !c.settled {
+		fatal := fmt.Errorf("fatal: %w", err)
+		c.fatal = append(c.fatal, fatal)
+		stream(c.errs, fatal)
+	}
+	c.mu.Unlock()
+	go c.closeAll(context.Background(), Reason{Kind: ReasonFailure, Err: err})
+}
@@ -0,0 +1,70 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// errBroken is a fatal error of the application.
+var errBroken = errors.New("broken")
+
+// TestFailWith verifies that a fatal error initiates shutdown, is reported as its reason and
+// by Wait even though every closing function succeeds, and maps to an exit code.
+func TestFailWith(t *testing.T) {
+	c := New(WithErrorExitCode(errBroken, 70))
+	ran := make(chan struct{})
+	c.Add(func() error {
+		close(ran)
+		return nil
+	})
+	c.FailWith(errBroken)
+
+	err := c.Wait()
+	if !errors.Is(err, errBroken) || err.Error() != "fatal: broken" {
+		t.Errorf("expected %q, got %v", "fatal: broken", err)
+	}
+	var se *ShutdownError
+	if !errors.As(err, &se) || se.Errors()["fatal"] != errBroken {
+		t.Errorf("expected the error under fatal, got %v", se)
+	}
+	select {
+	case <-ran:
+	default:
+		t.Error("expected the closing functions to run")
+	}
+	if r := c.Reason(); r.Kind != ReasonFailure || r.String() != "failure: broken" {
+		t.Errorf("expected failure reason, got %v", r)
+	}
+	if code := c.ExitCode(); code != 70 {
+		t.Errorf("expected exit code 70, got %d", code)
+	}
+}
+
+// TestFailWithDuringShutdown verifies that a fatal error during a shutdown initiated
+// otherwise is reported, and that one after it has completed is not.
+func TestFailWithDuringShutdown(t *testing.T) {
+	c := New()
+	release := make(chan struct{})
+	c.Add(func() error {
+		<-release
+		return nil
+	})
+	go c.CloseAll()
+	for c.State() != StateClosing {
+		time.Sleep(time.Millisecond)
+	}
+	c.FailWith(errBroken)
+	close(release)
+
+	if err := c.Wait(); !errors.Is(err, errBroken) {
+		t.Errorf("expected %v, got %v", errBroken, err)
+	}
+	if r := c.Reason(); r.Kind != ReasonCall {
+		t.Errorf("expected the CloseAll call to remain the reason, got %v", r)
+	}
+	c.FailWith(errors.New("late"))
+	if err := c.Wait(); err.Error() != "fatal: broken" {
+		t.Errorf("expected the late error to be dropped, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+	"weak"
+)
+
+// AddFlusher registers a function that flushes buffered data, such as a metrics buffer or a
+// log writer. Flushers run every time Flush is called and one final time, together with the
+// regular closing functions, when CloseAll is called.
+func (c *Closer) AddFlusher(f closeFunc) {
+	c.add([]Option{asFlusher()}, f)
+}
+
+// Flush runs all registered flushers concurrently and returns their errors joined in
+// registration order. It does not close the Closer and can be called any number of times.
+// Flush calls never overlap with each other or with the final flush performed by CloseAll;
+// once shutdown has started, Flush returns ErrClosed without running anything.
+func (c *Closer) Flush() error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	var flushers []entry
+	for _, e := range c.funcs.snapshot(false) {
+		if e.flusher {
+			flushers = append(flushers, e)
+		}
+	}
+	c.mu.Unlock()
+
+	col := collector{ignored: c.ignored, clock: c.clock}
+	runConcurrently(withClock(context.Background(), c.clock), flushers, c.limit, c.ordered, &col)
+	return errors.Join(col.errors()...)
+}
+
+// asFlusher marks a function as a flusher.
+func asFlusher() Option {
+	return func(o *options) {
+		o.flusher = true
+	}
+}
+
+// Flusher is implemented by buffered sinks, such as metrics clients, buffered writers and log
+// handlers, that lose the data still buffered unless flushed.
+type Flusher interface {
+	Flush() error
+}
+
+// AddSink registers s as a flusher, configured by opts, so that it is flushed on Flush and one
+// final time on shutdown. If interval is positive, s is also flushed every interval until
+// shutdown is initiated, limiting how much data is lost if the process is killed; these
+// flushes never overlap with Flush or the final flush, and their failures are logged as Error
+// "flush failed" events. The periodic flushes do not keep c reachable: they stop once c is
+// garbage collected. The function is named after the dynamic type of s. It panics if s is
+// nil.
+//
+// Example:
+//
+//	w := bufio.NewWriter(f)
+//	c.AddSink(w, 5*time.Second)
+func (c *Closer) AddSink(s Flusher, interval time.Duration, opts ...Option) {
+	mustNotBeNil(s, "Flusher")
+	name := typeName(s)
+	c.add(append([]Option{withName(name), asFlusher()}, opts...), s.Flush)
+	if interval <= 0 {
+		return
+	}
+	go flushEvery(weak.Make(c), c.Context(), c.collected(), s, name, c.clock.NewTicker(interval))
+}
+
+// collected returns a channel closed once c has been garbage collected, so that the
+// goroutines working on behalf of c through a weak pointer stop with it.
+func (c *Closer) collected() <-chan struct{} {
+	ch := make(chan struct{})
+	runtime.AddCleanup(c, func(ch chan struct{}) { close(ch) }, ch)
+	return ch
+}
+
+// flushEvery flushes s, the sink named name of the Closer ref points to, every time ticker
+// fires until ctx is done or collected is closed, once that Closer has been garbage collected.
+func flushEvery(ref weak.Pointer[Closer], ctx context.Context, collected <-chan struct{}, s Flusher, name string, ticker Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return
+		case <-collected:
+			return
+		}
+		c := ref.Value()
+		if c == nil {
+			return
+		}
+		c.flushMu.Lock()
+		if ctx.Err() == nil {
+			if err := s.Flush(); err != nil {
+				c.log().Error("flush failed", "name", name, "error", err)
+			}
+		}
+		c.flushMu.Unlock()
+	}
+}
@@ -0,0 +1,118 @@
+package closer
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFlush verifies that flushers run on every Flush and once more on CloseAll,
+// while regular closing functions run only on CloseAll.
+func TestFlush(t *testing.T) {
+	c := New()
+	var flushed, closed int32
+	c.AddFlusher(func() error {
+		atomic.AddInt32(&flushed, 1)
+		return nil
+	})
+	c.Add(func() error {
+		atomic.AddInt32(&closed, 1)
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := c.Flush(); err != nil {
+			t.Fatalf("unexpected flush error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&closed); n != 0 {
+		t.Fatalf("expected regular closer not to run on Flush, got %d", n)
+	}
+
+	c.CloseAll()
+	c.Wait()
+
+	if n := atomic.LoadInt32(&flushed); n != 3 {
+		t.Errorf("expected flusher to run 3 times, got %d", n)
+	}
+	if n := atomic.LoadInt32(&closed); n != 1 {
+		t.Errorf("expected regular closer to run once, got %d", n)
+	}
+}
+
+// TestFlushError verifies that Flush returns the errors of failing flushers.
+func TestFlushError(t *testing.T) {
+	c := New()
+	errFlush := errors.New("flush failed")
+	c.AddFlusher(func() error { return errFlush })
+
+	if err := c.Flush(); !errors.Is(err, errFlush) {
+		t.Errorf("expected %v, got %v", errFlush, err)
+	}
+}
+
+// TestFlushAfterClose verifies that Flush is rejected once shutdown has started.
+func TestFlushAfterClose(t *testing.T) {
+	c := New()
+	c.CloseAll()
+	c.Wait()
+
+	if err := c.Flush(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+}
+
+// countingSink counts its flushes.
+type countingSink struct {
+	flushes atomic.Int32
+}
+
+func (s *countingSink) Flush() error {
+	s.flushes.Add(1)
+	return nil
+}
+
+// TestAddSink verifies that a sink is flushed periodically while running and once more on
+// shutdown, and that periodic flushes stop once shutdown is initiated.
+func TestAddSink(t *testing.T) {
+	c := New()
+	s := &countingSink{}
+	c.AddSink(s, 5*time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for s.flushes.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := s.flushes.Load(); n < 2 {
+		t.Fatalf("expected periodic flushes, got %d", n)
+	}
+	c.CloseAll()
+	n := s.flushes.Load()
+	time.Sleep(20 * time.Millisecond)
+	if after := s.flushes.Load(); after != n {
+		t.Errorf("expected no flush after shutdown, got %d more", after-n)
+	}
+	if r := c.Report(); len(r.Funcs) != 1 || r.Funcs[0].Name != "*closer.countingSink" {
+		t.Errorf("expected a final flush named after the sink, got %+v", r.Funcs)
+	}
+}
+
+// TestAddSinkNoLeak verifies that the periodic flushes of a sink do not keep their Closer
+// alive, and stop once it is garbage collected.
+func TestAddSinkNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		New().AddSink(&countingSink{}, time.Hour)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,39 @@
+package closer
+
+// Group is a view of a Closer that registers closing functions with a shared set of options.
+// It holds no functions of its own: everything added through a group is forwarded to the
+// parent Closer. A Group is safe for concurrent use.
+type Group struct {
+	c    *Closer
+	opts []Option
+}
+
+// Group returns a view of the Closer that applies opts to every function registered
+// through it. Options passed to an individual registration override the group's options.
+//
+// Example:
+//
+//	g := c.Group(closer.WithLabel("kafka"), closer.WithTimeout(3*time.Second))
+//	g.Add(consumer.Close)
+//	g.AddNamed("producer", producer.Close)
+func (c *Closer) Group(opts ...Option) *Group {
+	return &Group{c: c, opts: append([]Option(nil), opts...)}
+}
+
+// Add registers one or more closing functions with the group's options.
+func (g *Group) Add(f ...closeFunc) {
+	g.c.add(g.opts, f...)
+}
+
+// AddNamed registers a single named closing function with the group's options,
+// overridden by opts.
+func (g *Group) AddNamed(name string, f closeFunc, opts ...Option) {
+	g.c.add(g.merge(append([]Option{withName(name)}, opts...)), f)
+}
+
+// merge returns the group's options followed by opts without modifying the group.
+func (g *Group) merge(opts []Option) []Option {
+	merged := make([]Option, 0, len(g.opts)+len(opts))
+	merged = append(merged, g.opts...)
+	return append(merged, opts...)
+}
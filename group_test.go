@@ -0,0 +1,116 @@
+package closer
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureLog redirects the standard logger into a buffer for the duration of the test.
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	prev := log.Writer()
+	log.SetOutput(buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return buf
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestGroupTimeout verifies that a group's timeout option applies to its members,
+// while functions registered directly on the Closer are not limited by it.
+func TestGroupTimeout(t *testing.T) {
+	buf := captureLog(t)
+	c := New()
+	g := c.Group(WithLabel("kafka"), WithTimeout(10*time.Millisecond))
+
+	slow := func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+	g.AddNamed("consumer", slow)
+	c.AddNamed("db", slow)
+
+	c.CloseAll()
+	c.Wait()
+
+	out := buf.String()
+	if !strings.Contains(out, "name=consumer label=kafka") || !strings.Contains(out, ErrTimeout.Error()) {
+		t.Errorf("expected timeout error for kafka/consumer, got %q", out)
+	}
+	if strings.Contains(out, "db") {
+		t.Errorf("expected no error for db, got %q", out)
+	}
+}
+
+// TestGroupOptionOverride verifies that options passed to a single registration
+// take precedence over the group's options.
+func TestGroupOptionOverride(t *testing.T) {
+	buf := captureLog(t)
+	c := New()
+	g := c.Group(WithTimeout(10 * time.Millisecond))
+
+	g.AddNamed("slow", func() error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}, WithTimeout(time.Second))
+
+	c.CloseAll()
+	c.Wait()
+
+	if out := buf.String(); strings.Contains(out, "error") {
+		t.Errorf("expected no errors, got %q", out)
+	}
+}
+
+// TestGroupConcurrentUse registers functions through a single group from many goroutines.
+func TestGroupConcurrentUse(t *testing.T) {
+	c := New()
+	g := c.Group(WithLabel("workers"))
+
+	var mu sync.Mutex
+	counter := 0
+	cleanup := func() error {
+		mu.Lock()
+		counter++
+		mu.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Add(cleanup)
+		}()
+	}
+	wg.Wait()
+
+	c.CloseAll()
+	c.Wait()
+
+	if counter != 10 {
+		t.Errorf("expected counter to be 10, got %d", counter)
+	}
+}
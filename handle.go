@@ -0,0 +1,39 @@
+package closer
+
+import "sync"
+
+// Handle refers to a closing function registered with AddRemovable.
+type Handle struct {
+	c    *Closer
+	at   slot
+	once sync.Once
+}
+
+// AddRemovable registers a single closing function configured by opts and returns a handle
+// to unregister it. It suits short-lived resources, such as per-request temporary
+// files or per-session connections, that are usually released before shutdown and would
+// otherwise accumulate in a long-running process.
+//
+// Example:
+//
+//	conn := dial()
+//	h := c.AddRemovable(conn.Close)
+//	defer func() {
+//		if h.Remove() {
+//			conn.Close()
+//		}
+//	}()
+func (c *Closer) AddRemovable(f closeFunc, opts ...Option) *Handle {
+	return &Handle{c: c, at: c.add(opts, f)}
+}
+
+// Remove unregisters the function so that it does not run on shutdown. It reports whether
+// the function was removed, which is false if shutdown has already taken the function or if
+// Remove was called before.
+func (h *Handle) Remove() bool {
+	removed := false
+	h.once.Do(func() {
+		removed = h.c.funcs.remove(h.at.shard, h.at.index)
+	})
+	return removed
+}
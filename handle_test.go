@@ -0,0 +1,37 @@
+package closer
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// TestAddRemovable verifies that removed functions do not run on shutdown, while the
+// others still do.
+func TestAddRemovable(t *testing.T) {
+	c := New()
+	var ran [3]int32
+	handles := make([]*Handle, 3)
+	for i := range handles {
+		handles[i] = c.AddRemovable(func() error {
+			atomic.StoreInt32(&ran[i], 1)
+			return nil
+		})
+	}
+
+	if !handles[1].Remove() {
+		t.Error("expected first Remove to remove the function")
+	}
+	if handles[1].Remove() {
+		t.Error("expected second Remove to report nothing removed")
+	}
+	c.CloseAll()
+
+	for i, expected := range []int32{1, 0, 1} {
+		if got := atomic.LoadInt32(&ran[i]); got != expected {
+			t.Errorf("expected function %d ran=%d, got %d", i, expected, got)
+		}
+	}
+	if handles[0].Remove() {
+		t.Error("expected Remove after shutdown to report nothing removed")
+	}
+}
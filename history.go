@@ -0,0 +1,138 @@
+package closer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+)
+
+// historySize is the number of durations kept per function by WithDurationHistory.
+const historySize = 20
+
+// minHistory is the number of durations a function needs before it can be reported as slow.
+const minHistory = 3
+
+// DurationStore persists the durations of named closing functions across shutdowns, for
+// WithDurationHistory. Durations are kept by function name, oldest first.
+type DurationStore interface {
+	// Load returns the durations saved last, or an empty map if there are none.
+	Load() (map[string][]time.Duration, error)
+	// Save replaces the saved durations with history.
+	Save(history map[string][]time.Duration) error
+}
+
+// WithDurationHistory makes New create a Closer that records how long its named functions
+// take in store, keeping the last 20 successful runs of each, and uses the durations recorded
+// by previous shutdowns to spot slow outliers: a function still running past the 95th
+// percentile of its past durations is logged right away with a Warn "closer slower than
+// usual" event, with the "p95", while functions finishing as usual are only logged as
+// finished. Functions need 3 recorded durations before they are reported. Failing to load
+// or save the durations is logged as an Error event without affecting the shutdown.
+//
+// Example:
+//
+//	c := closer.New(closer.WithDurationHistory(closer.FileDurationStore("/var/lib/app/shutdown.json")))
+func WithDurationHistory(store DurationStore) Option {
+	return func(o *options) {
+		o.history = store
+	}
+}
+
+// FileDurationStore returns a DurationStore keeping durations in the JSON file at path.
+// A missing file holds no durations.
+func FileDurationStore(path string) DurationStore {
+	return fileDurationStore(path)
+}
+
+// fileDurationStore is the DurationStore returned by FileDurationStore.
+type fileDurationStore string
+
+func (path fileDurationStore) Load() (map[string][]time.Duration, error) {
+	history := make(map[string][]time.Duration)
+	data, err := os.ReadFile(string(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return history, json.Unmarshal(data, &history)
+}
+
+func (path fileDurationStore) Save(history map[string][]time.Duration) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(string(path), data, 0o644)
+}
+
+// loadHistory returns the durations saved by the previous shutdowns, or nil if they cannot
+// be loaded.
+func (c *Closer) loadHistory(l Logger) map[string][]time.Duration {
+	history, err := c.history.Load()
+	if err != nil {
+		l.Error("duration history not loaded", "error", err)
+		return nil
+	}
+	return history
+}
+
+// slowThresholds returns the 95th percentile of the durations of every function of history
+// with enough of them.
+func slowThresholds(history map[string][]time.Duration) map[string]time.Duration {
+	thresholds := make(map[string]time.Duration, len(history))
+	for name, durations := range history {
+		if len(durations) < minHistory {
+			continue
+		}
+		sorted := slices.Sorted(slices.Values(durations))
+		thresholds[name] = sorted[(len(sorted)*95+99)/100-1]
+	}
+	return thresholds
+}
+
+// saveHistory adds the durations of the functions of results that succeeded to history
+// and saves it.
+func (c *Closer) saveHistory(l Logger, history map[string][]time.Duration, results []result) {
+	if history == nil {
+		history = make(map[string][]time.Duration)
+	}
+	for _, r := range results {
+		if r.entry.name == "" || r.err != nil || r.start.IsZero() {
+			continue
+		}
+		durations := append(history[r.entry.name], r.duration)
+		if len(durations) > historySize {
+			durations = durations[len(durations)-historySize:]
+		}
+		history[r.entry.name] = durations
+	}
+	if err := c.history.Save(history); err != nil {
+		l.Error("duration history not saved", "error", err)
+	}
+}
+
+// watchSlow logs the function of e, started at start, if it is still running once it has
+// run longer than usual, as described in WithDurationHistory.
+func (c *collector) watchSlow(e *entry, start time.Time) {
+	p95, ok := c.slow[e.name]
+	if !ok {
+		return
+	}
+	clock := c.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	clock.AfterFunc(start.Add(p95).Sub(clock.Now()), func() {
+		c.mu.Lock()
+		running := c.running[e] == start
+		c.mu.Unlock()
+		if running {
+			warn(c.slowLog, "closer slower than usual", e.attrs("running", clock.Now().Sub(start).Round(time.Millisecond), "p95", p95)...)
+		}
+	})
+}
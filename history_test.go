@@ -0,0 +1,94 @@
+package closer
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryDurationStore is a DurationStore kept in memory.
+type memoryDurationStore struct {
+	mu      sync.Mutex
+	history map[string][]time.Duration
+}
+
+func (s *memoryDurationStore) Load() (map[string][]time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := make(map[string][]time.Duration, len(s.history))
+	for name, durations := range s.history {
+		history[name] = slices.Clone(durations)
+	}
+	return history, nil
+}
+
+func (s *memoryDurationStore) Save(history map[string][]time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = history
+	return nil
+}
+
+// TestWithDurationHistory verifies that a function running past the 95th percentile of its
+// past durations is logged as slow, and that durations are recorded.
+func TestWithDurationHistory(t *testing.T) {
+	store := &memoryDurationStore{history: map[string][]time.Duration{
+		"db":    {time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond},
+		"cache": {time.Second, time.Second, time.Second},
+	}}
+	l := &recordLogger{}
+	c := New(WithDurationHistory(store), WithLogger(l))
+	c.AddNamed("db", func() error { time.Sleep(50 * time.Millisecond); return nil })
+	c.AddNamed("cache", func() error { return nil })
+	c.Add(func() error { return nil })
+	c.CloseAll()
+
+	l.mu.Lock()
+	var slow []string
+	for _, e := range l.events {
+		if strings.Contains(e, "slower than usual") {
+			slow = append(slow, e)
+		}
+	}
+	l.mu.Unlock()
+	if len(slow) != 1 || !strings.Contains(slow[0], "name=db") || !strings.Contains(slow[0], "p95=3ms") {
+		t.Errorf("expected db to be logged as slow, got %v", slow)
+	}
+
+	history, _ := store.Load()
+	if len(history["db"]) != 4 || history["db"][3] < 50*time.Millisecond || len(history["cache"]) != 4 || len(history) != 2 {
+		t.Errorf("expected the durations of db and cache to be recorded, got %v", history)
+	}
+}
+
+// TestFileDurationStore verifies that durations round-trip through a file, and that
+// a missing file holds none.
+func TestFileDurationStore(t *testing.T) {
+	store := FileDurationStore(filepath.Join(t.TempDir(), "durations.json"))
+	if history, err := store.Load(); err != nil || len(history) != 0 {
+		t.Fatalf("expected no durations, got %v and %v", history, err)
+	}
+	want := map[string][]time.Duration{"db": {time.Second, 2 * time.Second}}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got, err := store.Load(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v and %v", want, got, err)
+	}
+}
+
+// TestSlowThresholds verifies the 95th percentile and the minimum number of durations.
+func TestSlowThresholds(t *testing.T) {
+	var many []time.Duration
+	for i := 100; i > 0; i-- {
+		many = append(many, time.Duration(i))
+	}
+	got := slowThresholds(map[string][]time.Duration{"many": many, "few": {1, 2}})
+	if want := map[string]time.Duration{"many": 95}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
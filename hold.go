@@ -0,0 +1,44 @@
+package closer
+
+import "sync"
+
+// Hold delays shutdown while a critical section runs. While at least one hold is
+// outstanding, CloseAll blocks before running any closing function; this applies both to
+// programmatic calls and to shutdowns triggered by OS signals, which are recorded and
+// carried out once the last hold is released. Holds are counted, so nested holds must
+// each be released.
+//
+// Hold returns a release function, which is safe to call more than once, and true.
+// If shutdown has already been requested, Hold returns a no-op release function and false.
+//
+// Example:
+//
+//	release, ok := c.Hold()
+//	if !ok {
+//		return errShuttingDown
+//	}
+//	defer release()
+//	commitBatch()
+func (c *Closer) Hold() (release func(), ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return func() {}, false
+	}
+	c.holds++
+
+	var once sync.Once
+	return func() {
+		once.Do(c.release)
+	}, true
+}
+
+// release drops one hold and wakes up a pending CloseAll when none remain.
+func (c *Closer) release() {
+	c.mu.Lock()
+	c.holds--
+	if c.holds == 0 {
+		c.held.Broadcast()
+	}
+	c.mu.Unlock()
+}
@@ -0,0 +1,84 @@
+package closer
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHoldDelaysSignalShutdown verifies that a signal received while a hold is outstanding
+// does not run closing functions until the hold is released.
+func TestHoldDelaysSignalShutdown(t *testing.T) {
+	c := New(WithSignals(os.Interrupt))
+	var executed int32
+	c.Add(func() error {
+		atomic.AddInt32(&executed, 1)
+		return nil
+	})
+
+	release, ok := c.Hold()
+	if !ok {
+		t.Fatal("expected hold to be acquired")
+	}
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find process: %v", err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&executed); n != 0 {
+		t.Fatalf("expected no cleanup while held, got %d", n)
+	}
+
+	release()
+	c.Wait()
+
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expected cleanup function executed once, got %d", n)
+	}
+}
+
+// TestNestedHolds verifies that CloseAll waits for every outstanding hold.
+func TestNestedHolds(t *testing.T) {
+	c := New()
+	var executed int32
+	c.Add(func() error {
+		atomic.AddInt32(&executed, 1)
+		return nil
+	})
+
+	outer, _ := c.Hold()
+	inner, _ := c.Hold()
+	go c.CloseAll()
+
+	inner()
+	inner() // releasing twice must not drop the outer hold
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&executed); n != 0 {
+		t.Fatalf("expected no cleanup while outer hold is outstanding, got %d", n)
+	}
+
+	outer()
+	c.Wait()
+
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expected cleanup function executed once, got %d", n)
+	}
+}
+
+// TestHoldAfterShutdown verifies that holds cannot be acquired once shutdown was requested.
+func TestHoldAfterShutdown(t *testing.T) {
+	c := New()
+	c.CloseAll()
+
+	release, ok := c.Hold()
+	if ok {
+		t.Error("expected hold to fail after shutdown")
+	}
+	release()
+}
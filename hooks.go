@@ -0,0 +1,24 @@
+package closer
+
+// OnShutdownStart registers fn to be called as soon as shutdown is initiated, with what
+// initiated it, before holds are waited for and closing functions run. It suits actions that
+// must happen first, such as failing health checks so that load balancers stop sending
+// traffic. Hooks run one at a time in registration order; hooks registered once shutdown has
+// been initiated never run.
+func (c *Closer) OnShutdownStart(fn func(reason Reason)) {
+	mustNotBeNil(fn, "hook")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startHooks = append(c.startHooks, fn)
+}
+
+// OnShutdownEnd registers fn to be called once all closing functions have finished, with the
+// report of the shutdown, before Wait returns. It suits actions such as writing an audit log
+// or notifying a control plane. Hooks run one at a time in registration order; hooks
+// registered once shutdown has completed never run.
+func (c *Closer) OnShutdownEnd(fn func(report Report)) {
+	mustNotBeNil(fn, "hook")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endHooks = append(c.endHooks, fn)
+}
@@ -0,0 +1,46 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestShutdownHooks verifies that start hooks run before closing functions and end hooks
+// after them, before Wait returns.
+func TestShutdownHooks(t *testing.T) {
+	c := New()
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+	c.OnShutdownStart(func(r Reason) { record("start " + r.String()) })
+	c.Add(func() error {
+		record("close")
+		return errors.New("boom")
+	})
+	c.OnShutdownEnd(func(r Report) { record("end " + r.Funcs[0].Err.Error()) })
+
+	c.CloseAll()
+	expected := "start CloseAll call, close, end boom"
+	if got := strings.Join(events, ", "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestShutdownStartHookWhileHeld verifies that start hooks do not wait for holds.
+func TestShutdownStartHookWhileHeld(t *testing.T) {
+	c := New()
+	started := make(chan struct{})
+	c.OnShutdownStart(func(Reason) { close(started) })
+	release, _ := c.Hold()
+
+	go c.CloseAll()
+	<-started
+	release()
+	c.Wait()
+}
@@ -0,0 +1,164 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Ready reports whether the application should receive traffic, which is until shutdown is
+// initiated or Quiesce is called. It turns false before any closing function runs, and during
+// WithDrainDelay.
+func (c *Closer) Ready() bool {
+	return c.ctx.Err() == nil && !c.quiesced.Load()
+}
+
+// ReadyHandler returns an HTTP handler for readiness probes, such as /readyz. It responds
+// with 200 OK while Ready is true and with 503 Service Unavailable once shutdown has been
+// initiated, so that orchestrators and load balancers stop routing traffic to the process.
+//
+// Example:
+//
+//	mux.Handle("/readyz", c.ReadyHandler())
+func (c *Closer) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.Ready() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// HTTPMiddleware returns middleware that tracks the requests in flight through the handlers
+// it wraps, and registers a closing function, named "http-drain" and configured by opts, that
+// waits for them to complete. Once that function has started, new requests are rejected with
+// 503 Service Unavailable and a "Connection: close" header. The function runs before all
+// others, or in PhaseRequests if c declares it, as NewWebService does, unless opts give it
+// another priority or phase; it fails with an error wrapping the shutdown
+// context's error if requests are still in flight when the deadline passes.
+//
+// Example:
+//
+//	drain := c.HTTPMiddleware(closer.WithTimeout(10 * time.Second))
+//	srv := &http.Server{Handler: drain(mux)}
+func (c *Closer) HTTPMiddleware(opts ...Option) func(http.Handler) http.Handler {
+	t := c.tracker("requests", append([]Option{withName("http-drain")}, opts...))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !t.Begin() {
+				w.Header().Set("Connection", "close")
+				http.Error(w, "shutting down", http.StatusServiceUnavailable)
+				return
+			}
+			defer t.End()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithGrace bounds the graceful part of a server shutdown registered with AddHTTPServer:
+// connections still open after d are closed forcibly, and their number is reported with an
+// error wrapping ErrTimeout, while the shutdown deadline still leaves time to do so. Without
+// it, connections are only closed forcibly at the deadline.
+//
+// Example:
+//
+//	c := closer.New(closer.WithTimeout(30 * time.Second))
+//	c.AddHTTPServer(srv, closer.WithGrace(20*time.Second))
+func WithGrace(d time.Duration) Option {
+	return func(o *options) {
+		o.grace = d
+	}
+}
+
+// AddHTTPServer registers srv to be shut down gracefully, configured by opts. As soon as
+// shutdown is initiated, keep-alives are disabled so that clients open their next connections
+// elsewhere, for example during WithDrainDelay. srv.Shutdown then receives the shutdown
+// context; if it does not finish before the deadline, srv.Close forcibly closes the remaining
+// connections and the deadline error is reported, or it does so earlier as described in
+// WithGrace. http.ErrServerClosed is not reported. With WithGrace, srv must be registered
+// before it starts serving, as its connections are counted through srv.ConnState.
+// The function runs in PhaseRequests if c declares it, as NewWebService does, unless opts
+// give it another priority or phase. The function is named "http-server". It panics if srv is
+// nil.
+//
+// Example:
+//
+//	srv := &http.Server{Addr: ":8080", Handler: mux}
+//	c.AddHTTPServer(srv)
+//	go srv.ListenAndServe()
+func (c *Closer) AddHTTPServer(srv *http.Server, opts ...Option) {
+	mustNotBeNil(srv, "http.Server")
+	c.OnShutdownStart(func(Reason) {
+		srv.SetKeepAlivesEnabled(false)
+	})
+	grace := newOptions(opts).grace
+	var conns *connCounter
+	if grace > 0 {
+		conns = countConns(srv)
+	}
+	opts = append([]Option{withName("http-server"), c.phaseOr(PhaseRequests, WithPriority(0))}, opts...)
+	c.addContext(opts, func(ctx context.Context) error {
+		shutdownCtx := ctx
+		if grace > 0 {
+			var cancel context.CancelFunc
+			shutdownCtx, cancel = withTimeout(ctx, grace)
+			defer cancel()
+		}
+		err := srv.Shutdown(shutdownCtx)
+		if shutdownCtx.Err() != nil {
+			open := conns.open()
+			srv.Close()
+			if grace > 0 {
+				return fmt.Errorf("graceful shutdown %w: %w, %d connections closed", ErrTimeout, context.Cause(shutdownCtx), open)
+			}
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	})
+}
+
+// connCounter counts the open connections of an http.Server.
+type connCounter struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// countConns makes srv count its open connections in the returned connCounter, keeping any
+// ConnState hook srv already has.
+func countConns(srv *http.Server) *connCounter {
+	cc := &connCounter{conns: make(map[net.Conn]struct{})}
+	hook := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		cc.mu.Lock()
+		switch state {
+		case http.StateNew:
+			cc.conns[conn] = struct{}{}
+		case http.StateHijacked, http.StateClosed:
+			delete(cc.conns, conn)
+		}
+		cc.mu.Unlock()
+		if hook != nil {
+			hook(conn, state)
+		}
+	}
+	return cc
+}
+
+// open returns the number of open connections.
+func (cc *connCounter) open() int {
+	if cc == nil {
+		return 0
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return len(cc.conns)
+}
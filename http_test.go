@@ -0,0 +1,171 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestReadyHandler verifies that readiness fails as soon as shutdown is initiated, even
+// while a hold delays the closing functions.
+func TestReadyHandler(t *testing.T) {
+	c := New()
+	h := c.ReadyHandler()
+	probe := func() int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if code := probe(); code != http.StatusOK || !c.Ready() {
+		t.Errorf("expected ready before shutdown, got %d", code)
+	}
+	release, _ := c.Hold()
+	go c.CloseAll()
+	<-c.Done()
+	if code := probe(); code != http.StatusServiceUnavailable || c.Ready() {
+		t.Errorf("expected not ready during shutdown, got %d", code)
+	}
+	release()
+	c.Wait()
+}
+
+// TestHTTPMiddleware verifies that shutdown waits for requests in flight, rejects new ones
+// meanwhile, and only then runs the other closing functions.
+func TestHTTPMiddleware(t *testing.T) {
+	c := New()
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	h := c.HTTPMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(entered)
+			<-unblock
+		}
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	var closedEarly atomic.Bool
+	c.Add(func() error {
+		select {
+		case <-unblock:
+		default:
+			closedEarly.Store(true)
+		}
+		return nil
+	})
+
+	slow := make(chan int, 1)
+	go func() { slow <- serve("/slow").Code }()
+	<-entered
+	errc := make(chan error, 1)
+	go func() { errc <- c.CloseAll() }()
+
+	deadline := time.Now().Add(time.Second)
+	rec := serve("/")
+	for rec.Code == http.StatusOK && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		rec = serve("/")
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Connection") != "close" {
+		t.Errorf("expected new request to be rejected, got %d %v", rec.Code, rec.Header())
+	}
+
+	close(unblock)
+	if code := <-slow; code != http.StatusOK {
+		t.Errorf("expected request in flight to complete, got %d", code)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if closedEarly.Load() {
+		t.Error("expected closing functions to wait for the drain")
+	}
+}
+
+// TestHTTPMiddlewareDeadline verifies that the drain gives up when the deadline passes.
+func TestHTTPMiddlewareDeadline(t *testing.T) {
+	c := New()
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	defer close(unblock)
+	h := c.HTTPMiddleware(WithTimeout(10 * time.Millisecond))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(entered)
+		<-unblock
+	}))
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-entered
+
+	if err := c.CloseAll(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected %v, got %v", ErrTimeout, err)
+	}
+}
+
+// TestAddHTTPServer verifies that a server is shut down gracefully and that connections still
+// busy at the deadline are closed forcibly.
+func TestAddHTTPServer(t *testing.T) {
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(entered)
+		<-unblock
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	c := New(WithTimeout(20 * time.Millisecond))
+	c.AddHTTPServer(srv.Config)
+
+	reqErr := make(chan error, 1)
+	go func() {
+		resp, err := srv.Client().Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		reqErr <- err
+	}()
+	<-entered
+
+	if err := c.CloseAll(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case err := <-reqErr:
+		if err == nil {
+			t.Error("expected request to fail when its connection is closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected connection to be closed forcibly")
+	}
+}
+
+// TestAddHTTPServerGrace verifies that connections still busy after the grace period are
+// closed forcibly and counted.
+func TestAddHTTPServerGrace(t *testing.T) {
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(entered)
+		<-unblock
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	c := New(WithTimeout(time.Second))
+	c.AddHTTPServer(srv.Config, WithGrace(20*time.Millisecond))
+	srv.Start()
+	go func() {
+		if resp, err := srv.Client().Get(srv.URL); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	err := c.CloseAll()
+	if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "1 connections closed") {
+		t.Errorf("expected 1 connection closed after the grace period, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package closer
+
+import "errors"
+
+// WithIgnoredErrors makes New create a Closer that treats errors matching any of errs, as
+// reported by errors.Is, as success. Closing functions, flushers and tasks returning such
+// errors are neither logged as failures nor counted by metrics, and their errors are left out
+// of the reports and of the error returned by CloseAll. It suits errors that are expected
+// during shutdown, such as http.ErrServerClosed, context.Canceled or net.ErrClosed.
+//
+// Example:
+//
+//	c := closer.New(closer.WithIgnoredErrors(http.ErrServerClosed, net.ErrClosed))
+func WithIgnoredErrors(errs ...error) Option {
+	return func(o *options) {
+		o.ignored = append(o.ignored, errs...)
+	}
+}
+
+// ignore returns nil if err matches one of ignored, and err otherwise.
+func ignore(err error, ignored []error) error {
+	for _, target := range ignored {
+		if errors.Is(err, target) {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestWithIgnoredErrors verifies that ignored errors, even wrapped, are reported as success
+// while other errors are still returned.
+func TestWithIgnoredErrors(t *testing.T) {
+	l := &recordLogger{}
+	boom := errors.New("boom")
+	c := New(WithLogger(l), WithIgnoredErrors(http.ErrServerClosed, context.Canceled))
+	c.Add(func() error { return fmt.Errorf("serve: %w", http.ErrServerClosed) })
+	c.Add(func() error { return boom })
+	c.AddFlusher(func() error { return context.Canceled })
+
+	if err := c.Flush(); err != nil {
+		t.Errorf("expected no flush error, got %v", err)
+	}
+	err := c.CloseAll()
+	if !errors.Is(err, boom) || errors.Is(err, http.ErrServerClosed) || errors.Is(err, context.Canceled) {
+		t.Errorf("expected only %v, got %v", boom, err)
+	}
+	for i, f := range c.Report().Funcs {
+		if (f.Err != nil) != (i == 1) {
+			t.Errorf("expected only function #1 to fail, got #%d with %v", i, f.Err)
+		}
+	}
+	if events := l.events; events[len(events)-1] != "INFO shutdown finished failures=1" {
+		t.Errorf("expected 1 failure, got %v", events)
+	}
+}
+
+// TestWithIgnoredErrorsTask verifies that an ignored task error is not reported, while the
+// task still initiates shutdown.
+func TestWithIgnoredErrorsTask(t *testing.T) {
+	c := New(WithIgnoredErrors(http.ErrServerClosed))
+	c.Go(func(context.Context) error { return http.ErrServerClosed })
+	c.Wait()
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonTask {
+		t.Errorf("expected %v, got %v", ReasonTask, r.Kind)
+	}
+}
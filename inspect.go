@@ -0,0 +1,104 @@
+package closer
+
+import (
+	"sort"
+	"time"
+)
+
+// State is the stage of the lifecycle of a Closer.
+type State int
+
+const (
+	// StateIdle means that shutdown has not been initiated.
+	StateIdle State = iota
+	// StateClosing means that shutdown has been initiated and has not completed yet.
+	StateClosing
+	// StateClosed means that shutdown has completed.
+	StateClosed
+)
+
+// String returns the name of the state, such as "closing".
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateClosing:
+		return "closing"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// State returns the stage of the lifecycle c is in.
+func (c *Closer) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.closed:
+		return StateClosed
+	case c.closing:
+		return StateClosing
+	}
+	return StateIdle
+}
+
+// Len returns the number of closing functions registered, or, once shutdown has taken them,
+// the number it runs.
+func (c *Closer) Len() int {
+	return len(c.registeredFuncs())
+}
+
+// Names returns the names of the registered closing functions that have one, in
+// registration order, or once shutdown has taken them, of those it runs.
+func (c *Closer) Names() []string {
+	var names []string
+	for _, e := range c.registeredFuncs() {
+		if e.name != "" {
+			names = append(names, e.name)
+		}
+	}
+	return names
+}
+
+// Pending returns the closing functions running at the moment, in registration order, with
+// their Start and how long they have been running as their Duration. It returns nil when no
+// shutdown is running closing functions.
+//
+// Example:
+//
+//	for _, f := range c.Pending() {
+//		log.Printf("still waiting for %s after %v", f.Name, f.Duration)
+//	}
+func (c *Closer) Pending() []FuncReport {
+	c.mu.Lock()
+	col := c.current
+	c.mu.Unlock()
+	if col == nil {
+		return nil
+	}
+	return col.pendingReports(col.now())
+}
+
+// registeredFuncs returns the functions registered, or those taken by shutdown.
+func (c *Closer) registeredFuncs() []entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return c.registered
+	}
+	return c.funcs.snapshot(false)
+}
+
+// pendingReports describes the functions still running at now, in registration order.
+func (c *collector) pendingReports(now time.Time) []FuncReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reports := make([]FuncReport, 0, len(c.running))
+	for e, start := range c.running {
+		res := result{entry: e, start: start, duration: now.Sub(start)}
+		reports = append(reports, res.report())
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Index < reports[j].Index })
+	return reports
+}
@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestIntrospection verifies the state, functions and pending functions reported through a
+// shutdown.
+func TestIntrospection(t *testing.T) {
+	c := New()
+	release := make(chan struct{})
+	c.AddNamed("db", func() error {
+		<-release
+		return nil
+	})
+	c.Add(func() error { return nil })
+	c.AddNamed("cache", func() error { return nil })
+
+	if s := c.State(); s != StateIdle {
+		t.Errorf("expected idle, got %v", s)
+	}
+	if n := c.Len(); n != 3 {
+		t.Errorf("expected 3 functions, got %d", n)
+	}
+	if names := c.Names(); !slices.Equal(names, []string{"db", "cache"}) {
+		t.Errorf("expected [db cache], got %v", names)
+	}
+	if p := c.Pending(); p != nil {
+		t.Errorf("expected nothing pending before shutdown, got %v", p)
+	}
+
+	go c.CloseAll()
+	var pending []FuncReport
+	for range 100 {
+		if pending = c.Pending(); len(pending) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(pending) != 1 || pending[0].Name != "db" {
+		t.Errorf("expected db to be pending, got %+v", pending)
+	}
+	if s := c.State(); s != StateClosing {
+		t.Errorf("expected closing, got %v", s)
+	}
+	close(release)
+	c.Wait()
+
+	if s := c.State(); s != StateClosed {
+		t.Errorf("expected closed, got %v", s)
+	}
+	if n := c.Len(); n != 3 {
+		t.Errorf("expected 3 functions run, got %d", n)
+	}
+}
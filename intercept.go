@@ -0,0 +1,41 @@
+package closer
+
+import "context"
+
+// Interceptor wraps the closing function next, identified by name as in log events, such
+// as "kafka/consumer #3", and returns the function to run instead. It is called every time
+// the function runs, and typically calls next, adding timing, logging or circuit breaking
+// around it.
+type Interceptor func(name string, next func(ctx context.Context) error) func(ctx context.Context) error
+
+// WithInterceptor wraps closing functions with i. Passed to New, it applies to every
+// registered function; passed to a registration or a Group, it applies to those functions
+// only. Interceptors given to New wrap those given to a registration, and earlier interceptors
+// wrap later ones. Interceptors also apply to flushers run by Flush.
+//
+// Example:
+//
+//	c := closer.New(closer.WithInterceptor(func(name string, next func(context.Context) error) func(context.Context) error {
+//		return func(ctx context.Context) error {
+//			start := time.Now()
+//			defer func() { closeDuration.WithLabelValues(name).Observe(time.Since(start).Seconds()) }()
+//			return next(ctx)
+//		}
+//	}))
+func WithInterceptor(i Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, i)
+	}
+}
+
+// intercept returns a function running fn, identified by name, wrapped by interceptors, the
+// first one outermost.
+func intercept(interceptors []Interceptor, name string, fn contextFunc) contextFunc {
+	return func(ctx context.Context) error {
+		f := fn
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			f = interceptors[i](name, f)
+		}
+		return f(ctx)
+	}
+}
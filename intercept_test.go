@@ -0,0 +1,73 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestWithInterceptor verifies that interceptors given to New and to a registration wrap
+// functions in order, receive their names and can change their outcome.
+func TestWithInterceptor(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	trace := func(tag string) Interceptor {
+		return func(name string, next func(context.Context) error) func(context.Context) error {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				calls = append(calls, tag+" "+name)
+				mu.Unlock()
+				return next(ctx)
+			}
+		}
+	}
+	boom := errors.New("boom")
+	swallow := func(_ string, next func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if err := next(ctx); !errors.Is(err, boom) {
+				return err
+			}
+			return nil
+		}
+	}
+
+	c := New(WithInterceptor(trace("outer")))
+	c.AddNamed("db", func() error { return boom }, WithInterceptor(trace("inner")), WithInterceptor(swallow))
+	c.AddNamed("cache", func() error { return nil })
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected the error to be swallowed, got %v", err)
+	}
+	slices.Sort(calls)
+	expected := []string{"inner db #0", "outer cache #1", "outer db #0"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
+
+// TestWithInterceptorOrder verifies that earlier interceptors wrap later ones.
+func TestWithInterceptorOrder(t *testing.T) {
+	var order []string
+	tag := func(s string) Interceptor {
+		return func(_ string, next func(context.Context) error) func(context.Context) error {
+			return func(ctx context.Context) error {
+				order = append(order, s)
+				return next(ctx)
+			}
+		}
+	}
+	c := New(WithInterceptor(tag("first")), WithInterceptor(tag("second")))
+	c.Add(func() error {
+		order = append(order, "fn")
+		return nil
+	})
+	c.CloseAll()
+
+	if expected := []string{"first", "second", "fn"}; !slices.Equal(order, expected) {
+		t.Errorf("expected %v, got %v", expected, order)
+	}
+}
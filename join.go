@@ -0,0 +1,60 @@
+package closer
+
+import (
+	"context"
+	"errors"
+)
+
+// Join returns a Closer composed of cs, for applications embedding libraries that each
+// expose their own Closer. CloseAll on the composite closes all of cs concurrently and
+// returns once every one of them has completed, with their errors joined. The composite also
+// shuts down by itself once every one of cs has shut down, whatever initiated it, so that
+// its Wait covers the completion of all of them. Functions may be registered with the
+// composite as with any Closer; they run alongside the closing of cs.
+//
+// Example:
+//
+//	c := closer.Join(app, cache.Closer(), queue.Closer())
+//	...
+//	err := c.Wait()
+func Join(cs ...*Closer) *Closer {
+	for _, c := range cs {
+		mustNotBeNil(c, "Closer")
+	}
+	j := New()
+	for _, c := range cs {
+		j.AddContext(c.CloseAllContext)
+	}
+	if len(cs) > 0 {
+		go func() {
+			for _, c := range cs {
+				c.Wait()
+			}
+			j.CloseAll()
+		}()
+	}
+	return j
+}
+
+// WaitN waits for the shutdowns of all of cs to complete, as Wait does for each of them, and
+// returns their errors joined in the order of cs. It stops waiting when ctx is done and then
+// returns ctx.Err(); the shutdowns themselves keep running.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	err := closer.WaitN(ctx, api, worker)
+func WaitN(ctx context.Context, cs ...*Closer) error {
+	errs := make([]error, len(cs))
+	for i, c := range cs {
+		mustNotBeNil(c, "Closer")
+		select {
+		case <-c.completed():
+			errs[i] = c.result()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
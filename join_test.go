@@ -0,0 +1,80 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestJoin verifies that closing the composite closes all children and returns their errors.
+func TestJoin(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	a, b, ok := New(), New(), New()
+	a.Add(func() error { return errA })
+	b.Add(func() error { return errB })
+	closed := false
+	ok.Add(func() error { closed = true; return nil })
+
+	j := Join(a, b, ok)
+	err := j.CloseAll()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected errors of both children, got %v", err)
+	}
+	if !closed {
+		t.Error("expected all children to be closed")
+	}
+	if err := j.Wait(); !errors.Is(err, errA) {
+		t.Errorf("expected Wait to return the joined errors, got %v", err)
+	}
+}
+
+// TestJoinChildrenShutDown verifies that the composite completes once every child has shut
+// down on its own.
+func TestJoinChildrenShutDown(t *testing.T) {
+	errA := errors.New("a failed")
+	a, b := New(), New()
+	a.Add(func() error { return errA })
+	j := Join(a, b)
+
+	a.CloseAll()
+	select {
+	case <-j.Done():
+		t.Fatal("expected composite to keep running while a child is running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	b.CloseAll()
+	if err := j.WaitTimeout(time.Second); !errors.Is(err, errA) {
+		t.Errorf("expected %v, got %v", errA, err)
+	}
+}
+
+// TestJoinNil verifies that Join panics on a nil Closer.
+func TestJoinNil(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	Join(New(), nil)
+}
+
+// TestWaitN verifies that WaitN returns the errors of all Closers once all of them have
+// shut down, and gives up when its context is done.
+func TestWaitN(t *testing.T) {
+	errA := errors.New("a failed")
+	a, b := New(), New()
+	a.Add(func() error { return errA })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	a.CloseAll()
+	if err := WaitN(ctx, a, b); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	go b.CloseAll()
+	if err := WaitN(context.Background(), a, b); !errors.Is(err, errA) {
+		t.Errorf("expected %v, got %v", errA, err)
+	}
+}
@@ -0,0 +1,116 @@
+package closer
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// WithJSONReport makes New create a Closer that writes the Report of its shutdown as a single
+// line of JSON to w once all closing functions have finished, for log pipelines and
+// termination-log collectors to parse, as WriteJSON does. A nil w sends the JSON to the
+// Logger instead, as an Info "shutdown report" event with the "report" key.
+//
+// Example:
+//
+//	c := closer.New(closer.WithJSONReport(os.Stdout))
+func WithJSONReport(w io.Writer) Option {
+	return func(o *options) {
+		o.jsonReport = &jsonReport{w: w}
+	}
+}
+
+// jsonReport is the destination of the report written by WithJSONReport.
+type jsonReport struct {
+	w io.Writer // nil to log the report
+}
+
+// reportJSON is the JSON form of a Report.
+type reportJSON struct {
+	Reason   string     `json:"reason"`
+	Start    time.Time  `json:"start"`
+	Duration float64    `json:"duration_seconds"`
+	Extended float64    `json:"extended_seconds,omitempty"`
+	Clean    bool       `json:"clean"`
+	Failures int        `json:"failures"`
+	Funcs    []funcJSON `json:"funcs"`
+}
+
+// funcJSON is the JSON form of a FuncReport.
+type funcJSON struct {
+	Index    int     `json:"index"`
+	Name     string  `json:"name,omitempty"`
+	Label    string  `json:"label,omitempty"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration_seconds"`
+	Err      string  `json:"error,omitempty"`
+	Attempts int     `json:"attempts"`
+	Optional bool    `json:"optional,omitempty"`
+}
+
+// WriteJSON writes r to w as a single line of JSON: the reason, start, duration in seconds,
+// deadline extension in seconds if any, whether the shutdown was clean and the number of failures of required functions, followed
+// by every function with its status, which is one of "ok", "failed", "timeout", "panicked"
+// and "skipped", its duration in seconds and its error, if any.
+func (r Report) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.json())
+}
+
+// json returns the JSON form of r.
+func (r Report) json() reportJSON {
+	out := reportJSON{
+		Reason:   r.Reason.String(),
+		Start:    r.Start,
+		Duration: r.Duration.Seconds(),
+		Extended: r.Extended.Seconds(),
+		Funcs:    make([]funcJSON, len(r.Funcs)),
+	}
+	for i, f := range r.Funcs {
+		out.Funcs[i] = funcJSON{
+			Index:    f.Index,
+			Name:     f.Name,
+			Label:    f.Label,
+			Status:   f.status(),
+			Duration: f.Duration.Seconds(),
+			Attempts: f.Attempts,
+			Optional: f.Optional,
+		}
+		if f.Err != nil {
+			out.Funcs[i].Err = f.Err.Error()
+			if !f.Optional {
+				out.Failures++
+			}
+		}
+	}
+	out.Clean = out.Failures == 0
+	return out
+}
+
+// status returns the status of f in the JSON form of a Report.
+func (f FuncReport) status() string {
+	switch {
+	case f.Err == nil:
+		return "ok"
+	case f.Skipped:
+		return "skipped"
+	case f.Panicked:
+		return "panicked"
+	case f.TimedOut:
+		return "timeout"
+	}
+	return "failed"
+}
+
+// write writes r as configured by WithJSONReport, logging failures to l.
+func (j *jsonReport) write(l Logger, r Report) {
+	if j.w == nil {
+		data, err := json.Marshal(r.json())
+		if err == nil {
+			l.Info("shutdown report", "report", string(data))
+		}
+		return
+	}
+	if err := r.WriteJSON(j.w); err != nil {
+		l.Error("shutdown report not written", "error", err)
+	}
+}
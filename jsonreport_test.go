@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestWithJSONReport verifies that the report is written as a single JSON line with the
+// status of every function.
+func TestWithJSONReport(t *testing.T) {
+	var buf syncBuffer
+	c := New(WithJSONReport(&buf))
+	c.AddNamed("db", func() error { return errors.New("boom") })
+	c.AddNamed("cache", func() error { return nil }, WithLabel("storage"))
+	c.AddNamed("metrics", func() error { panic("oops") })
+	c.CloseAll()
+
+	out := buf.String()
+	if strings.Count(out, "\n") != 1 {
+		t.Fatalf("expected a single line, got %q", out)
+	}
+	var got reportJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+	if got.Reason != "CloseAll call" || got.Clean || got.Failures != 2 || len(got.Funcs) != 3 {
+		t.Fatalf("expected a failed shutdown with 3 functions, got %+v", got)
+	}
+	want := []funcJSON{
+		{Index: 0, Name: "db", Status: "failed", Err: "boom", Attempts: 1},
+		{Index: 1, Name: "cache", Label: "storage", Status: "ok", Attempts: 1},
+		{Index: 2, Name: "metrics", Status: "panicked", Attempts: 1},
+	}
+	for i, w := range want {
+		f := got.Funcs[i]
+		f.Duration = 0
+		if w.Err == "" {
+			f.Err = ""
+		}
+		if f != w {
+			t.Errorf("expected %+v, got %+v", w, f)
+		}
+	}
+}
+
+// TestWithJSONReportLogger verifies that the report goes to the logger without a writer.
+func TestWithJSONReportLogger(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithJSONReport(nil), WithLogger(l))
+	c.Add(func() error { return nil })
+	c.CloseAll()
+
+	last := l.events[len(l.events)-1]
+	if !strings.HasPrefix(last, `INFO shutdown report report={"reason":"CloseAll call"`) || !strings.Contains(last, `"clean":true`) {
+		t.Errorf("expected the JSON report to be logged, got %q", last)
+	}
+}
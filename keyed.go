@@ -0,0 +1,117 @@
+package closer
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Once returns a function that calls f the first time it is called and returns the error of
+// that call on every call, so that a resource closed both explicitly by the application and
+// by the Closer is only closed once.
+//
+// Example:
+//
+//	closeFile := closer.Once(f.Close)
+//	c.Add(closeFile)
+//	...
+//	if err := closeFile(); err != nil { // f is done with early
+//		return err
+//	}
+func Once(f func() error) func() error {
+	var once sync.Once
+	var err error
+	return func() error {
+		once.Do(func() {
+			err = f()
+		})
+		return err
+	}
+}
+
+// AddKeyed registers f under key, configured by opts, unless a function is already registered
+// under the same key, and reports whether f was registered. It suits resources that may be
+// registered from several places, such as a shared connection looked up by its address, and
+// must be closed once. The function is named key unless opts give it another name.
+//
+// Example:
+//
+//	conn := pool.Get(addr)
+//	c.AddKeyed("conn:"+addr, conn.Close)
+func (c *Closer) AddKeyed(key string, f closeFunc, opts ...Option) bool {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	if _, ok := c.keys[key]; ok {
+		return false
+	}
+	if c.keys == nil {
+		c.keys = make(map[string]slot)
+	}
+	c.keys[key] = c.add(append([]Option{withName(key)}, opts...), f)
+	return true
+}
+
+// ReplaceKeyed registers f under key, configured by opts, in place of the function already
+// registered under it, as when a resource is recreated after a configuration reload. The
+// swap is atomic with respect to other keyed registrations. It returns the replaced function,
+// which no longer runs on shutdown, so that the caller deliberately either runs it to close
+// the old resource right away or discards it; running it applies the timeout and panic
+// recovery of a shutdown. It returns nil if no function was registered under key, or if
+// shutdown has already taken it, in which case the shutdown runs it.
+//
+// Example:
+//
+//	c.OnReload(func() error {
+//		pool, err := sql.Open("postgres", newDSN())
+//		if err != nil {
+//			return err
+//		}
+//		if closeOld := c.ReplaceKeyed("db", pool.Close); closeOld != nil {
+//			return closeOld()
+//		}
+//		return nil
+//	})
+func (c *Closer) ReplaceKeyed(key string, f closeFunc, opts ...Option) (old func() error) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	if at, ok := c.keys[key]; ok {
+		if e, ok := c.funcs.take(at.shard, at.index); ok {
+			old = func() error {
+				return e.run(context.Background())
+			}
+		}
+	}
+	if c.keys == nil {
+		c.keys = make(map[string]slot)
+	}
+	c.keys[key] = c.add(append([]Option{withName(key)}, opts...), f)
+	return old
+}
+
+// AddResource registers f to close res, configured by opts, unless a function is already
+// registered for res, and reports whether f was registered. Resources are identified like map
+// keys: pointers, such as a *sql.DB, by the address they point to, so that a connection
+// registered from several code paths is closed once and reported once. The function is named
+// after the dynamic type of res unless opts give it another name. It panics if res is nil or
+// cannot be compared, such as a slice.
+//
+// Example:
+//
+//	c.AddResource(conn, conn.Close) // in every place that may be the first to use conn
+func (c *Closer) AddResource(res any, f closeFunc, opts ...Option) bool {
+	mustNotBeNil(res, "resource")
+	if !reflect.TypeOf(res).Comparable() {
+		panic("closer: resource of type " + typeName(res) + " cannot be compared")
+	}
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	if c.resources[res] {
+		return false
+	}
+	if c.resources == nil {
+		c.resources = make(map[any]bool)
+	}
+	c.resources[res] = true
+	c.add(append([]Option{withName(typeName(res))}, opts...), f)
+	return true
+}
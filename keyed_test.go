@@ -0,0 +1,116 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestOnce verifies that the wrapped function runs once and its error is returned every time.
+func TestOnce(t *testing.T) {
+	calls := 0
+	boom := errors.New("boom")
+	f := Once(func() error {
+		calls++
+		return boom
+	})
+	c := New()
+	c.Add(f)
+	if err := f(); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if err := c.CloseAll(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+// TestAddKeyed verifies that a key is registered once and names its function.
+func TestAddKeyed(t *testing.T) {
+	c := New()
+	calls := 0
+	f := func() error {
+		calls++
+		return nil
+	}
+	if !c.AddKeyed("conn:db", f) {
+		t.Error("expected the first registration to succeed")
+	}
+	if c.AddKeyed("conn:db", f) {
+		t.Error("expected the second registration to be ignored")
+	}
+	c.AddKeyed("conn:cache", f)
+	if names := c.Names(); len(names) != 2 || names[0] != "conn:db" {
+		t.Errorf("expected the functions to be named by key, got %v", names)
+	}
+	c.CloseAll()
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+// TestReplaceKeyed verifies that the replaced function is returned instead of running on
+// shutdown, also after a Reset.
+func TestReplaceKeyed(t *testing.T) {
+	c := New()
+	var closed []string
+	pool := func(name string) func() error {
+		return func() error {
+			closed = append(closed, name)
+			return nil
+		}
+	}
+	if old := c.ReplaceKeyed("db", pool("first")); old != nil {
+		t.Error("expected no function to replace")
+	}
+	old := c.ReplaceKeyed("db", pool("second"))
+	if old == nil {
+		t.Fatal("expected the first function to be replaced")
+	}
+	if err := old(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	c.CloseAll()
+	if !slices.Equal(closed, []string{"first", "second"}) {
+		t.Errorf("expected first closed on replacement and second on shutdown, got %v", closed)
+	}
+
+	closed = nil
+	c.Reset()
+	if old := c.ReplaceKeyed("db", pool("third")); old == nil {
+		t.Error("expected the function restored by Reset to be replaced")
+	}
+	c.CloseAll()
+	if !slices.Equal(closed, []string{"third"}) {
+		t.Errorf("expected only third closed, got %v", closed)
+	}
+}
+
+// TestAddResource verifies that a resource registered several times is closed and reported
+// once, and that distinct resources of the same type are not confused.
+func TestAddResource(t *testing.T) {
+	c := New()
+	a, b := &fakeCloser{}, &fakeCloser{}
+	if !c.AddResource(a, a.Close) || c.AddResource(a, a.Close) || !c.AddResource(b, b.Close) {
+		t.Fatal("expected only the first registration of each resource to be kept")
+	}
+	c.CloseAll()
+	if a.calls != 1 || b.calls != 1 {
+		t.Errorf("expected each resource to be closed once, got %d and %d", a.calls, b.calls)
+	}
+	if funcs := c.Report().Funcs; len(funcs) != 2 || funcs[0].Name != "*closer.fakeCloser" {
+		t.Errorf("expected 2 functions named after the resource type, got %+v", funcs)
+	}
+}
+
+// TestAddResourceNotComparable verifies that a resource that cannot be compared panics.
+func TestAddResourceNotComparable(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New().AddResource([]int{1}, func() error { return nil })
+}
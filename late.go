@@ -0,0 +1,71 @@
+package closer
+
+import "context"
+
+// LatePolicy decides what happens to closing functions registered once shutdown has started
+// running closing functions, which is too late for them to be part of it.
+type LatePolicy int
+
+const (
+	// DropLate discards late functions without running them and logs an error for each of
+	// them. It is the default policy.
+	DropLate LatePolicy = iota
+	// RunLate runs late functions right away, in the goroutine registering them, without
+	// the shutdown deadline. Their errors are logged, but not reported by Wait.
+	RunLate
+)
+
+// WithLatePolicy makes New create a Closer that handles functions registered once shutdown
+// has started according to p. TryAdd can be used instead to find out that a function was
+// registered too late.
+func WithLatePolicy(p LatePolicy) Option {
+	return func(o *options) {
+		o.late = p
+	}
+}
+
+// TryAdd is like Add, but returns ErrClosed without registering or running anything if
+// shutdown has already started running closing functions. Functions registered while holds
+// delay the shutdown are still accepted, because they still run.
+func (c *Closer) TryAdd(f ...closeFunc) error {
+	fns := make([]contextFunc, 0, len(f))
+	for _, fn := range f {
+		if c.valid(fn == nil, funcValue(fn)) {
+			fns = append(fns, withoutContext(fn))
+		}
+	}
+	_, err := c.register(nil, fns, true)
+	return err
+}
+
+// IsClosing reports whether shutdown has been initiated, that is whether Done is closed.
+func (c *Closer) IsClosing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closing
+}
+
+// IsClosed reports whether shutdown has completed, that is whether Wait would not block.
+func (c *Closer) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// registeredLate handles e, registered once shutdown has started, according to the policy.
+func (c *Closer) registeredLate(e entry) {
+	l := c.log()
+	if c.late != RunLate {
+		l.Error("closer registered after shutdown started", e.attrs("caller", e.caller)...)
+		return
+	}
+	if err := ignore(e.run(context.Background()), c.ignored); err != nil {
+		if c.onError != nil {
+			c.onError(e.key(), err)
+			return
+		}
+		l.Error("closer failed", e.attrs("error", err, "caller", e.caller)...)
+		return
+	}
+	l.Debug("closer finished", e.attrs()...)
+}
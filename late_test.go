@@ -0,0 +1,88 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestDropLate verifies that functions registered after shutdown are not run by default and
+// that each of them is logged.
+func TestDropLate(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	c.CloseAll()
+
+	var ran int32
+	c.AddNamed("late", func() error {
+		atomic.StoreInt32(&ran, 1)
+		return nil
+	})
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("expected late function not to run")
+	}
+	if got := l.events[len(l.events)-1]; got != "ERROR closer registered after shutdown started name=late index=0" {
+		t.Errorf("expected late registration to be logged, got %q", got)
+	}
+}
+
+// TestRunLate verifies that functions registered after shutdown run right away with RunLate.
+func TestRunLate(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithLatePolicy(RunLate))
+	c.CloseAll()
+
+	var ran int32
+	c.Add(func() error {
+		atomic.StoreInt32(&ran, 1)
+		return errors.New("boom")
+	})
+	if atomic.LoadInt32(&ran) != 1 {
+		t.Error("expected late function to run")
+	}
+	if got := l.events[len(l.events)-1]; !strings.HasPrefix(got, "ERROR closer failed index=0 error=boom") {
+		t.Errorf("expected late failure to be logged, got %q", got)
+	}
+}
+
+// TestTryAdd verifies that TryAdd refuses functions once shutdown has started.
+func TestTryAdd(t *testing.T) {
+	c := New()
+	var ran int32
+	f := func() error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+	if err := c.TryAdd(f); err != nil {
+		t.Errorf("expected TryAdd to succeed before shutdown, got %v", err)
+	}
+	c.CloseAll()
+	if err := c.TryAdd(f); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	if n := atomic.LoadInt32(&ran); n != 1 {
+		t.Errorf("expected function to run once, got %d", n)
+	}
+}
+
+// TestIsClosing verifies the state reported before, during and after shutdown.
+func TestIsClosing(t *testing.T) {
+	c := New()
+	release, _ := c.Hold()
+	if c.IsClosing() || c.IsClosed() {
+		t.Error("expected neither closing nor closed before shutdown")
+	}
+
+	go c.CloseAll()
+	<-c.Done()
+	if !c.IsClosing() || c.IsClosed() {
+		t.Error("expected closing but not closed while held")
+	}
+
+	release()
+	c.Wait()
+	if !c.IsClosing() || !c.IsClosed() {
+		t.Error("expected closing and closed after shutdown")
+	}
+}
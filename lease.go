@@ -0,0 +1,74 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Retry settings of AddLease.
+const (
+	leaseBackoff     = 50 * time.Millisecond // wait before the first retry, doubled for each following one
+	leaseMaxBackoff  = time.Second           // longest wait between attempts
+	leaseMaxAttempts = 5                     // attempts without a deadline
+)
+
+// ErrLeaseNotReleased is returned by functions registered with AddLease when the release of
+// the lease could not be verified.
+var ErrLeaseNotReleased = errors.New("closer: lease not released")
+
+// AddLease registers a lease or distributed lock to be released when CloseAll is called,
+// configured by opts. After release returns, verify reports whether the lease is actually
+// gone; until it is, release is tried again with a growing backoff, as long as the deadline of
+// the function or of the shutdown leaves time for the wait, or for 5 attempts without one. Both functions
+// receive the shutdown context. Failing to release the lease is reported with an error
+// wrapping ErrLeaseNotReleased and the last error of release or verify. Losing a lease to a
+// shutdown running out of time is often worse than other failures, so consider Critical. The
+// function is named "lease". It panics if release or verify is nil.
+//
+// Example:
+//
+//	c.AddLease(lock.Release, lock.IsReleased, closer.Critical(2*time.Second))
+func (c *Closer) AddLease(release func(ctx context.Context) error, verify func(ctx context.Context) (bool, error), opts ...Option) {
+	mustNotBeNil(release, "lease release function")
+	mustNotBeNil(verify, "lease verify function")
+	opts = append([]Option{withName("lease")}, opts...)
+	c.addContext(opts, func(ctx context.Context) error {
+		deadline, bounded := ctx.Deadline()
+		backoff := leaseBackoff
+		for attempt := 1; ; attempt++ {
+			err := release(ctx)
+			if err == nil {
+				var released bool
+				released, err = verify(ctx)
+				if err == nil && released {
+					return nil
+				}
+			}
+			// Give up before a retry could not complete in time, as the function is abandoned
+			// at its deadline, losing the error.
+			clock := clockFrom(ctx)
+			if bounded && deadline.Sub(clock.Now()) < backoff || !bounded && attempt >= leaseMaxAttempts {
+				return leaseNotReleased(attempt, err)
+			}
+			t := clock.NewTimer(backoff)
+			select {
+			case <-t.C():
+			case <-ctx.Done():
+				t.Stop()
+				return leaseNotReleased(attempt, errors.Join(err, context.Cause(ctx)))
+			}
+			backoff = min(2*backoff, leaseMaxBackoff)
+		}
+	})
+}
+
+// leaseNotReleased returns the error reported when a lease is still held after attempts,
+// the last of which failed with err, if any.
+func leaseNotReleased(attempts int, err error) error {
+	if err == nil {
+		return fmt.Errorf("%w after %d attempts", ErrLeaseNotReleased, attempts)
+	}
+	return fmt.Errorf("%w after %d attempts: %w", ErrLeaseNotReleased, attempts, err)
+}
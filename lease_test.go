@@ -0,0 +1,45 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestAddLease verifies that release is retried until verify confirms it.
+func TestAddLease(t *testing.T) {
+	c := New()
+	releases := 0
+	c.AddLease(func(context.Context) error {
+		releases++
+		if releases == 1 {
+			return errors.New("conflict")
+		}
+		return nil
+	}, func(context.Context) (bool, error) {
+		return releases == 3, nil
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if releases != 3 {
+		t.Errorf("expected 3 releases, got %d", releases)
+	}
+}
+
+// TestAddLeaseNotReleased verifies that a lease still held at the deadline is reported with
+// the last error.
+func TestAddLeaseNotReleased(t *testing.T) {
+	c := New()
+	held := errors.New("held by us")
+	c.AddLease(func(context.Context) error { return nil }, func(context.Context) (bool, error) {
+		return false, held
+	}, WithTimeout(120*time.Millisecond))
+
+	err := c.CloseAll()
+	if !errors.Is(err, ErrLeaseNotReleased) || !errors.Is(err, held) {
+		t.Errorf("expected %v with %v, got %v", ErrLeaseNotReleased, held, err)
+	}
+}
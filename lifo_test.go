@@ -0,0 +1,137 @@
+package closer
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLIFO verifies that functions run one at a time in reverse registration order.
+func TestLIFO(t *testing.T) {
+	c := New(WithLIFO())
+	var order []string // unsynchronized on purpose: sequential execution must not race
+	for _, name := range []string{"logger", "db", "http"} {
+		c.AddNamed(name, func() error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	c.CloseAll()
+
+	if expected := []string{"http", "db", "logger"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+// TestPlanLIFO compares the plan of a LIFO configuration against a golden structure.
+func TestPlanLIFO(t *testing.T) {
+	c := New(WithLIFO())
+	noop := func() error { return nil }
+	c.AddNamed("logger", noop)
+	c.AddNamed("db", noop)
+	c.AddNamed("listener", noop, WithPriority(10))
+	c.AddNamed("http", noop)
+
+	want := []PlanStep{
+		{Index: 0, Concurrent: false, Funcs: []PlanFunc{{Index: 2, Name: "listener", Prio: 10}}},
+		{Index: 1, Concurrent: false, Funcs: []PlanFunc{{Index: 3, Name: "http"}, {Index: 1, Name: "db"}, {Index: 0, Name: "logger"}}},
+	}
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected plan %+v, got %+v", want, got)
+	}
+}
+
+// TestWithSequential verifies that functions run one at a time in registration order.
+func TestWithSequential(t *testing.T) {
+	c := New(WithSequential())
+	var (
+		order   []int
+		running atomic.Int32
+	)
+	for i := range 5 {
+		c.Add(func() error {
+			if running.Add(1) > 1 {
+				t.Error("expected functions not to overlap")
+			}
+			time.Sleep(time.Millisecond)
+			order = append(order, i)
+			running.Add(-1)
+			return nil
+		})
+	}
+	c.CloseAll()
+
+	if !slices.Equal(order, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("expected registration order, got %v", order)
+	}
+	if c.Plan()[0].Concurrent {
+		t.Error("expected the plan to show a sequential step")
+	}
+}
+
+// TestWithFailFast verifies that the functions following a failure are skipped and reported
+// as such, in sequential mode and across the steps of concurrent mode.
+func TestWithFailFast(t *testing.T) {
+	boom := errors.New("boom")
+	c := New(WithSequential(), WithFailFast())
+	var ran atomic.Int32
+	c.Add(func() error { ran.Add(1); return nil })
+	c.Add(func() error { ran.Add(1); return boom })
+	c.Add(func() error { ran.Add(1); return nil })
+
+	err := c.CloseAll()
+	if !errors.Is(err, boom) || !errors.Is(err, ErrSkipped) {
+		t.Errorf("expected %v and %v, got %v", boom, ErrSkipped, err)
+	}
+	if n := ran.Load(); n != 2 {
+		t.Errorf("expected 2 functions to run, got %d", n)
+	}
+	if funcs := c.Report().Funcs; funcs[1].Skipped || !funcs[2].Skipped {
+		t.Errorf("expected only the last function to be skipped, got %+v", funcs)
+	}
+
+	c = New(WithFailFast())
+	ran.Store(0)
+	c.AddWithPriority(1, func() error { ran.Add(1); return boom }, func() error { ran.Add(1); return nil })
+	c.Add(func() error { ran.Add(1); return nil })
+	c.CloseAll()
+	if n := ran.Load(); n != 2 {
+		t.Errorf("expected the failing step to complete and the next to be skipped, got %d runs", n)
+	}
+}
+
+// TestWithRegistrationOrder verifies that concurrent functions start in registration order,
+// with and without a concurrency limit, while still overlapping.
+func TestWithRegistrationOrder(t *testing.T) {
+	for _, limit := range []int{0, 4} {
+		c := New(WithRegistrationOrder(), WithMaxConcurrency(limit))
+		var mu sync.Mutex
+		var started []int
+		release := make(chan struct{})
+		for i := range 50 {
+			c.Add(func() error {
+				mu.Lock()
+				started = append(started, i)
+				n := len(started)
+				mu.Unlock()
+				if n == 2 {
+					// The first two functions overlap.
+					close(release)
+				}
+				if i == 0 {
+					<-release
+				}
+				return nil
+			})
+		}
+		c.CloseAll()
+		if !slices.IsSorted(started) || len(started) != 50 {
+			t.Errorf("expected functions to start in registration order with limit %d, got %v", limit, started)
+		}
+	}
+}
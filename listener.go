@@ -0,0 +1,31 @@
+package closer
+
+import (
+	"errors"
+	"math"
+	"net"
+)
+
+// AddListener registers l to be closed before all other closing functions, or in
+// PhaseListeners if c declares it, as NewWebService does, configured by opts, so that no new
+// connections are accepted while the servers serving connections already accepted drain in
+// later steps. A listener that is already closed is not an error, and neither is net.ErrClosed
+// returned by an accept loop started with Go once shutdown has been initiated. The function is
+// named "listener". It panics if l is nil.
+//
+// Example:
+//
+//	l, err := net.Listen("tcp", ":9000")
+//	...
+//	c.AddListener(l)
+//	c.Go(func(context.Context) error { return serve(l) })
+func (c *Closer) AddListener(l net.Listener, opts ...Option) {
+	mustNotBeNil(l, "net.Listener")
+	opts = append([]Option{withName("listener"), c.phaseOr(PhaseListeners, WithPriority(math.MaxInt))}, opts...)
+	c.add(opts, func() error {
+		if err := l.Close(); !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// TestAddListener verifies that the listener is closed before other functions, and that
+// neither closing it twice nor an accept loop stopped by the shutdown is reported.
+func TestAddListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.AddListener(l)
+	c.Go(func(ctx context.Context) error {
+		context.AfterFunc(ctx, func() { l.Close() })
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			conn.Close()
+		}
+	})
+	var acceptErr error
+	c.Add(func() error {
+		_, acceptErr = l.Accept()
+		return nil
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !errors.Is(acceptErr, net.ErrClosed) {
+		t.Errorf("expected the listener to be closed first, got %v", acceptErr)
+	}
+}
@@ -0,0 +1,120 @@
+package closer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives the structured events a Closer emits during shutdown. Each method takes a
+// message followed by alternating keys and values, as the methods of *slog.Logger do, so a
+// *slog.Logger can be used directly.
+//
+// A Closer emits the following events:
+//   - Warn "previous shutdown interrupted" from New, with the functions left "unfinished" and
+//     "failed", if WithStateFile is given, as an Info event if the Logger has no Warn method,
+//     and Error "state file not read" and "state file not written" if the file cannot be
+//     read or written
+//   - Info "quiescing" with the number of quiescers when Quiesce is called
+//   - Info "closing tagged" with the tags and the number of functions when CloseTagged is called
+//   - Error "memory limit exceeded" with the "usage" and "threshold" before WithMemoryWatchdog
+//     initiates shutdown
+//   - Info "shutdown started" with the reason and the number of registered functions
+//   - Info "shutdown step" for each step of the shutdown, if WithPlanLogging is given
+//   - Error "pre-shutdown barrier failed" for each barrier given with WithPreShutdownBarrier
+//     that failed, with its "barrier" index and the "error"
+//   - Info "shutdown deadline extended" for each extension granted by ExtendDeadline
+//   - Debug "closer finished" for each function that succeeded, with its duration
+//   - Info "shutdown in progress" with the functions still running, if WithProgress is given
+//   - Warn "closer slower than usual" for each function running past the "p95" of its past
+//     durations, if WithDurationHistory is given, as an Info event if the Logger has no Warn
+//     method, and Error "duration history not loaded" and "duration history not saved" if
+//     the durations cannot be loaded or saved
+//   - Error "closer failed" for each function that failed, in registration order, with the
+//     "caller" that registered it, unless WithErrorHandler is given, or a single Error
+//     "closers failed" for more than 10 functions failing with the same error, see
+//     WithErrorCollapse
+//   - Warn "optional closer failed" for each optional function that failed, as an Info event
+//     if the Logger has no Warn method, or "optional closers failed" as for required ones
+//   - Info "shutdown finished" with the total duration and the number of failures of required
+//     functions
+//   - Info "shutdown report" with the JSON "report", if WithJSONReport is given without a writer,
+//     and Error "shutdown report not written" if writing it fails
+//   - Error "shutdown hard deadline passed" with the functions still running, before
+//     WithHardDeadline exits the process
+//   - Error "closer registered after shutdown started" for functions dropped by DropLate
+//   - Error "nil closer skipped" for nil functions, if WithSkipNil is given
+//   - Warn "duplicate closer registered" for functions registered twice, if
+//     WithDuplicateWarnings is given, as an Info event if the Logger has no Warn method
+//   - Error "diagnostics not written" when OnDiagnostics fails to write them
+//   - Error "signal callback panicked" when a callback given to OnSignal panics, with the
+//     "signal" and the "error"
+//   - Error "flush failed" when a periodic flush of AddSink fails
+//   - Error "database connections dropped" when AddDB closes a pool still in use
+//   - Error "systemd notification failed" when a notification configured by WithSystemd fails
+//   - Error "termination log not written" when WithTerminationLog fails
+//   - Error "pid file not written" and "pid file not removed" when WithPIDFile fails
+//   - Info "restarting" with the "pid" of the new process, and Error "restart failed" if it
+//     does not become ready
+//   - Debug "child closer finished" and Error "child closer failed" for each function of a
+//     child process started by Supervise, with the "pid" of the child and the "closer"
+//
+// Functions are identified by the "name", "label" and "index" keys; names and labels are
+// only included when set.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// WithLogger makes New create a Closer that emits its events to l. Without it, events go to
+// slog.Default at the time they are emitted.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithSlog makes New create a Closer that emits its events to l. It is equivalent to
+// WithLogger, except that a nil l keeps the default logger.
+func WithSlog(l *slog.Logger) Option {
+	return func(o *options) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// log returns the logger events of c are emitted to.
+func (c *Closer) log() Logger {
+	if l := c.bound.Load(); l != nil {
+		return l
+	}
+	if c.logger != nil {
+		return c.logger
+	}
+	return slog.Default()
+}
+
+// debugEnabled reports whether l emits Debug events, so that they are not built in vain.
+// Loggers without an Enabled method like that of *slog.Logger are assumed to emit them.
+func debugEnabled(l Logger) bool {
+	if e, ok := l.(interface {
+		Enabled(context.Context, slog.Level) bool
+	}); ok {
+		return e.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return true
+}
+
+// attrs returns the key-value pairs identifying e in log events, followed by args.
+func (e entry) attrs(args ...any) []any {
+	a := make([]any, 0, 6+len(args))
+	if e.name != "" {
+		a = append(a, "name", e.name)
+	}
+	if e.label != "" {
+		a = append(a, "label", e.label)
+	}
+	a = append(a, "index", e.index)
+	return append(a, args...)
+}
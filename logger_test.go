@@ -0,0 +1,79 @@
+package closer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordLogger is a Logger that records events as "LEVEL msg key=value ..." lines, leaving out
+// the durations and call sites that vary from run to run.
+type recordLogger struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *recordLogger) Debug(msg string, args ...any) { l.record("DEBUG", msg, args) }
+func (l *recordLogger) Info(msg string, args ...any)  { l.record("INFO", msg, args) }
+func (l *recordLogger) Error(msg string, args ...any) { l.record("ERROR", msg, args) }
+
+func (l *recordLogger) record(level, msg string, args []any) {
+	event := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "duration" || args[i] == "caller" {
+			continue
+		}
+		event += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	l.mu.Lock()
+	l.events = append(l.events, event)
+	l.mu.Unlock()
+}
+
+// TestWithLogger verifies the events emitted to a custom logger during shutdown.
+func TestWithLogger(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithLIFO())
+	c.AddNamed("db", func() error { return errors.New("boom") }, WithLabel("storage"))
+	c.Add(func() error { return nil })
+	c.CloseAll()
+
+	expected := []string{
+		"INFO shutdown started reason=CloseAll call funcs=2",
+		"DEBUG closer finished index=1",
+		"ERROR closer failed name=db label=storage index=0 error=boom",
+		"INFO shutdown finished failures=1",
+	}
+	if got := strings.Join(l.events, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected events\n%s\ngot\n%s", strings.Join(expected, "\n"), got)
+	}
+}
+
+// TestWithSlog verifies that events reach a *slog.Logger with their attributes.
+func TestWithSlog(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	c := New(WithSlog(slog.New(h)))
+	c.AddNamed("cache", func() error { return nil })
+	c.CloseAll()
+
+	out := buf.String()
+	for _, s := range []string{"msg=\"shutdown started\"", "msg=\"closer finished\" name=cache index=0 duration=", "failures=0"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in log, got %q", s, out)
+		}
+	}
+}
+
+// TestWithSlogNil verifies that a nil *slog.Logger keeps the default logger.
+func TestWithSlogNil(t *testing.T) {
+	buf := captureLog(t)
+	New(WithSlog(nil)).CloseAll()
+	if out := buf.String(); !strings.Contains(out, "shutdown finished") {
+		t.Errorf("expected events in the default logger, got %q", out)
+	}
+}
@@ -0,0 +1,196 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"weak"
+)
+
+// ErrMemoryPressure is the error of the Reason of a shutdown initiated because memory ran
+// short, see WithMemoryWatchdog and MemoryPressure.
+var ErrMemoryPressure = errors.New("closer: memory pressure")
+
+// cgroupRoot is where the cgroup file system is mounted, and procCgroup the file listing the
+// cgroups of the process. They are variables so that tests can point them elsewhere.
+var (
+	cgroupRoot = "/sys/fs/cgroup"
+	procCgroup = "/proc/self/cgroup"
+)
+
+// WithMemoryWatchdog makes New create a Closer that checks the memory used by the process
+// every interval and initiates shutdown once it exceeds threshold bytes, so that the
+// application shuts down gracefully instead of being killed by the OOM killer without any
+// cleanup. Memory usage is that of the cgroup of the process, as a container runtime
+// accounts it, when there is one, and otherwise the memory obtained by the Go runtime from
+// the operating system and not returned to it. A threshold of 0 stands for 90% of the memory
+// limit of the cgroup; without a limit, the watchdog then does nothing. The shutdown is
+// reported with ReasonMemory and an error wrapping ErrMemoryPressure, and is preceded by an
+// Error "memory limit exceeded" event with the "usage" and "threshold" in bytes. A
+// non-positive interval stands for one second.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithMemoryWatchdog(0, time.Second))
+func WithMemoryWatchdog(threshold uint64, interval time.Duration) Option {
+	return func(o *options) {
+		o.memory = &memoryWatchdog{threshold: threshold, interval: interval}
+	}
+}
+
+// MemoryPressure initiates shutdown because memory runs short, as WithMemoryWatchdog does,
+// for applications that learn about it otherwise, such as from pressure stall notifications
+// or their orchestrator. It does not wait for the shutdown to complete and does nothing once
+// shutdown has been initiated.
+func (c *Closer) MemoryPressure() {
+	go c.closeAll(context.Background(), Reason{Kind: ReasonMemory, Err: ErrMemoryPressure})
+}
+
+// startWatchdogs starts the watchdogs of WithMemoryWatchdog and WithTriggerFile, if given,
+// which stop once shutdown is initiated.
+func (c *Closer) startWatchdogs() {
+	if c.memory != nil {
+		c.memory.watch(c)
+	}
+	if c.trigger != "" {
+		c.watchTriggerFile(c.trigger)
+	}
+}
+
+// memoryWatchdog is the configuration of WithMemoryWatchdog.
+type memoryWatchdog struct {
+	threshold uint64
+	interval  time.Duration
+}
+
+// watch starts checking the memory usage of the process on behalf of c, until shutdown is
+// initiated or c is garbage collected.
+func (m *memoryWatchdog) watch(c *Closer) {
+	dirs, threshold := memoryCgroups(cgroupRoot, procCgroup), m.threshold
+	if threshold == 0 {
+		_, limit, ok := cgroupMemory(dirs)
+		if !ok || limit == 0 {
+			return
+		}
+		threshold = limit / 10 * 9
+	}
+	ref, done, collected := weak.Make(c), c.Context().Done(), c.collected()
+	interval := m.interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := c.clock.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+			case <-done:
+				return
+			case <-collected:
+				return
+			}
+			usage := memoryUsage(dirs)
+			if usage <= threshold {
+				continue
+			}
+			c := ref.Value()
+			if c == nil {
+				return
+			}
+			c.log().Error("memory limit exceeded", "usage", usage, "threshold", threshold)
+			err := fmt.Errorf("%w: %d bytes used, over %d", ErrMemoryPressure, usage, threshold)
+			go c.closeAll(context.Background(), Reason{Kind: ReasonMemory, Err: err})
+			return
+		}
+	}()
+}
+
+// memoryUsage returns the memory used by the process, as described in WithMemoryWatchdog,
+// with dirs as returned by memoryCgroups.
+func memoryUsage(dirs []cgroupDir) uint64 {
+	if usage, _, ok := cgroupMemory(dirs); ok {
+		return usage
+	}
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
+
+// cgroupDir is a directory of the cgroup file system holding the memory usage and limit of a
+// cgroup in the files named usage and limit.
+type cgroupDir struct {
+	path, usage, limit string
+}
+
+// memoryCgroups returns the directories that may hold the memory usage and limit of the cgroup
+// of the process, with the cgroup file system mounted at root and the cgroups of the process
+// listed in self, in order of preference: those of its cgroup v2 and of its cgroup v1 memory
+// controller, then those at root, which are the ones of the process in a container whose
+// cgroup is mounted at root while self names it from the host.
+func memoryCgroups(root, self string) []cgroupDir {
+	v2, v1 := "/", "/"
+	if data, err := os.ReadFile(self); err == nil {
+		for line := range strings.Lines(string(data)) {
+			// Lines are "hierarchy-ID:controllers:path", with ID 0 and no controller for v2.
+			fields := strings.SplitN(strings.TrimSpace(line), ":", 3)
+			switch {
+			case len(fields) != 3:
+			case fields[0] == "0" && fields[1] == "":
+				v2 = fields[2]
+			case slices.Contains(strings.Split(fields[1], ","), "memory"):
+				v1 = fields[2]
+			}
+		}
+	}
+	dirs := []cgroupDir{
+		{filepath.Join(root, v2), "memory.current", "memory.max"},
+		{filepath.Join(root, "memory", v1), "memory.usage_in_bytes", "memory.limit_in_bytes"},
+	}
+	if v2 != "/" {
+		dirs = append(dirs, cgroupDir{root, "memory.current", "memory.max"})
+	}
+	if v1 != "/" {
+		dirs = append(dirs, cgroupDir{filepath.Join(root, "memory"), "memory.usage_in_bytes", "memory.limit_in_bytes"})
+	}
+	return dirs
+}
+
+// cgroupMemory returns the memory usage and limit of the cgroup of the process, read from the
+// first of dirs holding them, the limit being 0 if there is none. It reports false if none
+// does.
+func cgroupMemory(dirs []cgroupDir) (usage, limit uint64, ok bool) {
+	for _, dir := range dirs {
+		usage, err := readBytes(filepath.Join(dir.path, dir.usage))
+		if err != nil {
+			continue
+		}
+		limit, err := readBytes(filepath.Join(dir.path, dir.limit))
+		if err != nil || limit >= 1<<62 {
+			// cgroup v2 has "max" and cgroup v1 a huge number when there is no limit.
+			limit = 0
+		}
+		return usage, limit, true
+	}
+	return 0, 0, false
+}
+
+// readBytes reads the number of bytes held by a cgroup file.
+func readBytes(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(bytes.TrimSpace(data)), 10, 64)
+}
@@ -0,0 +1,155 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// setCgroup points the cgroup file system to a directory with the given memory files for the
+// duration of the test, none if files is empty. The file "self" stands for /proc/self/cgroup.
+func setCgroup(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, self := cgroupRoot, procCgroup
+	cgroupRoot, procCgroup = dir, filepath.Join(dir, "self")
+	t.Cleanup(func() { cgroupRoot, procCgroup = root, self })
+}
+
+// TestWithMemoryWatchdog verifies that shutdown is initiated once the usage of the cgroup
+// exceeds 90% of its limit.
+func TestWithMemoryWatchdog(t *testing.T) {
+	setCgroup(t, map[string]string{"memory.current": "950\n", "memory.max": "1000\n"})
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithMemoryWatchdog(0, time.Millisecond))
+	if err := c.WaitTimeout(time.Second); err != nil {
+		t.Fatalf("expected shutdown, got %v", err)
+	}
+	r := c.Reason()
+	if r.Kind != ReasonMemory || !errors.Is(r.Err, ErrMemoryPressure) {
+		t.Errorf("expected memory pressure, got %v", r)
+	}
+	if want := "ERROR memory limit exceeded usage=950 threshold=900"; l.events[0] != want {
+		t.Errorf("expected %q, got %q", want, l.events[0])
+	}
+}
+
+// TestMemoryCgroups verifies that the memory of the cgroup of the process is read from the
+// directory named in /proc/self/cgroup, falling back to the root of the file system.
+func TestMemoryCgroups(t *testing.T) {
+	tests := map[string]map[string]string{
+		"v2": {
+			"self":                                 "0::/app.slice/svc.service\n",
+			"memory.current":                       "1",
+			"memory.max":                           "2",
+			"app.slice/svc.service/memory.current": "100",
+			"app.slice/svc.service/memory.max":     "200",
+		},
+		"v1": {
+			"self":                             "5:memory:/svc\n1:cpu,cpuacct:/svc\n0::/\n",
+			"memory/svc/memory.usage_in_bytes": "100",
+			"memory/svc/memory.limit_in_bytes": "200",
+		},
+		"v1 shared hierarchy": {
+			"self":                             "3:cpu,memory:/svc\n",
+			"memory/svc/memory.usage_in_bytes": "100",
+			"memory/svc/memory.limit_in_bytes": "200",
+		},
+		"v2 container": {
+			"self":           "0::/kubepods/pod1/abc\n",
+			"memory.current": "100",
+			"memory.max":     "200",
+		},
+		"v1 container": {
+			"self":                         "4:memory:/docker/abc\n",
+			"memory/memory.usage_in_bytes": "100",
+			"memory/memory.limit_in_bytes": "200",
+		},
+		"no self": {
+			"memory.current": "100",
+			"memory.max":     "200",
+		},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			setCgroup(t, files)
+			usage, limit, ok := cgroupMemory(memoryCgroups(cgroupRoot, procCgroup))
+			if !ok || usage != 100 || limit != 200 {
+				t.Errorf("expected usage 100 and limit 200, got %d, %d, %v", usage, limit, ok)
+			}
+		})
+	}
+}
+
+// TestWithMemoryWatchdogBelow verifies that no shutdown is initiated while the usage is below
+// the threshold, nor without a limit to derive the threshold from.
+func TestWithMemoryWatchdogBelow(t *testing.T) {
+	tests := map[string]map[string]string{
+		"below":    {"memory.current": "850", "memory.max": "1000"},
+		"no limit": {"memory.current": "850", "memory.max": "max"},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			setCgroup(t, files)
+			c := New(WithMemoryWatchdog(0, time.Millisecond))
+			if err := c.WaitTimeout(50 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected no shutdown, got %v", err)
+			}
+			c.CloseAll()
+		})
+	}
+}
+
+// TestWithMemoryWatchdogRuntime verifies that the memory of the Go runtime is watched when
+// there is no cgroup.
+func TestWithMemoryWatchdogRuntime(t *testing.T) {
+	setCgroup(t, nil)
+	c := New(WithMemoryWatchdog(1, time.Millisecond))
+	if err := c.WaitTimeout(time.Second); err != nil {
+		t.Fatalf("expected shutdown, got %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonMemory {
+		t.Errorf("expected memory pressure, got %v", r)
+	}
+}
+
+// TestMemoryPressure verifies that MemoryPressure initiates shutdown.
+func TestMemoryPressure(t *testing.T) {
+	c := New()
+	c.MemoryPressure()
+	c.Wait()
+	if r := c.Reason(); r.Kind != ReasonMemory || r.String() != "memory pressure: closer: memory pressure" {
+		t.Errorf("expected memory pressure, got %v", r)
+	}
+}
+
+// TestWithMemoryWatchdogNoLeak verifies that the watchdog of a Closer that is never closed
+// stops once the Closer is garbage collected.
+func TestWithMemoryWatchdogNoLeak(t *testing.T) {
+	setCgroup(t, map[string]string{"memory.current": "850", "memory.max": "1000"})
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		New(WithMemoryWatchdog(0, time.Millisecond))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,48 @@
+package closer
+
+import "time"
+
+// Metrics receives measurements of a shutdown, for example to export them to Prometheus.
+// They are delivered once all closing functions have finished and before Wait returns, so
+// that they can still be pushed before the process exits.
+//
+// Example:
+//
+//	type promMetrics struct {
+//		shutdown prometheus.Histogram    // closer_shutdown_duration_seconds
+//		funcs    *prometheus.HistogramVec // closer_func_duration_seconds{name}
+//		errors   *prometheus.CounterVec   // closer_func_errors_total{name}
+//	}
+//
+//	func (m promMetrics) ObserveShutdown(_ closer.Reason, d time.Duration, _ int) {
+//		m.shutdown.Observe(d.Seconds())
+//	}
+//
+//	func (m promMetrics) ObserveFunc(f closer.FuncReport) {
+//		m.funcs.WithLabelValues(f.Name).Observe(f.Duration.Seconds())
+//		if f.Err != nil {
+//			m.errors.WithLabelValues(f.Name).Inc()
+//		}
+//	}
+type Metrics interface {
+	// ObserveFunc is called for every registered function in registration order.
+	ObserveFunc(f FuncReport)
+	// ObserveShutdown is called last, with what initiated the shutdown, how long running
+	// the closing functions took and how many of them failed.
+	ObserveShutdown(reason Reason, d time.Duration, failures int)
+}
+
+// WithMetrics makes New create a Closer that reports measurements of its shutdown to m.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// observe reports the outcome of the shutdown to m.
+func observe(m Metrics, r Report, results []result, failures int) {
+	for _, res := range results {
+		m.ObserveFunc(res.report())
+	}
+	m.ObserveShutdown(r.Reason, r.Duration, failures)
+}
@@ -0,0 +1,38 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordMetrics is a Metrics that records the measurements it receives.
+type recordMetrics struct {
+	funcs    []FuncReport
+	reason   Reason
+	d        time.Duration
+	failures int
+}
+
+func (m *recordMetrics) ObserveFunc(f FuncReport) { m.funcs = append(m.funcs, f) }
+
+func (m *recordMetrics) ObserveShutdown(reason Reason, d time.Duration, failures int) {
+	m.reason, m.d, m.failures = reason, d, failures
+}
+
+// TestWithMetrics verifies that measurements are delivered before Wait returns.
+func TestWithMetrics(t *testing.T) {
+	m := &recordMetrics{}
+	c := New(WithMetrics(m))
+	c.AddNamed("cache", func() error { return nil })
+	c.AddNamed("db", func() error { return errors.New("boom") })
+	c.CloseAll()
+	c.Wait()
+
+	if len(m.funcs) != 2 || m.funcs[0].Name != "cache" || m.funcs[1].Name != "db" || m.funcs[1].Err == nil {
+		t.Errorf("expected cache and failed db, got %+v", m.funcs)
+	}
+	if m.reason.Kind != ReasonCall || m.d <= 0 || m.failures != 1 {
+		t.Errorf("expected a called shutdown with 1 failure, got %v %v %d", m.reason, m.d, m.failures)
+	}
+}
@@ -0,0 +1,74 @@
+package closer
+
+// ErrorHandler receives the failure of a closing function: its name, or its identifier such
+// as "db #3" if it has none, and the error it was reported with.
+type ErrorHandler func(name string, err error)
+
+// WithErrorHandler makes New create a Closer that calls h with the failure of each closing
+// function as soon as it is recorded, for example to report it to an error tracker, instead
+// of emitting "closer failed" Error events to its Logger. Failures of optional functions are
+// still logged as warnings and not passed to h. h may be called concurrently from several
+// goroutines and should return quickly, as the shutdown does not complete before it returns.
+//
+// Example:
+//
+//	c := closer.New(closer.WithErrorHandler(func(name string, err error) {
+//		sentry.CaptureException(fmt.Errorf("closing %s: %w", name, err))
+//	}))
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = h
+	}
+}
+
+// defaultCollapse is the number of functions failing with the same error past which their
+// failures are logged as a single event, unless WithErrorCollapse says otherwise.
+const defaultCollapse = 10
+
+// WithErrorCollapse makes New create a Closer that logs the failures of more than n closing
+// functions with the same error message as a single Error "closers failed" event, or Warn
+// "optional closers failed" for optional functions, with their "count", the "error" and
+// "caller" of the first of them, and the "first" function itself, instead of one event each.
+// It keeps the logs of a shutdown closing thousands of connections the same way readable;
+// the Report and the error returned by Wait still list every failure. The default is 10;
+// n <= 0 logs every failure on its own.
+//
+// Example:
+//
+//	c := closer.New(closer.WithErrorCollapse(100))
+func WithErrorCollapse(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = -1
+		}
+		o.collapse = n
+	}
+}
+
+// logFailures logs failures, in registration order, with log: each one as a single event
+// named single, except that the failures of more than collapse functions with the same error
+// message are logged as one event named collapsed, where the first of them would be. A
+// negative collapse logs every failure on its own.
+func logFailures(log func(msg string, args ...any), single, collapsed string, failures []result, collapse int) {
+	var counts map[string]int
+	if collapse >= 0 && len(failures) > collapse {
+		counts = make(map[string]int)
+		for _, f := range failures {
+			counts[f.err.Error()]++
+		}
+	}
+	for _, f := range failures {
+		if counts != nil {
+			msg := f.err.Error()
+			if n := counts[msg]; n < 0 {
+				// Summarized already.
+				continue
+			} else if n > collapse {
+				log(collapsed, "count", n, "error", f.err, "caller", f.entry.caller, "first", f.entry.String())
+				counts[msg] = -1
+				continue
+			}
+		}
+		log(single, f.entry.attrs("duration", f.duration, "error", f.err, "caller", f.entry.caller)...)
+	}
+}
@@ -0,0 +1,97 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestWithErrorHandler verifies that failures go to the handler instead of the logger.
+func TestWithErrorHandler(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithErrorHandler(func(name string, err error) {
+		mu.Lock()
+		got = append(got, name+": "+err.Error())
+		mu.Unlock()
+	}))
+	c.AddNamed("db", func() error { return errors.New("busy") })
+	c.Add(func() error { return errors.New("boom") })
+	c.Add(func() error { return nil })
+	c.AddOptional(func() error { return errors.New("ignored") })
+
+	if err := c.CloseAll(); err == nil {
+		t.Error("expected the failures to be returned")
+	}
+	slices.Sort(got)
+	expected := []string{"#1: boom", "db: busy"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	for _, event := range l.events {
+		if strings.HasPrefix(event, "ERROR closer failed") {
+			t.Errorf("expected no closer failed event, got %q", event)
+		}
+	}
+}
+
+// TestErrorCollapse verifies that more than 10 functions failing with the same error are
+// logged as a single event, in place of the first of them, while other failures are logged
+// one by one and Wait still reports all of them.
+func TestErrorCollapse(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithSequential())
+	c.AddNamed("db", func() error { return errors.New("broken pipe") })
+	closed := errors.New("use of closed network connection")
+	for range 11 {
+		c.AddNamed("conn", func() error { return closed })
+	}
+	c.AddNamed("cache", func() error { return errors.New("timeout") })
+
+	err := c.CloseAll()
+	var se *ShutdownError
+	if !errors.As(err, &se) || len(se.errs) != 13 {
+		t.Fatalf("expected 13 failures, got %v", err)
+	}
+	var failed []string
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "ERROR closer") {
+			failed = append(failed, e)
+		}
+	}
+	want := []string{
+		"ERROR closer failed name=db index=0 error=broken pipe",
+		"ERROR closers failed count=11 error=use of closed network connection first=conn #1",
+		"ERROR closer failed name=cache index=12 error=timeout",
+	}
+	if !slices.Equal(failed, want) {
+		t.Errorf("expected %q, got %q", want, failed)
+	}
+}
+
+// TestWithErrorCollapse verifies that the threshold can be changed or collapsing disabled.
+func TestWithErrorCollapse(t *testing.T) {
+	for _, test := range []struct {
+		n    int
+		want int
+	}{{2, 1}, {3, 3}, {0, 3}} {
+		l := &recordLogger{}
+		c := New(WithLogger(l), WithErrorCollapse(test.n))
+		for range 3 {
+			c.Add(func() error { return errors.New("closed") })
+		}
+		c.CloseAll()
+		got := 0
+		for _, e := range l.events {
+			if strings.HasPrefix(e, "ERROR closer") {
+				got++
+			}
+		}
+		if got != test.want {
+			t.Errorf("WithErrorCollapse(%d): expected %d events, got %d: %v", test.n, test.want, got, l.events)
+		}
+	}
+}
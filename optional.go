@@ -0,0 +1,31 @@
+package closer
+
+// Optional marks closing functions whose failure does not fail the shutdown, such as
+// removing a cache directory. Their failures are logged as warnings and reported in the
+// Report, but they are not part of the error returned by CloseAll and Wait, do not count
+// towards ExitCode and do not trigger WithFailFast.
+//
+// Example:
+//
+//	c.AddNamed("cache", cache.Purge, closer.Optional())
+func Optional() Option {
+	return func(o *options) {
+		o.optional = true
+	}
+}
+
+// AddOptional registers one or more closing functions whose failure does not fail the
+// shutdown, as described in Optional.
+func (c *Closer) AddOptional(f ...closeFunc) {
+	c.add([]Option{Optional()}, f...)
+}
+
+// warn emits a warning to l, as an Info event if l has no Warn method like that of
+// *slog.Logger.
+func warn(l Logger, msg string, args ...any) {
+	if w, ok := l.(interface{ Warn(msg string, args ...any) }); ok {
+		w.Warn(msg, args...)
+		return
+	}
+	l.Info(msg, args...)
+}
@@ -0,0 +1,43 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// warnLogger is a recordLogger with a Warn method.
+type warnLogger struct {
+	recordLogger
+}
+
+func (l *warnLogger) Warn(msg string, args ...any) { l.record("WARN", msg, args) }
+
+// TestAddOptional verifies that optional failures are reported and logged as warnings
+// without failing the shutdown or aborting a fail-fast one.
+func TestAddOptional(t *testing.T) {
+	l := &warnLogger{}
+	c := New(WithLogger(l), WithFailFast(), WithSequential())
+	c.AddOptional(func() error { return errors.New("cache busy") })
+	ran := false
+	c.Add(func() error {
+		ran = true
+		return nil
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !ran {
+		t.Error("expected the next function to run")
+	}
+	if code := c.ExitCode(); code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+	if !slices.Contains(l.events, "WARN optional closer failed index=0 error=cache busy") {
+		t.Errorf("expected a warning, got %v", l.events)
+	}
+	if f := c.Report().Funcs[0]; !f.Optional || f.Err == nil {
+		t.Errorf("expected an optional failure in the report, got %+v", f)
+	}
+}
@@ -0,0 +1,328 @@
+package closer
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Option configures a Closer or how closing functions are registered and executed.
+// Options can be passed to New, to a single registration such as AddNamed, or to Group
+// to apply them to every function registered through the group. Options that do not
+// apply where they are passed are ignored.
+type Option func(*options)
+
+// options holds the settings collected from a list of Option values.
+type options struct {
+	signals    []os.Signal
+	name       string
+	label      string
+	timeout    time.Duration
+	flusher    bool
+	serial     string
+	prio       int
+	phase      string
+	after      []string
+	lifo       bool
+	sequential bool
+	ordered    bool
+	failFast   bool
+	phases     map[string]int
+	budgets    map[string]time.Duration
+
+	forceExit        bool
+	exitCode         int
+	stackDump        io.Writer
+	logger           Logger
+	metrics          Metrics
+	tracer           Tracer
+	limit            int
+	late             LatePolicy
+	reloadSignal     os.Signal
+	drainDelay       time.Duration
+	ignored          []error
+	retry            *retryPolicy
+	progress         time.Duration
+	deadlineDump     io.Writer
+	notifier         Notifier
+	interceptors     []Interceptor
+	systemd          bool
+	systemdExtend    time.Duration
+	restartSignal    os.Signal
+	restartTimeout   time.Duration
+	pidFile          string
+	exitCodes        exitCodes
+	critical         time.Duration
+	grace            time.Duration
+	optional         bool
+	quiescer         bool
+	hardDeadline     *hardDeadline
+	panics           PanicPolicy
+	cancelGrace      time.Duration
+	logPlan          bool
+	expvar           string
+	onError          ErrorHandler
+	skipNil          bool
+	duplicates       bool
+	jsonReport       *jsonReport
+	terminationLog   string
+	history          DurationStore
+	rehearsal        contextFunc
+	extension        time.Duration
+	stateFile        string
+	interruptMessage *interruptMessage
+	tags             []string
+	collapse         int
+	memory           *memoryWatchdog
+	barriers         []barrier
+	values           []contextValue
+	required         []string
+	warnEmpty        bool
+	triggerFile      string
+	supervised       bool
+	reportHistory    int
+	clock            Clock
+}
+
+// newOptions applies opts in order, so later options override earlier ones.
+func newOptions(opts []Option) options {
+	if len(opts) == 0 {
+		// Spare registrations without options the allocation of applyOptions.
+		return options{}
+	}
+	return applyOptions(opts)
+}
+
+// applyOptions is newOptions for at least one option.
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// entry builds a registration entry for fn from the collected options.
+func (o options) entry(fn contextFunc) entry {
+	e := entry{
+		name:     o.name,
+		label:    o.label,
+		timeout:  o.timeout,
+		grace:    o.cancelGrace,
+		flusher:  o.flusher,
+		optional: o.optional,
+		quiescer: o.quiescer,
+		panics:   o.panics,
+		serial:   o.serial,
+		prio:     o.prio,
+		after:    o.after,
+		fn:       fn,
+		rehearse: o.rehearsal,
+	}
+	if o.critical > 0 {
+		e.critical = true
+		if e.timeout <= 0 || e.timeout > o.critical {
+			e.timeout = o.critical
+		}
+	}
+	return e
+}
+
+// WithSignals makes New watch the given OS signals and trigger CloseAll when any of them
+// is received. Once shutdown starts, the signals are released and get their default
+// behavior back, so that sending one again terminates a process whose shutdown is stuck,
+// unless WithForceExit is given to handle them instead.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = append(o.signals, sigs...)
+	}
+}
+
+// WithLabel attaches a label to closing functions, typically the name of the subsystem
+// they belong to. The label is included in log messages next to the function name.
+func WithLabel(label string) Option {
+	return func(o *options) {
+		o.label = label
+	}
+}
+
+// WithTimeout limits how long closing functions may run. A zero or negative duration
+// disables the limit.
+//
+// Passed to New, it bounds the entire shutdown as described in CloseAllContext.
+// Passed to Group or a registration, it bounds each function individually: a function that
+// does not finish in time is abandoned and reported with an error wrapping ErrTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithPriority sets the priority of closing functions. During CloseAll, all functions of
+// the highest priority run concurrently first, then those of the next priority, and so on.
+// Functions without a priority have priority 0; negative priorities run after them. It
+// overrides an earlier InPhase.
+func WithPriority(prio int) Option {
+	return func(o *options) {
+		o.prio, o.phase = prio, ""
+	}
+}
+
+// WithPhase makes New declare a named shutdown phase made of the functions with the given
+// priority. Functions are assigned to the phase with InPhase, and the phase name appears in
+// the Plan. Like priorities, phases run one after another, highest priority first, while
+// the functions within a phase run concurrently. Several names may share a priority.
+//
+// Example:
+//
+//	c := closer.New(
+//		closer.WithPhase("drain", 100),
+//		closer.WithPhase("stop", 0),
+//		closer.WithPhase("flush", -100),
+//	)
+//	c.Group(closer.InPhase("drain")).Add(consumer.Drain)
+func WithPhase(name string, prio int) Option {
+	return func(o *options) {
+		if o.phases == nil {
+			o.phases = make(map[string]int)
+		}
+		o.phases[name] = prio
+	}
+}
+
+// InPhase assigns closing functions to a phase declared with WithPhase, giving them the
+// priority of that phase and overriding an earlier WithPriority. Registering a function in an
+// undeclared phase panics.
+func InPhase(name string) Option {
+	return func(o *options) {
+		o.phase = name
+	}
+}
+
+// After makes closing functions run only once the functions with the given names or labels
+// have finished, while functions without dependencies between them still run concurrently.
+// Dependencies apply among functions of the same priority; functions of higher priorities
+// run earlier anyway, and dependencies on names that are not registered are ignored.
+// Functions that depend on each other in a cycle are run together after all others, and
+// each of them is reported with an error wrapping ErrDependencyCycle.
+//
+// Example:
+//
+//	c.AddNamed("http", srv.Close)
+//	c.AddNamed("db", db.Close, closer.After("http"))
+func After(names ...string) Option {
+	return func(o *options) {
+		o.after = append(o.after, names...)
+	}
+}
+
+// WithForceExit makes New create a Closer that terminates the process with code when one of
+// the signals given to WithSignals is received while shutdown is already in progress, for
+// example when Ctrl-C is pressed twice because a closing function hangs. Without it, the
+// shutdown signals are released once shutdown starts, so a second one gets its default
+// behavior.
+func WithForceExit(code int) Option {
+	return func(o *options) {
+		o.forceExit = true
+		o.exitCode = code
+	}
+}
+
+// WithStackDump makes New create a Closer that writes the stack traces of all goroutines to w
+// before forcing the process to exit, on a second signal as configured by WithForceExit or at
+// the deadline set by WithHardDeadline, to show what the shutdown was stuck on.
+func WithStackDump(w io.Writer) Option {
+	return func(o *options) {
+		o.stackDump = w
+	}
+}
+
+// WithDeadlineDump makes New create a Closer that writes the stack traces of all goroutines
+// to w when the shutdown deadline passes before all closing functions have finished, to show
+// what each of them is blocked on. The dump is written before CloseAll returns, so before the
+// process exits if it exits right after, such as with CloseAllAndExit.
+//
+// Example:
+//
+//	c := closer.New(closer.WithTimeout(30*time.Second), closer.WithDeadlineDump(os.Stderr))
+func WithDeadlineDump(w io.Writer) Option {
+	return func(o *options) {
+		o.deadlineDump = w
+	}
+}
+
+// WithLIFO makes New create a Closer that runs closing functions one at a time in reverse
+// registration order, like deferred calls, so that resources are closed in the reverse
+// order of their creation. Functions with different priorities still run highest priority
+// first, each priority in reverse registration order.
+func WithLIFO() Option {
+	return func(o *options) {
+		o.lifo = true
+	}
+}
+
+// WithSequential makes New create a Closer that runs closing functions one at a time in
+// registration order, for teardown steps that must not overlap. Functions with different
+// priorities still run highest priority first. See WithLIFO for the reverse order.
+func WithSequential() Option {
+	return func(o *options) {
+		o.sequential = true
+	}
+}
+
+// WithRegistrationOrder makes New create a Closer that still runs the closing functions of a
+// step concurrently, but starts them one after another in registration order: each function
+// starts once the previous one has started, without waiting for it to finish. A function that
+// shares a serialization key with earlier ones, as with AddSerialized, starts once they have
+// finished instead, possibly after functions registered later. Shutdowns then behave the same
+// from one run to the next, whereas goroutine scheduling otherwise starts functions in any
+// order.
+//
+// Regardless of this option, the Report and the errors of a shutdown list functions in
+// registration order; only Subscribe and Errors deliver them as they occur.
+func WithRegistrationOrder() Option {
+	return func(o *options) {
+		o.ordered = true
+	}
+}
+
+// WithFailFast makes New create a Closer that stops running closing functions after the
+// first one fails, for teardowns where later steps are pointless or dangerous after an
+// earlier one failed. Functions not run are reported with ErrSkipped. With WithSequential or
+// WithLIFO, the shutdown stops right after the failed function; otherwise, functions running
+// concurrently with it still complete and the following steps are skipped.
+func WithFailFast() Option {
+	return func(o *options) {
+		o.failFast = true
+	}
+}
+
+// WithMaxConcurrency makes New create a Closer that runs at most n closing functions at the
+// same time, on a pool of n goroutines, instead of 1024. A lower limit avoids a burst of
+// memory and file descriptor usage at shutdown when thousands of functions are registered.
+// Functions of the same priority are started in registration order as goroutines of the pool
+// become free. A zero or negative n keeps the default.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) {
+		o.limit = n
+	}
+}
+
+// WithDrainDelay makes New create a Closer that waits for d between the initiation of
+// shutdown and the first closing function, while the application keeps serving. Behind a
+// load balancer or in Kubernetes, this gives the infrastructure time to stop routing new
+// traffic to the process before servers stop accepting it. Done is closed and start hooks
+// run at the beginning of the delay, so that readiness checks fail during it. The delay
+// counts towards the timeout given to New and ends early when the shutdown deadline passes.
+func WithDrainDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.drainDelay = d
+	}
+}
+
+// withName sets the name of a single closing function.
+func withName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
@@ -0,0 +1,84 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is reported for a closing function that panicked. The panic is recovered so
+// that the remaining functions still run, unless WithPanicPolicy says otherwise.
+type PanicError struct {
+	Value any    // value passed to panic
+	Stack []byte // stack trace of the goroutine that panicked
+}
+
+// Error returns the panic value followed by the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("closer: panic: %v\n%s", e.Value, e.Stack)
+}
+
+// call invokes fn with ctx, converting a panic into a *PanicError.
+func call(fn contextFunc, ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// PanicPolicy determines what a shutdown does when a closing function panics.
+type PanicPolicy int
+
+const (
+	// PanicContinue reports the panic as a *PanicError and runs the remaining functions. It is
+	// the default.
+	PanicContinue PanicPolicy = iota + 1
+	// PanicAbort reports the panic as a *PanicError and skips the remaining functions as
+	// WithFailFast does, reporting them with ErrSkipped. Critical functions still run.
+	PanicAbort
+	// PanicRepanic reports the panic like PanicContinue, then panics again with the
+	// *PanicError, holding the stack of the original panic, once the shutdown has completed
+	// and its report has been logged and passed to the end hooks. The panic occurs in the
+	// goroutine that ran the shutdown, such as the caller of CloseAll, and usually crashes
+	// the process.
+	PanicRepanic
+)
+
+// WithPanicPolicy sets what the shutdown does when closing functions panic. Passed to New, it
+// applies to every function registered without a policy of its own; passed to Group or a
+// registration, it applies to those functions. The policy applies to CloseAll only: Flush and
+// Quiesce report panics as errors.
+//
+// Example:
+//
+//	c := closer.New(closer.WithPanicPolicy(closer.PanicRepanic))
+func WithPanicPolicy(p PanicPolicy) Option {
+	return func(o *options) {
+		o.panics = p
+	}
+}
+
+// panicked returns the *PanicError err holds, if any.
+func panicked(err error) *PanicError {
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	return nil
+}
+
+// repanic returns the panic of the first function of results that panicked under
+// PanicRepanic, nil if there is none.
+func repanic(results []result) *PanicError {
+	for _, r := range results {
+		if r.entry.panics == PanicRepanic && r.err != nil {
+			if pe := panicked(r.err); pe != nil {
+				return pe
+			}
+		}
+	}
+	return nil
+}
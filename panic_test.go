@@ -0,0 +1,82 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPanicRecovery verifies that a panicking function is reported with its stack trace
+// while the other functions still run, with and without a timeout.
+func TestPanicRecovery(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Second} {
+		c := New()
+		var executed int32
+		c.AddNamed("broken", func() error {
+			panic("nil map")
+		}, WithTimeout(timeout))
+		c.Add(func() error {
+			atomic.AddInt32(&executed, 1)
+			return nil
+		})
+
+		err := c.CloseAll()
+
+		var perr *PanicError
+		if !errors.As(err, &perr) {
+			t.Fatalf("expected *PanicError, got %v", err)
+		}
+		if perr.Value != "nil map" {
+			t.Errorf("expected panic value %q, got %v", "nil map", perr.Value)
+		}
+		if !strings.Contains(string(perr.Stack), "TestPanicRecovery") {
+			t.Errorf("expected stack trace to include the panicking function, got %s", perr.Stack)
+		}
+		if n := atomic.LoadInt32(&executed); n != 1 {
+			t.Errorf("expected other function executed once, got %d", n)
+		}
+	}
+}
+
+// TestPanicAbort verifies that a panic under PanicAbort skips the remaining functions, while
+// other functions keep the default policy.
+func TestPanicAbort(t *testing.T) {
+	c := New(WithSequential())
+	c.AddNamed("tolerated", func() error { panic("first") })
+	c.AddNamed("fatal", func() error { panic("second") }, WithPanicPolicy(PanicAbort))
+	var ran atomic.Bool
+	c.AddNamed("db", func() error {
+		ran.Store(true)
+		return nil
+	})
+
+	err := c.CloseAll()
+	if !errors.Is(err, ErrSkipped) || ran.Load() {
+		t.Errorf("expected db to be skipped, got %v", err)
+	}
+	if r := c.Report(); !r.Funcs[0].Panicked || !r.Funcs[1].Panicked {
+		t.Errorf("expected both panics to be reported, got %+v", r.Funcs)
+	}
+}
+
+// TestPanicRepanic verifies that the panic is raised again once the shutdown has completed.
+func TestPanicRepanic(t *testing.T) {
+	c := New(WithPanicPolicy(PanicRepanic))
+	c.AddNamed("bad", func() error { panic("boom") })
+	var reported atomic.Bool
+	c.OnShutdownEnd(func(Report) { reported.Store(true) })
+
+	defer func() {
+		pe, ok := recover().(*PanicError)
+		if !ok || pe.Value != "boom" {
+			t.Errorf("expected a *PanicError with boom, got %v", pe)
+		}
+		if !reported.Load() || !c.IsClosed() {
+			t.Error("expected the panic after the shutdown completed")
+		}
+	}()
+	c.CloseAll()
+	t.Error("expected CloseAll to panic")
+}
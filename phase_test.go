@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestPhases verifies that named phases run in the order of their priorities.
+func TestPhases(t *testing.T) {
+	c := New(WithPhase("drain", 100), WithPhase("stop", 0), WithPhase("flush", -100))
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) closeFunc {
+		return func() error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	c.Group(InPhase("flush")).Add(record("flush"))
+	c.Add(record("stop"))
+	c.Group(InPhase("drain")).Add(record("drain"))
+
+	c.CloseAll()
+
+	if expected := []string{"drain", "stop", "flush"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+// TestPlanPhases compares the plan of a phased configuration against a golden structure.
+func TestPlanPhases(t *testing.T) {
+	c := New(WithPhase("drain", 100), WithPhase("flush", -100))
+	noop := func() error { return nil }
+	c.AddNamed("metrics", noop, InPhase("flush"))
+	c.AddNamed("db", noop)
+	c.AddNamed("consumer", noop, InPhase("drain"))
+
+	want := []PlanStep{
+		{Index: 0, Phase: "drain", Concurrent: true, Funcs: []PlanFunc{{Index: 2, Name: "consumer", Prio: 100}}},
+		{Index: 1, Phase: "", Concurrent: true, Funcs: []PlanFunc{{Index: 1, Name: "db"}}},
+		{Index: 2, Phase: "flush", Concurrent: true, Funcs: []PlanFunc{{Index: 0, Name: "metrics", Prio: -100}}},
+	}
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected plan %+v, got %+v", want, got)
+	}
+}
+
+// TestUnknownPhase verifies that registering into an undeclared phase panics.
+func TestUnknownPhase(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New().AddNamed("db", func() error { return nil }, InPhase("drain"))
+}
@@ -0,0 +1,40 @@
+package closer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// WithPIDFile makes New create a Closer that writes the process ID to the file at path, and
+// removes the file as the very last step of shutdown, after every closing function and hook,
+// whether or not they failed. Failing to write or remove the file is logged as an Error event
+// without affecting the shutdown.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithPIDFile("/run/app.pid"))
+func WithPIDFile(path string) Option {
+	return func(o *options) {
+		o.pidFile = path
+	}
+}
+
+// writePIDFile writes the process ID to the PID file of c.
+func (c *Closer) writePIDFile() {
+	pid := strconv.Itoa(os.Getpid()) + "\n"
+	if err := os.WriteFile(c.pidFile, []byte(pid), 0o644); err != nil {
+		c.log().Error("pid file not written", "path", c.pidFile, "error", err)
+	}
+}
+
+// removePIDFile removes the PID file of c, if any.
+func (c *Closer) removePIDFile() {
+	if c.pidFile == "" {
+		return
+	}
+	if err := os.Remove(c.pidFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.log().Error("pid file not removed", "path", c.pidFile, "error", err)
+	}
+}
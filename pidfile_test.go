@@ -0,0 +1,38 @@
+package closer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestWithPIDFile verifies that the PID file is written by New and removed after the end
+// hooks, even when a closing function fails.
+func TestWithPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.pid")
+	c := New(WithPIDFile(path))
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strconv.Itoa(os.Getpid()) + "\n"; string(b) != want {
+		t.Errorf("expected %q, got %q", want, b)
+	}
+	c.Add(func() error { return errors.New("boom") })
+	var existed bool
+	c.OnShutdownEnd(func(Report) {
+		_, err := os.Stat(path)
+		existed = err == nil
+	})
+
+	c.CloseAll()
+	if !existed {
+		t.Error("expected the PID file to exist while end hooks run")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the PID file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package closer
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlanStep is a stage of the shutdown sequence. Steps run one after another.
+type PlanStep struct {
+	Index      int        // position of the step in the sequence
+	Phase      string     // names of the phases declared with the priority of the step, if any
+	Concurrent bool       // whether the functions of the step run concurrently
+	Critical   bool       // whether the step runs critical functions, regardless of the deadline
+	Quiesce    bool       // whether the step runs quiescers, possibly early through Quiesce
+	Funcs      []PlanFunc // functions executed by the step, in execution order
+}
+
+// PlanFunc describes a closing function executed by a PlanStep.
+type PlanFunc struct {
+	Index    int           // registration index of the function
+	Name     string        // name given at registration, if any
+	Label    string        // label given at registration, if any
+	Timeout  time.Duration // timeout of the function, zero if unlimited
+	Attempts int           // attempts allowed by WithRetry, zero if the function is not retried
+	Backoff  time.Duration // delay before the first retry under WithRetry, doubled each time
+	Flusher  bool          // whether the function is a flusher
+	Serial   string        // key serializing the function with others in the step, if any
+	Prio     int           // priority of the function
+	After    []string      // names or labels of the functions this one runs after, if any
+}
+
+// step is a stage of the shutdown sequence executed by CloseAll.
+type step struct {
+	sequential bool // run funcs one at a time in order instead of concurrently
+	ordered    bool // start concurrent funcs one after another in order, see WithRegistrationOrder
+	cycle      bool // funcs depend on each other in a cycle and are run regardless
+	critical   bool // funcs are critical and run regardless of the deadline
+	quiesce    bool // funcs are quiescers, run by Quiesce if it was called
+	funcs      []entry
+}
+
+// Plan returns the sequence CloseAll would execute, without running anything or modifying
+// the Closer. Once shutdown has started, Plan returns the sequence that was actually
+// executed. Plan is safe to call any number of times.
+func (c *Closer) Plan() []PlanStep {
+	c.mu.Lock()
+	steps := c.steps
+	if !c.started {
+		steps = c.plan(c.funcs.snapshot(false))
+	}
+	c.mu.Unlock()
+
+	plan := make([]PlanStep, len(steps))
+	for i, s := range steps {
+		plan[i] = PlanStep{
+			Index:      i,
+			Phase:      c.phaseName(s.funcs[0].prio),
+			Concurrent: !s.sequential,
+			Critical:   s.critical,
+			Quiesce:    s.quiesce,
+			Funcs:      make([]PlanFunc, len(s.funcs)),
+		}
+		for j, e := range s.funcs {
+			plan[i].Funcs[j] = e.describe()
+		}
+	}
+	return plan
+}
+
+// plan arranges funcs into the steps executed by CloseAll. Functions are grouped by priority,
+// from the highest priority to the lowest, and each priority is split into one step per
+// level of After dependencies among its functions. Steps are concurrent, unless the Closer
+// runs in sequential mode, where each step runs its functions one at a time in registration
+// order, or in LIFO mode, where it does so in reverse registration order. Quiescers are
+// arranged the same way into steps of their own, before all others, and critical functions
+// after all others. It does not modify funcs.
+func (c *Closer) plan(funcs []entry) []step {
+	var steps []step
+	if others, quiescers := split(funcs, func(e *entry) bool { return e.quiescer }); quiescers != nil {
+		for _, s := range c.arrange(quiescers) {
+			s.quiesce = true
+			steps = append(steps, s)
+		}
+		funcs = others
+	}
+	ordinary, critical := split(funcs, func(e *entry) bool { return e.critical })
+	if critical == nil {
+		if steps == nil {
+			return c.arrange(funcs)
+		}
+		return append(steps, c.arrange(funcs)...)
+	}
+	steps = append(steps, c.arrange(ordinary)...)
+	for _, s := range c.arrange(critical) {
+		s.critical = true
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+// arrange arranges funcs into steps as described in plan, regardless of critical functions.
+func (c *Closer) arrange(funcs []entry) []step {
+	if len(funcs) == 0 {
+		return nil
+	}
+
+	sorted := make([]entry, len(funcs))
+	copy(sorted, funcs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].prio > sorted[j].prio
+	})
+
+	var steps []step
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && sorted[end].prio == sorted[start].prio {
+			end++
+		}
+		steps = append(steps, layers(sorted[start:end])...)
+		start = end
+	}
+
+	for i := range steps {
+		steps[i].ordered = c.ordered
+		if c.lifo || c.sequential {
+			steps[i].sequential = true
+			if c.lifo {
+				slices.Reverse(steps[i].funcs)
+			}
+		}
+	}
+	return steps
+}
+
+// layers splits funcs sharing a priority into steps that satisfy their After dependencies:
+// every function runs in a step after the ones of all functions it depends on, and as early
+// as possible otherwise. Dependencies on functions outside funcs are ignored. Functions that
+// depend on each other in a cycle, directly or not, are put into a final step marked as such.
+func layers(funcs []entry) []step {
+	// deps[i] holds the positions in funcs of the functions funcs[i] depends on.
+	deps := make([][]int, len(funcs))
+	hasDeps := false
+	for i, e := range funcs {
+		for _, name := range e.after {
+			for j, other := range funcs {
+				if j != i && (other.name == name || other.label == name) {
+					deps[i] = append(deps[i], j)
+					hasDeps = true
+				}
+			}
+		}
+	}
+	if !hasDeps {
+		return []step{{funcs: funcs}}
+	}
+
+	var steps []step
+	done := make([]bool, len(funcs))
+	for remaining := len(funcs); remaining > 0; {
+		var ready []int
+		for i := range funcs {
+			if !done[i] && allDone(deps[i], done) {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			var cycle []entry
+			for i, e := range funcs {
+				if !done[i] {
+					cycle = append(cycle, e)
+				}
+			}
+			return append(steps, step{cycle: true, funcs: cycle})
+		}
+
+		layer := make([]entry, len(ready))
+		for k, i := range ready {
+			layer[k] = funcs[i]
+			done[i] = true
+		}
+		steps = append(steps, step{funcs: layer})
+		remaining -= len(ready)
+	}
+	return steps
+}
+
+// allDone reports whether done is set for all positions.
+func allDone(positions []int, done []bool) bool {
+	for _, pos := range positions {
+		if !done[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+// phaseName returns the names of the phases declared with prio, sorted and separated by
+// commas, or an empty string if there are none.
+func (c *Closer) phaseName(prio int) string {
+	var names []string
+	for name, p := range c.phases {
+		if p == prio {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// describe returns the public description of the entry.
+func (e entry) describe() PlanFunc {
+	f := PlanFunc{
+		Index:   e.index,
+		Name:    e.name,
+		Label:   e.label,
+		Timeout: e.timeout,
+		Flusher: e.flusher,
+		Serial:  e.serial,
+		Prio:    e.prio,
+		After:   e.after,
+	}
+	if e.retry != nil {
+		f.Attempts, f.Backoff = e.retry.attempts, e.retry.backoff
+	}
+	return f
+}
+
+// WithPlanLogging makes New create a Closer that logs the sequence it executes when shutdown
+// starts, with an Info "shutdown step" event per step of the Plan listing its functions, so
+// that the teardown order can be checked in the logs of any shutdown.
+func WithPlanLogging() Option {
+	return func(o *options) {
+		o.logPlan = true
+	}
+}
+
+// logPlan logs steps to l as described in WithPlanLogging.
+func (c *Closer) logPlan(l Logger, steps []step) {
+	for i, s := range steps {
+		funcs := make([]string, len(s.funcs))
+		for j, e := range s.funcs {
+			funcs[j] = e.String()
+		}
+		args := []any{"step", i, "concurrent", !s.sequential, "funcs", funcs}
+		if phase := c.phaseName(s.funcs[0].prio); phase != "" {
+			args = append(args, "phase", phase)
+		}
+		switch {
+		case s.quiesce:
+			args = append(args, "quiesce", true)
+		case s.critical:
+			args = append(args, "critical", true)
+		case s.cycle:
+			args = append(args, "cycle", true)
+		}
+		l.Info("shutdown step", args...)
+	}
+}
@@ -0,0 +1,99 @@
+package closer
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPlan compares the plan of a concurrent configuration against a golden structure,
+// before and after shutdown.
+func TestPlan(t *testing.T) {
+	c := New()
+	noop := func() error { return nil }
+	c.AddNamed("http", noop, WithTimeout(time.Second))
+	c.Group(WithLabel("kafka")).AddNamed("consumer", noop)
+	c.AddSerialized("cgo", noop)
+	c.AddFlusher(noop)
+	c.AddNamed("upload", noop, WithRetry(3, time.Second))
+
+	want := []PlanStep{{
+		Index:      0,
+		Concurrent: true,
+		Funcs: []PlanFunc{
+			{Index: 0, Name: "http", Timeout: time.Second},
+			{Index: 1, Name: "consumer", Label: "kafka"},
+			{Index: 2, Serial: "cgo"},
+			{Index: 3, Flusher: true},
+			{Index: 4, Name: "upload", Attempts: 3, Backoff: time.Second},
+		},
+	}}
+
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected plan %+v, got %+v", want, got)
+	}
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected repeated plan %+v, got %+v", want, got)
+	}
+
+	c.CloseAll()
+	c.Wait()
+	c.Add(noop) // functions added after shutdown were never executed
+
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected executed plan %+v, got %+v", want, got)
+	}
+}
+
+// TestPlanRetry verifies that the plan shows the retry policy given to New unless a function
+// overrides it, and no policy for functions that are not retried.
+func TestPlanRetry(t *testing.T) {
+	c := New(WithRetry(2, time.Millisecond))
+	noop := func() error { return nil }
+	c.AddNamed("db", noop)
+	c.AddNamed("upload", noop, WithRetry(5, time.Second))
+	c.AddNamed("once", noop, WithRetry(1, time.Second))
+
+	want := []PlanFunc{
+		{Index: 0, Name: "db", Attempts: 2, Backoff: time.Millisecond},
+		{Index: 1, Name: "upload", Attempts: 5, Backoff: time.Second},
+		{Index: 2, Name: "once"},
+	}
+	if got := c.Plan(); len(got) != 1 || !reflect.DeepEqual(got[0].Funcs, want) {
+		t.Errorf("expected functions %+v, got %+v", want, got)
+	}
+}
+
+// TestPlanEmpty verifies that a Closer without functions has an empty plan.
+func TestPlanEmpty(t *testing.T) {
+	if got := New().Plan(); len(got) != 0 {
+		t.Errorf("expected empty plan, got %+v", got)
+	}
+}
+
+// TestWithPlanLogging verifies that the steps of the shutdown are logged when it starts.
+func TestWithPlanLogging(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithPlanLogging(), WithPhase("drain", 10))
+	c.AddNamed("http", func() error { return nil }, InPhase("drain"))
+	c.AddNamed("db", func() error { return nil })
+	c.AddNamed("lock", func() error { return nil }, Critical(time.Second))
+	c.CloseAll()
+
+	var steps []string
+	for _, event := range l.events {
+		if strings.HasPrefix(event, "INFO shutdown step") {
+			steps = append(steps, event)
+		}
+	}
+	expected := []string{
+		"INFO shutdown step step=0 concurrent=true funcs=[http #0] phase=drain",
+		"INFO shutdown step step=1 concurrent=true funcs=[db #1]",
+		"INFO shutdown step step=2 concurrent=true funcs=[lock #2] critical=true",
+	}
+	if !slices.Equal(steps, expected) {
+		t.Errorf("expected %q, got %q", expected, steps)
+	}
+}
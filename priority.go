@@ -0,0 +1,15 @@
+package closer
+
+// AddWithPriority registers one or more closing functions with the given priority.
+// During CloseAll, all functions of the highest priority run concurrently first, then those
+// of the next priority, and so on, down to negative priorities. Functions added with Add
+// have priority 0. A failing function does not prevent lower priorities from running.
+//
+// Example:
+//
+//	c.AddWithPriority(100, listener.Close) // stop accepting connections
+//	c.AddWithPriority(50, workers.Drain)   // finish in-flight work
+//	c.Add(db.Close)                        // release infrastructure
+func (c *Closer) AddWithPriority(prio int, f ...closeFunc) {
+	c.add([]Option{WithPriority(prio)}, f...)
+}
@@ -0,0 +1,71 @@
+package closer
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestAddWithPriorityOrder verifies that priorities run strictly one after another, from the
+// highest to the lowest, while functions sharing a priority overlap in time.
+func TestAddWithPriorityOrder(t *testing.T) {
+	c := New()
+	type span struct {
+		prio       int
+		start, end time.Time
+	}
+	var mu sync.Mutex
+	var spans []span
+	record := func(prio int) closeFunc {
+		return func() error {
+			start := time.Now()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			spans = append(spans, span{prio, start, time.Now()})
+			mu.Unlock()
+			return errors.New("failure does not stop lower priorities")
+		}
+	}
+	c.Add(record(0), record(0))
+	c.AddWithPriority(-5, record(-5))
+	c.AddWithPriority(100, record(100), record(100))
+	c.AddWithPriority(50, record(50))
+
+	c.CloseAll()
+	c.Wait()
+
+	if len(spans) != 6 {
+		t.Fatalf("expected 6 functions to run, got %d", len(spans))
+	}
+	for _, a := range spans {
+		for _, b := range spans {
+			switch {
+			case a.prio > b.prio && a.end.After(b.start):
+				t.Errorf("expected priority %d to finish before priority %d started", a.prio, b.prio)
+			case a.prio == b.prio && (a.end.Before(b.start) || b.end.Before(a.start)):
+				t.Errorf("expected functions of priority %d to overlap", a.prio)
+			}
+		}
+	}
+}
+
+// TestPlanPriorities compares the plan of a phased configuration against a golden structure.
+func TestPlanPriorities(t *testing.T) {
+	c := New()
+	noop := func() error { return nil }
+	c.AddNamed("db", noop)
+	c.AddWithPriority(100, noop)
+	c.Group(WithPriority(50)).AddNamed("workers", noop)
+	c.AddNamed("listener", noop, WithPriority(100))
+
+	want := []PlanStep{
+		{Index: 0, Concurrent: true, Funcs: []PlanFunc{{Index: 1, Prio: 100}, {Index: 3, Name: "listener", Prio: 100}}},
+		{Index: 1, Concurrent: true, Funcs: []PlanFunc{{Index: 2, Name: "workers", Prio: 50}}},
+		{Index: 2, Concurrent: true, Funcs: []PlanFunc{{Index: 0, Name: "db"}}},
+	}
+	if got := c.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected plan %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,70 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// AddProcess registers the child process started by cmd to be stopped when CloseAll is
+// called: it is sent graceSignal and, if it has not exited after killAfter or by the shutdown
+// deadline, killed. Either way it is waited for, so that it neither outlives the service nor
+// lingers as a zombie; the caller must therefore not call cmd.Wait itself. A kill is reported
+// with an error wrapping ErrTimeout, and exiting on graceSignal counts as success. A zero
+// killAfter waits until the deadline. A process that was never started is skipped. The
+// function is named after the executable. It panics if cmd is nil.
+//
+// Example:
+//
+//	cmd := exec.Command("envoy", "-c", "envoy.yaml")
+//	if err := cmd.Start(); err != nil {
+//		return err
+//	}
+//	c.AddProcess(cmd, syscall.SIGTERM, 10*time.Second)
+func (c *Closer) AddProcess(cmd *exec.Cmd, graceSignal os.Signal, killAfter time.Duration) {
+	mustNotBeNil(cmd, "*exec.Cmd")
+	c.addContext([]Option{withName(filepath.Base(cmd.Path))}, func(ctx context.Context) error {
+		if cmd.Process == nil {
+			return nil
+		}
+		if killAfter > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = withTimeout(ctx, killAfter)
+			defer cancel()
+		}
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+		if err := cmd.Process.Signal(graceSignal); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			cmd.Process.Kill()
+			<-exited
+			return fmt.Errorf("signal process: %w, killed", err)
+		}
+		select {
+		case err := <-exited:
+			if exitedOn(err, graceSignal) {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			cmd.Process.Kill()
+			<-exited
+			return fmt.Errorf("process exit %w: %w, killed", ErrTimeout, context.Cause(ctx))
+		}
+	})
+}
+
+// exitedOn reports whether err, as returned by exec.Cmd.Wait, tells that the process was
+// terminated by sig.
+func exitedOn(err error, sig os.Signal) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == sig
+}
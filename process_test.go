@@ -0,0 +1,90 @@
+package closer
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processChildEnv marks the helper process started by the AddProcess tests.
+const processChildEnv = "CLOSER_TEST_PROCESS_CHILD"
+
+// TestProcessHelper is the process started by the AddProcess tests. It reports on stdout once
+// its signal handling is set up, then sleeps, ignoring SIGTERM if asked to.
+func TestProcessHelper(t *testing.T) {
+	switch os.Getenv(processChildEnv) {
+	case "ignore":
+		signal.Ignore(syscall.SIGTERM)
+	case "":
+		return
+	}
+	os.Stdout.WriteString("ready\n")
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// startProcessHelper starts TestProcessHelper in mode and waits until it is ready.
+func startProcessHelper(t *testing.T, mode string) *exec.Cmd {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("signals cannot be sent on Windows")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestProcessHelper$")
+	cmd.Env = append(os.Environ(), processChildEnv+"="+mode)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bufio.NewReader(out).ReadString('\n'); err != nil {
+		t.Fatalf("expected helper to become ready, got %v", err)
+	}
+	return cmd
+}
+
+// TestAddProcess verifies that a process exiting on the grace signal is reaped without error.
+func TestAddProcess(t *testing.T) {
+	cmd := startProcessHelper(t, "exit")
+	c := New()
+	c.AddProcess(cmd, syscall.SIGTERM, time.Second)
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if cmd.ProcessState == nil {
+		t.Error("expected the process to be reaped")
+	}
+}
+
+// TestAddProcessKill verifies that a process ignoring the grace signal is killed and reaped,
+// and that the kill is reported as a timeout.
+func TestAddProcessKill(t *testing.T) {
+	cmd := startProcessHelper(t, "ignore")
+	c := New()
+	c.AddProcess(cmd, syscall.SIGTERM, 50*time.Millisecond)
+
+	if err := c.CloseAll(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected %v, got %v", ErrTimeout, err)
+	}
+	if cmd.ProcessState == nil {
+		t.Error("expected the process to be reaped")
+	}
+}
+
+// TestAddProcessNotStarted verifies that a command that was never started is skipped.
+func TestAddProcessNotStarted(t *testing.T) {
+	c := New()
+	c.AddProcess(exec.Command("true"), syscall.SIGTERM, time.Second)
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
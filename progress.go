@@ -0,0 +1,103 @@
+package closer
+
+import (
+	"sort"
+	"time"
+)
+
+// WithProgress makes New create a Closer that reports the progress of a shutdown taking
+// longer than interval: every interval, it logs an Info "shutdown in progress" event with the
+// time elapsed and the functions still running, each with how long it has been running, such
+// as "kafka/consumer #0 for 10s". A hung shutdown then shows what it is waiting on.
+//
+// Example:
+//
+//	c := closer.New(closer.WithProgress(5 * time.Second))
+func WithProgress(interval time.Duration) Option {
+	return func(o *options) {
+		o.progress = interval
+	}
+}
+
+// logProgress starts logging the functions running in col every c.progress since start, if
+// progress events are enabled, and returns a function to stop logging them.
+func (c *Closer) logProgress(col *collector, start time.Time) (stop func()) {
+	if c.progress <= 0 {
+		return func() {}
+	}
+	ticker := c.clock.NewTicker(c.progress)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case now := <-ticker.C():
+				elapsed := now.Sub(start).Round(time.Millisecond)
+				c.log().Info("shutdown in progress", "elapsed", elapsed, "pending", col.pending(now))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// WaitWithProgress is like Wait, but calls onTick every interval while closing functions are
+// running with the names of those still running, in registration order, so that interactive
+// tools can tell the user what the teardown is waiting on. Functions without a name are
+// identified as in a ShutdownError. onTick is not called before shutdown starts running
+// closing functions, nor when none is running. If interval is not positive, WaitWithProgress
+// is Wait.
+//
+// Example:
+//
+//	c.WaitWithProgress(func(pending []string) {
+//		fmt.Fprintf(os.Stderr, "waiting on: %s\n", strings.Join(pending, ", "))
+//	}, time.Second)
+func (c *Closer) WaitWithProgress(onTick func(pending []string), interval time.Duration) error {
+	mustNotBeNil(onTick, "progress callback")
+	if interval <= 0 {
+		return c.Wait()
+	}
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+	done := c.completed()
+	for {
+		select {
+		case <-done:
+			return c.result()
+		case <-ticker.C():
+			if pending := c.pendingNames(); len(pending) > 0 {
+				onTick(pending)
+			}
+		}
+	}
+}
+
+// pendingNames returns the keys of the functions running at the moment, in registration
+// order.
+func (c *Closer) pendingNames() []string {
+	c.mu.Lock()
+	col := c.current
+	c.mu.Unlock()
+	if col == nil {
+		return nil
+	}
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	entries := make([]*entry, 0, len(col.running))
+	for e := range col.running {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].index < entries[j].index })
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.key()
+	}
+	return names
+}
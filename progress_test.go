@@ -0,0 +1,79 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithProgress verifies that the functions still running are logged periodically while
+// the shutdown takes longer than the interval.
+func TestWithProgress(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithProgress(10*time.Millisecond))
+	c.AddNamed("fast", func() error { return nil })
+	c.AddNamed("slow", func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, WithLabel("kafka"))
+	c.CloseAll()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var progress []string
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "INFO shutdown in progress") {
+			progress = append(progress, e)
+		}
+	}
+	if len(progress) == 0 {
+		t.Fatalf("expected progress events, got %v", l.events)
+	}
+	if p := progress[0]; !strings.Contains(p, "pending=[kafka/slow #1 for ") || strings.Contains(p, "fast") {
+		t.Errorf("expected only the slow function to be pending, got %q", p)
+	}
+}
+
+// TestWithProgressFast verifies that no progress is logged for a shutdown shorter than the
+// interval.
+func TestWithProgressFast(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithProgress(time.Second))
+	c.Add(func() error { return nil })
+	c.CloseAll()
+
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "INFO shutdown in progress") {
+			t.Errorf("expected no progress events, got %q", e)
+		}
+	}
+}
+
+// TestWaitWithProgress verifies that WaitWithProgress reports the functions still running
+// until the shutdown completes, and returns its error.
+func TestWaitWithProgress(t *testing.T) {
+	c := New()
+	release := make(chan struct{})
+	c.AddNamed("fast", func() error { return nil })
+	c.AddNamed("db", func() error { <-release; return errors.New("db failed") })
+	c.Add(func() error { <-release; return nil })
+	go c.CloseAll()
+
+	var ticks [][]string
+	err := c.WaitWithProgress(func(pending []string) {
+		ticks = append(ticks, pending)
+		if len(ticks) == 3 {
+			close(release)
+		}
+	}, 5*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "db failed") {
+		t.Errorf("expected the error of db, got %v", err)
+	}
+	if len(ticks) < 3 {
+		t.Fatalf("expected at least 3 ticks, got %d", len(ticks))
+	}
+	if got, want := strings.Join(ticks[0], ","), "db,#2"; got != want {
+		t.Errorf("expected pending %q, got %q", want, got)
+	}
+}
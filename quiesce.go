@@ -0,0 +1,84 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// AddQuiescer registers functions that stop the intake of work, such as closing a listener or
+// pausing a queue consumer. Quiescers run before all other closing functions, in steps of
+// their own ordered like the rest of the shutdown, or earlier when Quiesce is called.
+func (c *Closer) AddQuiescer(f ...closeFunc) {
+	c.add([]Option{asQuiescer()}, f...)
+}
+
+// Quiesce runs the quiescers and turns Ready false, without initiating shutdown, so that
+// deployment tooling can stop traffic to the process, check that it has moved elsewhere, and
+// only then call Terminate. It returns the errors of the quiescers joined in registration
+// order; they are reported again by the shutdown. Further calls do nothing and return nil;
+// quiescers registered after the first call do not run before shutdown. Once shutdown has
+// started, Quiesce returns ErrClosed without running anything.
+func (c *Closer) Quiesce() error {
+	c.quiesceMu.Lock()
+	defer c.quiesceMu.Unlock()
+
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	if c.quiesced.Load() {
+		c.mu.Unlock()
+		return nil
+	}
+	c.quiesced.Store(true)
+	var quiescers []entry
+	for _, e := range c.funcs.snapshot(false) {
+		if e.quiescer {
+			quiescers = append(quiescers, e)
+		}
+	}
+	c.mu.Unlock()
+
+	c.log().Info("quiescing", "funcs", len(quiescers))
+	col := collector{ignored: c.ignored, clock: c.clock}
+	runConcurrently(withClock(context.Background(), c.clock), quiescers, c.limit, c.ordered, &col)
+	c.mu.Lock()
+	c.quiescence = col.sorted()
+	c.mu.Unlock()
+	return errors.Join(col.errors()...)
+}
+
+// Terminate runs the closing functions, completing a shutdown started with Quiesce. It is
+// CloseAll, which runs the quiescers first if Quiesce has not been called.
+func (c *Closer) Terminate() error {
+	return c.CloseAll()
+}
+
+// withoutQuiesced returns steps without the quiescers that have already run, with the given
+// results.
+func withoutQuiesced(steps []step, ran []result) []step {
+	done := make(map[int]bool, len(ran))
+	for _, r := range ran {
+		done[r.entry.index] = true
+	}
+	remaining := make([]step, 0, len(steps))
+	for _, s := range steps {
+		if s.quiesce {
+			s.funcs = slices.DeleteFunc(slices.Clone(s.funcs), func(e entry) bool { return done[e.index] })
+			if len(s.funcs) == 0 {
+				continue
+			}
+		}
+		remaining = append(remaining, s)
+	}
+	return remaining
+}
+
+// asQuiescer marks a function as a quiescer.
+func asQuiescer() Option {
+	return func(o *options) {
+		o.quiescer = true
+	}
+}
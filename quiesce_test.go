@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestQuiesce verifies that Quiesce runs the quiescers and turns readiness off without
+// initiating shutdown, and that Terminate runs the other functions only.
+func TestQuiesce(t *testing.T) {
+	c := New()
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string, err error) func() error {
+		return func() error {
+			mu.Lock()
+			calls = append(calls, name)
+			mu.Unlock()
+			return err
+		}
+	}
+	boom := errors.New("boom")
+	c.AddNamed("db", record("db", nil))
+	c.AddQuiescer(record("listener", boom))
+
+	if err := c.Quiesce(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if c.Ready() || c.IsClosing() {
+		t.Errorf("expected not ready and not closing, got ready %v and closing %v", c.Ready(), c.IsClosing())
+	}
+	if err := c.Quiesce(); err != nil {
+		t.Errorf("expected a second call to do nothing, got %v", err)
+	}
+	if !slices.Equal(calls, []string{"listener"}) {
+		t.Errorf("expected only the quiescer to run, got %v", calls)
+	}
+
+	if err := c.Terminate(); !errors.Is(err, boom) {
+		t.Errorf("expected the shutdown to report %v, got %v", boom, err)
+	}
+	if !slices.Equal(calls, []string{"listener", "db"}) {
+		t.Errorf("expected the quiescer not to run again, got %v", calls)
+	}
+	if r := c.Report(); len(r.Funcs) != 2 {
+		t.Errorf("expected both functions in the report, got %d", len(r.Funcs))
+	}
+	if err := c.Quiesce(); err != ErrClosed {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+}
+
+// TestQuiescersRunFirst verifies that CloseAll runs the quiescers in a step of their own
+// before the other functions.
+func TestQuiescersRunFirst(t *testing.T) {
+	c := New()
+	c.AddNamed("db", func() error { return nil }, WithPriority(100))
+	c.AddQuiescer(func() error { return nil })
+
+	plan := c.Plan()
+	if len(plan) != 2 || !plan[0].Quiesce || plan[1].Quiesce || plan[1].Funcs[0].Name != "db" {
+		t.Errorf("expected the quiescer step first, got %+v", plan)
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package closer
+
+import (
+	"context"
+	"os"
+)
+
+// ReasonKind identifies what initiated shutdown.
+type ReasonKind int
+
+const (
+	// ReasonNone means that shutdown has not been initiated.
+	ReasonNone ReasonKind = iota
+	// ReasonCall means that shutdown was initiated by a call to CloseAll or CloseAllContext.
+	ReasonCall
+	// ReasonSignal means that shutdown was initiated by an OS signal.
+	ReasonSignal
+	// ReasonTask means that shutdown was initiated by a task started with Go returning.
+	ReasonTask
+	// ReasonRestart means that shutdown was initiated by Restart once the new process was ready.
+	ReasonRestart
+	// ReasonRemote means that shutdown was initiated by a request to a ShutdownHandler.
+	ReasonRemote
+	// ReasonContext means that shutdown was initiated by the end of the context given to
+	// NewWithContext.
+	ReasonContext
+	// ReasonRehearsal means that no shutdown was initiated: the report is that of a fire
+	// drill run by Rehearse.
+	ReasonRehearsal
+	// ReasonMemory means that shutdown was initiated because memory ran short, by
+	// WithMemoryWatchdog or MemoryPressure.
+	ReasonMemory
+	// ReasonTrigger means that shutdown was initiated through the file given to
+	// WithTriggerFile.
+	ReasonTrigger
+	// ReasonFailure means that shutdown was initiated by FailWith.
+	ReasonFailure
+	// ReasonSupervisor means that shutdown was initiated by the process supervising this one
+	// with Supervise, or by its exit, see WithSupervisor.
+	ReasonSupervisor
+)
+
+// Reason describes what initiated shutdown.
+type Reason struct {
+	Kind   ReasonKind
+	Signal os.Signal // signal that initiated shutdown, for ReasonSignal
+	Err    error     // error returned by the task, for ReasonTask, cause of the end of the context, for ReasonContext, wrapping ErrMemoryPressure, for ReasonMemory, given to FailWith, for ReasonFailure, or ErrSupervisorLost, for ReasonSupervisor
+}
+
+// String returns a short description of the reason for logs.
+func (r Reason) String() string {
+	switch r.Kind {
+	case ReasonCall:
+		return "CloseAll call"
+	case ReasonSignal:
+		return "signal " + r.Signal.String()
+	case ReasonTask:
+		if r.Err != nil {
+			return "task failure: " + r.Err.Error()
+		}
+		return "task exit"
+	case ReasonRestart:
+		return "restart"
+	case ReasonRemote:
+		return "remote request"
+	case ReasonContext:
+		return "context done: " + r.Err.Error()
+	case ReasonRehearsal:
+		return "rehearsal"
+	case ReasonMemory:
+		return "memory pressure: " + r.Err.Error()
+	case ReasonTrigger:
+		return "trigger file"
+	case ReasonFailure:
+		return "failure: " + r.Err.Error()
+	case ReasonSupervisor:
+		if r.Err != nil {
+			return "supervisor lost"
+		}
+		return "supervisor request"
+	}
+	return "nothing"
+}
+
+// Reason reports what initiated shutdown. Before shutdown is initiated, its Kind is ReasonNone.
+// A signal received while a hold is outstanding is reported as soon as it is received, even
+// though closing functions only run once the hold is released.
+func (c *Closer) Reason() Reason {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reason
+}
+
+// reasonKey is the context key of the Reason of a shutdown.
+type reasonKey struct{}
+
+// ReasonFromContext returns the Reason of the shutdown whose context is ctx, as given to
+// closing functions registered with AddContext, so that they can behave differently depending
+// on what initiated it. It reports false if ctx is not a shutdown context, for example during
+// Flush.
+func ReasonFromContext(ctx context.Context) (Reason, bool) {
+	r, ok := ctx.Value(reasonKey{}).(Reason)
+	return r, ok
+}
+
+// AddSignalAware registers one or more closing functions that receive the signal that
+// initiated shutdown, or nil if shutdown was not initiated by a signal, so that a cleanup can
+// be quick on an interactive SIGINT and thorough on a SIGTERM from an orchestrator.
+//
+// Example:
+//
+//	c.AddSignalAware(func(sig os.Signal) error {
+//		if sig == os.Interrupt {
+//			return cache.Close()
+//		}
+//		return cache.FlushAndClose()
+//	})
+func (c *Closer) AddSignalAware(f ...func(sig os.Signal) error) {
+	fs := make([]contextFunc, len(f))
+	for i, fn := range f {
+		fs[i] = func(ctx context.Context) error {
+			r, _ := ReasonFromContext(ctx)
+			return fn(r.Signal)
+		}
+	}
+	c.addContext(nil, fs...)
+}
@@ -0,0 +1,116 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReason verifies the reason reported before and after shutdown initiated by a call
+// and by a signal, and that the reason is logged.
+func TestReason(t *testing.T) {
+	buf := captureLog(t)
+
+	c := New()
+	if r := c.Reason(); r.Kind != ReasonNone {
+		t.Errorf("expected no reason before shutdown, got %v", r)
+	}
+	c.CloseAll()
+	if r := c.Reason(); r.Kind != ReasonCall {
+		t.Errorf("expected %v, got %v", ReasonCall, r.Kind)
+	}
+
+	term := testSignal("term")
+	c = New(WithSignals(term))
+	c.watcher.ch <- term
+	c.Wait()
+	if r := c.Reason(); r.Kind != ReasonSignal || r.Signal != term {
+		t.Errorf("expected signal term, got %v", r)
+	}
+
+	if out := buf.String(); !strings.Contains(out, `reason="CloseAll call"`) ||
+		!strings.Contains(out, `reason="signal term"`) {
+		t.Errorf("expected reasons to be logged, got %q", out)
+	}
+}
+
+// TestReasonWhileHeld verifies that a signal received during a hold is reported right away.
+func TestReasonWhileHeld(t *testing.T) {
+	term := testSignal("term")
+	c := New(WithSignals(term))
+	release, _ := c.Hold()
+
+	c.watcher.ch <- term
+	deadline := time.Now().Add(time.Second)
+	for c.Reason().Kind != ReasonSignal {
+		if time.Now().After(deadline) {
+			t.Fatal("expected signal reason while held")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	release()
+	c.Wait()
+}
+
+// TestAddSignalAware verifies that closing functions receive the signal that initiated
+// shutdown, and that the reason is available from the shutdown context.
+func TestAddSignalAware(t *testing.T) {
+	term := testSignal("term")
+	c := New(WithSignals(term))
+	var got os.Signal
+	var reason Reason
+	c.AddSignalAware(func(sig os.Signal) error {
+		got = sig
+		return nil
+	})
+	c.AddContext(func(ctx context.Context) error {
+		reason, _ = ReasonFromContext(ctx)
+		return nil
+	})
+	c.watcher.ch <- term
+	c.Wait()
+	if got != term || reason.Signal != term {
+		t.Errorf("expected %v, got %v and %v", term, got, reason.Signal)
+	}
+
+	c = New()
+	got = term
+	c.AddSignalAware(func(sig os.Signal) error {
+		got = sig
+		return nil
+	})
+	c.CloseAll()
+	if got != nil {
+		t.Errorf("expected no signal on CloseAll, got %v", got)
+	}
+	if _, ok := ReasonFromContext(context.Background()); ok {
+		t.Error("expected no reason outside a shutdown")
+	}
+}
+
+// TestNewWithContext verifies that the end of the parent context initiates shutdown.
+func TestNewWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	c := NewWithContext(ctx)
+	var alive bool
+	c.AddContext(func(ctx context.Context) error {
+		alive = ctx.Err() == nil
+		return nil
+	})
+	stop := errors.New("stop")
+	cancel(stop)
+	c.Wait()
+	if r := c.Reason(); r.Kind != ReasonContext || r.Err != stop {
+		t.Errorf("expected %v with %v, got %v", ReasonContext, stop, r)
+	}
+	if !alive {
+		t.Error("expected closing functions to get a live context")
+	}
+	if s := c.Reason().String(); s != "context done: stop" {
+		t.Errorf("expected %q, got %q", "context done: stop", s)
+	}
+}
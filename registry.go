@@ -0,0 +1,116 @@
+package closer
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// registryShards is the number of shards of a registry. Registrations pick a shard at
+// random, so that concurrent callers rarely wait for each other.
+const registryShards = 32
+
+// registry holds the closing functions registered and not yet taken by CloseAll, spread
+// over shards with a lock each, so that services registering and removing functions at a
+// high rate, such as one per connection, do not contend on a single lock.
+type registry struct {
+	next   atomic.Int64 // registration index of the next function
+	shards [registryShards]registryShard
+}
+
+// registryShard is a part of a registry.
+type registryShard struct {
+	mu     sync.Mutex
+	funcs  []entry // sorted by registration index
+	sealed bool    // set once CloseAll has taken funcs, later functions are late
+	_      [64]byte
+}
+
+// lock picks a shard at random and locks it. The caller appends to the funcs of the shard,
+// unless it is sealed, and unlocks it.
+func (r *registry) lock() (s *registryShard, shard int) {
+	shard = int(rand.Uint32N(registryShards))
+	s = &r.shards[shard]
+	s.mu.Lock()
+	return s, shard
+}
+
+// reserve returns the first of n consecutive registration indexes. Indexes reserved while
+// holding the lock of a shard increase within the shard, keeping its funcs sorted.
+func (r *registry) reserve(n int) int {
+	return int(r.next.Add(int64(n))) - n
+}
+
+// remove removes the function with the given index from shard, and reports whether it
+// was there.
+func (r *registry) remove(shard, index int) bool {
+	_, ok := r.take(shard, index)
+	return ok
+}
+
+// take removes the function with the given index from shard and returns it, reporting
+// whether it was there. Functions restored by Reset are all in the first shard, so it is
+// searched as well.
+func (r *registry) take(shard, index int) (entry, bool) {
+	for _, shard := range []int{shard, 0} {
+		s := &r.shards[shard]
+		s.mu.Lock()
+		i, ok := slices.BinarySearchFunc(s.funcs, index, func(e entry, index int) int {
+			return e.index - index
+		})
+		if ok {
+			e := s.funcs[i]
+			s.funcs = slices.Delete(s.funcs, i, i+1)
+			s.mu.Unlock()
+			return e, true
+		}
+		s.mu.Unlock()
+	}
+	return entry{}, false
+}
+
+// snapshot returns the functions of r in registration order. If seal is set, they are
+// taken from r, and functions registered afterwards are late.
+func (r *registry) snapshot(seal bool) []entry {
+	n := 0
+	for i := range r.shards {
+		r.shards[i].mu.Lock()
+		n += len(r.shards[i].funcs)
+	}
+	// Merge the shards, each sorted already, rather than sorting large entries.
+	funcs := make([]entry, 0, n)
+	var heads [registryShards]int
+	for len(funcs) < n {
+		next := -1
+		for i := range r.shards {
+			s := &r.shards[i]
+			if heads[i] < len(s.funcs) && (next < 0 || s.funcs[heads[i]].index < r.shards[next].funcs[heads[next]].index) {
+				next = i
+			}
+		}
+		funcs = append(funcs, r.shards[next].funcs[heads[next]])
+		heads[next]++
+	}
+	for i := range r.shards {
+		s := &r.shards[i]
+		if seal {
+			s.funcs, s.sealed = nil, true
+		}
+		s.mu.Unlock()
+	}
+	return funcs
+}
+
+// restore puts funcs, in registration order, back into r and accepts registrations again.
+func (r *registry) restore(funcs []entry) {
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.mu.Lock()
+		s.funcs, s.sealed = nil, false
+		if i == 0 {
+			s.funcs = funcs
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,60 @@
+package closer
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestRegistryOrder verifies that functions registered concurrently are planned and run in
+// registration order, and that removed ones are left out.
+func TestRegistryOrder(t *testing.T) {
+	c := New(WithSequential())
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ran []int
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				var h *Handle
+				h = c.AddRemovable(func() error {
+					mu.Lock()
+					ran = append(ran, h.at.index)
+					mu.Unlock()
+					return nil
+				})
+				if h.at.index%2 == 0 {
+					h.Remove()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.CloseAll()
+	if len(ran) != 400 {
+		t.Fatalf("expected 400 functions to run, got %d", len(ran))
+	}
+	for i, index := range ran {
+		if index%2 == 0 || i > 0 && index <= ran[i-1] {
+			t.Fatalf("expected odd indexes in registration order, got %d after %v", index, ran[:i])
+		}
+	}
+}
+
+// BenchmarkAddParallel measures registering and removing functions from many goroutines at
+// once, as services do with per-connection cleanups.
+func BenchmarkAddParallel(b *testing.B) {
+	c := New()
+	noop := func() error { return nil }
+	b.SetParallelism(64)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.AddRemovable(noop).Remove()
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package closer
+
+import (
+	"context"
+	"time"
+)
+
+// Rehearsable is implemented by resources that can rehearse their shutdown without tearing
+// anything down, for example by checking that buffers could be flushed in time. Resources
+// registered with AddCloser, AddValue or Manage that implement it rehearse with Rehearse.
+type Rehearsable interface {
+	Rehearse(ctx context.Context) error
+}
+
+// WithRehearsal makes closing functions rehearse their shutdown with f when Rehearse is
+// called, instead of doing nothing.
+func WithRehearsal(f func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.rehearsal = f
+	}
+}
+
+// rehearsalOf returns the options making v rehearse with its Rehearse method, if it has one.
+func rehearsalOf(v any) []Option {
+	if r, ok := v.(Rehearsable); ok {
+		return []Option{WithRehearsal(r.Rehearse)}
+	}
+	return nil
+}
+
+// Rehearse runs a fire drill of the shutdown: it executes the Plan with ctx, bounded by the
+// timeout of the Closer as CloseAll would be, but runs the rehearsal of every function given
+// with WithRehearsal or Rehearsable instead of the function itself, and nothing for functions
+// without one. It returns the report of the drill, with ReasonRehearsal, without modifying
+// the Closer, so that shutdown budgets can be checked without restarting a service. Once
+// shutdown has started, there is nothing left to rehearse.
+//
+// Example:
+//
+//	r := c.Rehearse(ctx)
+//	log.Printf("shutdown would take %v", r.Duration)
+func (c *Closer) Rehearse(ctx context.Context) Report {
+	c.mu.Lock()
+	steps := c.plan(c.funcs.snapshot(false))
+	c.mu.Unlock()
+
+	n := 0
+	for _, s := range steps {
+		for i := range s.funcs {
+			e := &s.funcs[i]
+			e.fn, e.tries = e.rehearse, nil
+			if e.fn == nil {
+				e.fn = func(context.Context) error { return nil }
+			}
+			n++
+		}
+	}
+	ctx = withClock(ctx, c.clock)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	col := collector{
+		ignored: c.ignored,
+		results: make([]result, 0, n),
+		running: make(map[*entry]time.Time),
+		clock:   c.clock,
+	}
+	start := c.clock.Now()
+	execute(ctx, steps, c.limit, c.budgets, &col)
+	r := Report{Reason: Reason{Kind: ReasonRehearsal}, Start: start, Duration: c.clock.Now().Sub(start)}
+	for _, res := range col.sorted() {
+		r.Funcs = append(r.Funcs, res.report())
+	}
+	c.keepReport(r)
+	return r
+}
@@ -0,0 +1,68 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// rehearsableCloser is an io.Closer that can rehearse its shutdown.
+type rehearsableCloser struct {
+	closed, rehearsed int
+}
+
+func (r *rehearsableCloser) Close() error { r.closed++; return nil }
+
+func (r *rehearsableCloser) Rehearse(context.Context) error {
+	r.rehearsed++
+	return errors.New("would not flush in time")
+}
+
+// TestRehearse verifies that Rehearse runs rehearsals instead of the functions, reports
+// them, and leaves the Closer untouched.
+func TestRehearse(t *testing.T) {
+	c := New()
+	closed := false
+	c.AddNamed("db", func() error { closed = true; return nil })
+	c.AddNamed("upload", func() error { closed = true; return nil }, WithRehearsal(func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}))
+	v := AddValue(c, &rehearsableCloser{})
+
+	r := c.Rehearse(context.Background())
+	if closed || v.closed != 0 {
+		t.Fatal("expected no function to run")
+	}
+	if v.rehearsed != 1 {
+		t.Errorf("expected the value to rehearse once, got %d", v.rehearsed)
+	}
+	if r.Reason.Kind != ReasonRehearsal || len(r.Funcs) != 3 || r.Duration < 20*time.Millisecond {
+		t.Fatalf("expected a rehearsal report of 3 functions lasting 20ms, got %+v", r)
+	}
+	if r.Funcs[0].Err != nil || r.Funcs[1].Duration < 20*time.Millisecond || r.Funcs[2].Err == nil {
+		t.Errorf("expected the outcome of every rehearsal, got %+v", r.Funcs)
+	}
+	if c.IsClosing() {
+		t.Error("expected the Closer to keep running")
+	}
+
+	c.CloseAll()
+	if !closed || v.closed != 1 {
+		t.Error("expected the functions to run on shutdown")
+	}
+}
+
+// TestRehearseTimeout verifies that rehearsals are bounded by the timeout of the Closer.
+func TestRehearseTimeout(t *testing.T) {
+	c := New(WithTimeout(10 * time.Millisecond))
+	c.AddNamed("upload", func() error { return nil }, WithRehearsal(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	r := c.Rehearse(context.Background())
+	if len(r.Funcs) != 1 || !r.Funcs[0].TimedOut {
+		t.Errorf("expected the rehearsal to time out, got %+v", r.Funcs)
+	}
+}
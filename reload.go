@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"errors"
+	"os"
+)
+
+// WithReloadSignal makes New create a Closer that runs the callbacks registered with OnReload
+// when sig is received, instead of SIGHUP on Unix and no signal elsewhere.
+func WithReloadSignal(sig os.Signal) Option {
+	return func(o *options) {
+		o.reloadSignal = sig
+	}
+}
+
+// OnReload registers fn to be called when the reload signal is received, SIGHUP on Unix unless
+// WithReloadSignal is given, or when Reload is called. The reload signal does not trigger
+// shutdown, and it is watched from the first call to OnReload until shutdown starts, then
+// ignored until shutdown completes, so that it cannot kill the process meanwhile. On other
+// platforms, without WithReloadSignal, only Reload runs fn.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM))
+//	c.OnReload(cfg.Reload) // SIGHUP reloads, SIGTERM shuts down
+func (c *Closer) OnReload(fn func() error) {
+	mustNotBeNil(fn, "reload function")
+	c.mu.Lock()
+	first := len(c.reloads) == 0
+	c.reloads = append(c.reloads, fn)
+	c.mu.Unlock()
+	if first && c.reloadSig != nil {
+		c.onSignal(c.reloadSig, func(c *Closer, _ os.Signal) { c.Reload() })
+	}
+}
+
+// Reload runs the callbacks registered with OnReload one at a time, in registration order,
+// and returns their errors joined. Failures are also logged. Reloads never overlap, whether
+// they are triggered by the reload signal or by calls to Reload.
+func (c *Closer) Reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	c.mu.Lock()
+	reloads := c.reloads
+	c.mu.Unlock()
+
+	l := c.log()
+	var errs []error
+	for _, fn := range reloads {
+		if err := fn(); err != nil {
+			l.Error("reload failed", "error", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		l.Info("reloaded", "funcs", len(reloads))
+	}
+	return errors.Join(errs...)
+}
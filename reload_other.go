@@ -0,0 +1,9 @@
+//go:build !unix
+
+package closer
+
+import "os"
+
+// defaultReloadSignal is the signal triggering Reload unless WithReloadSignal is given: there
+// is none where SIGHUP is not the conventional reload signal.
+var defaultReloadSignal os.Signal
@@ -0,0 +1,56 @@
+package closer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOnReload verifies that the reload signal runs the reload callbacks without triggering
+// shutdown, while the shutdown signal still does.
+func TestOnReload(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	c := New(WithSignals(term), WithReloadSignal(hup))
+	reloaded := make(chan struct{}, 1)
+	c.OnReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	c.watcher.ch <- hup
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("expected reload callback to run")
+	}
+	if c.IsClosing() {
+		t.Error("expected reload signal not to trigger shutdown")
+	}
+
+	c.watcher.ch <- term
+	c.Wait()
+}
+
+// TestReload verifies that Reload runs every callback and returns their errors.
+func TestReload(t *testing.T) {
+	c := New()
+	boom := errors.New("boom")
+	var calls int32
+	c.OnReload(func() error {
+		atomic.AddInt32(&calls, 1)
+		return boom
+	})
+	c.OnReload(func() error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	if err := c.Reload(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected 2 callbacks to run, got %d", n)
+	}
+	c.CloseAll()
+}
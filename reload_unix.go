@@ -0,0 +1,11 @@
+//go:build unix
+
+package closer
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignal is the signal triggering Reload unless WithReloadSignal is given.
+var defaultReloadSignal os.Signal = syscall.SIGHUP
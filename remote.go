@@ -0,0 +1,92 @@
+package closer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ShutdownHandler returns an HTTP handler that initiates shutdown on a POST request carrying
+// token in an "Authorization: Bearer" header, for orchestration systems and chat bots that
+// cannot send signals to the process. The response streams the events of the shutdown as
+// newline-delimited JSON objects, one per Event, until it completes; the shutdown carries on
+// if the client goes away. Other methods are rejected with 405 Method Not Allowed, a missing
+// or wrong token with 401 Unauthorized, and a request made once shutdown has been initiated
+// with 409 Conflict. The shutdown is reported with ReasonRemote. ShutdownHandler panics if
+// token is empty.
+//
+// Example:
+//
+//	adminMux.Handle("/admin/shutdown", c.ShutdownHandler(os.Getenv("SHUTDOWN_TOKEN")))
+func (c *Closer) ShutdownHandler(token string) http.Handler {
+	if token == "" {
+		panic("closer: empty shutdown token")
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if c.IsClosing() {
+			http.Error(w, "shutdown already initiated", http.StatusConflict)
+			return
+		}
+		events := c.Subscribe()
+		go c.closeAll(context.Background(), Reason{Kind: ReasonRemote})
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusAccepted)
+		rc := http.NewResponseController(w)
+		enc := json.NewEncoder(w)
+		for ev := range events {
+			if enc.Encode(remoteEvent(ev)) != nil {
+				// The client went away; the shutdown does not depend on it.
+				return
+			}
+			rc.Flush()
+		}
+	})
+}
+
+// shutdownEvent is the JSON form of an Event streamed by ShutdownHandler.
+type shutdownEvent struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	Reason   string    `json:"reason"`
+	Index    *int      `json:"index,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Label    string    `json:"label,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+	Err      string    `json:"error,omitempty"`
+}
+
+// remoteEvent converts ev to its JSON form.
+func remoteEvent(ev Event) shutdownEvent {
+	se := shutdownEvent{Event: ev.Kind.String(), Time: ev.Time, Reason: ev.Reason.String()}
+	switch ev.Kind {
+	case FuncStarted, FuncFinished, FuncFailed:
+		index := ev.Func.Index
+		se.Index = &index
+		se.Name = ev.Func.Name
+		se.Label = ev.Func.Label
+		if ev.Kind != FuncStarted {
+			se.Duration = ev.Func.Duration.Round(time.Microsecond).String()
+		}
+		if ev.Func.Err != nil {
+			se.Err = ev.Func.Err.Error()
+		}
+	case ShutdownCompleted:
+		if ev.Err != nil {
+			se.Err = ev.Err.Error()
+		}
+	}
+	return se
+}
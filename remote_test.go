@@ -0,0 +1,81 @@
+package closer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestShutdownHandler verifies that only an authenticated POST initiates shutdown, and that
+// its events are streamed back.
+func TestShutdownHandler(t *testing.T) {
+	c := New(WithSequential())
+	c.AddNamed("db", func() error { return errors.New("busy") })
+	srv := httptest.NewServer(c.ShutdownHandler("secret"))
+	defer srv.Close()
+	post := func(method, auth string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for _, tc := range []struct {
+		method, auth string
+		code         int
+	}{
+		{http.MethodGet, "Bearer secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, "", http.StatusUnauthorized},
+		{http.MethodPost, "Bearer wrong", http.StatusUnauthorized},
+	} {
+		resp := post(tc.method, tc.auth)
+		resp.Body.Close()
+		if resp.StatusCode != tc.code {
+			t.Errorf("expected %d for %s %q, got %d", tc.code, tc.method, tc.auth, resp.StatusCode)
+		}
+	}
+	if c.IsClosing() {
+		t.Fatal("expected rejected requests not to initiate shutdown")
+	}
+
+	resp := post(http.MethodPost, "Bearer secret")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+	var got []string
+	var last shutdownEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		last = shutdownEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, last.Event+" "+last.Name)
+	}
+	expected := []string{"ShutdownTriggered ", "FuncStarted db", "FuncFailed db", "ShutdownCompleted "}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if last.Reason != "remote request" || last.Err == "" {
+		t.Errorf("expected a failed remote shutdown, got %+v", last)
+	}
+	if r := c.Reason(); r.Kind != ReasonRemote {
+		t.Errorf("expected %v, got %v", ReasonRemote, r.Kind)
+	}
+
+	resp = post(http.MethodPost, "Bearer secret")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected %d once closed, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Report describes a completed shutdown, as returned by Closer.Report.
+type Report struct {
+	Reason   Reason        // what initiated the shutdown
+	Start    time.Time     // when CloseAll started running closing functions, after holds were released
+	Duration time.Duration // how long running the closing functions took
+	Extended time.Duration // how much closing functions extended the deadline, see WithDeadlineExtension
+	Funcs    []FuncReport  // one per registered function, in registration order
+}
+
+// FuncReport describes how a single closing function ran during shutdown.
+type FuncReport struct {
+	Index    int           // registration index
+	Name     string        // name given to AddNamed, if any
+	Label    string        // label given with WithLabel, if any
+	Start    time.Time     // when the function was started, zero if it never was
+	Duration time.Duration // how long the function ran, or ran before being abandoned
+	Err      error         // error the function was reported with, without the name prefix
+	TimedOut bool          // whether it was abandoned because its timeout or the shutdown deadline elapsed
+	Panicked bool          // whether it panicked, in which case Err is a *PanicError
+	Skipped  bool          // whether it was not run because an earlier function failed, see WithFailFast
+	Attempts int           // how many times it was called, more than once only if WithRetry applies
+	Caller   string        // file and line of the call that registered it, such as "/src/app/main.go:42"
+	Optional bool          // whether its failure does not fail the shutdown, see Optional
+}
+
+// Report waits for the shutdown to complete, like Wait, and returns a report on how each
+// registered function ran. The report is useful to find out which functions make the
+// shutdown slow, for example to tune the grace period an orchestrator gives the process.
+func (c *Closer) Report() Report {
+	<-c.completed()
+	return c.buildReport()
+}
+
+// buildReport returns the report of a shutdown whose closing functions have finished.
+func (c *Closer) buildReport() Report {
+	r := c.report
+	r.Funcs = make([]FuncReport, len(c.results))
+	for i, res := range c.results {
+		r.Funcs[i] = res.report()
+	}
+	return r
+}
+
+// report describes res as a FuncReport.
+func (res result) report() FuncReport {
+	var p *PanicError
+	attempts := 0
+	switch {
+	case res.entry.tries != nil:
+		attempts = int(res.entry.tries.Load())
+	case !res.start.IsZero():
+		attempts = 1
+	}
+	return FuncReport{
+		Index:    res.entry.index,
+		Name:     res.entry.name,
+		Label:    res.entry.label,
+		Start:    res.start,
+		Duration: res.duration,
+		Err:      res.err,
+		TimedOut: errors.Is(res.err, ErrTimeout) || errors.Is(res.err, context.DeadlineExceeded),
+		Panicked: errors.As(res.err, &p),
+		Skipped:  errors.Is(res.err, ErrSkipped),
+		Attempts: attempts,
+		Caller:   res.entry.caller,
+		Optional: res.entry.optional,
+	}
+}
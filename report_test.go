@@ -0,0 +1,63 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestReport verifies the per-function outcomes reported after shutdown.
+func TestReport(t *testing.T) {
+	c := New()
+	boom := errors.New("boom")
+	c.AddNamed("cache", func() error { return nil }, WithLabel("storage"))
+	c.AddNamed("db", func() error { return boom })
+	c.Add(func() error { panic("oops") })
+	c.AddWithTimeout(10*time.Millisecond, func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	c.CloseAll()
+
+	r := c.Report()
+	if r.Reason.Kind != ReasonCall || r.Start.IsZero() || r.Duration <= 0 {
+		t.Errorf("expected a summary of a called shutdown, got %+v", r)
+	}
+	if len(r.Funcs) != 4 {
+		t.Fatalf("expected 4 functions, got %d", len(r.Funcs))
+	}
+	for i, f := range r.Funcs {
+		if f.Index != i || f.Start.IsZero() {
+			t.Errorf("expected function %d to be started, got %+v", i, f)
+		}
+	}
+	if f := r.Funcs[0]; f.Name != "cache" || f.Label != "storage" || f.Err != nil {
+		t.Errorf("expected storage/cache to succeed, got %+v", f)
+	}
+	if f := r.Funcs[1]; f.Name != "db" || f.Err != boom || f.TimedOut || f.Panicked {
+		t.Errorf("expected db to fail with %v, got %+v", boom, f)
+	}
+	if f := r.Funcs[2]; !f.Panicked || f.TimedOut {
+		t.Errorf("expected function 2 to panic, got %+v", f)
+	}
+	if f := r.Funcs[3]; !f.TimedOut || f.Panicked || f.Duration < 10*time.Millisecond {
+		t.Errorf("expected function 3 to time out, got %+v", f)
+	}
+}
+
+// TestReportNotStarted verifies that functions skipped because the deadline passed are
+// reported as timed out and never started.
+func TestReportNotStarted(t *testing.T) {
+	c := New(WithTimeout(10 * time.Millisecond))
+	c.AddWithPriority(1, func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	c.Add(func() error { return nil })
+	c.CloseAll()
+
+	r := c.Report()
+	if f := r.Funcs[1]; !f.TimedOut || !f.Start.IsZero() || f.Duration != 0 {
+		t.Errorf("expected function 1 to never start, got %+v", f)
+	}
+}
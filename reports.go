@@ -0,0 +1,66 @@
+package closer
+
+// WithReportHistory makes New create a Closer that keeps the reports of its last n
+// shutdowns and rehearsals in memory, returned by History and shown by DebugHandler, so that
+// flaky teardowns can be diagnosed across the shutdowns of a Closer reused with Reset, such
+// as by the test cases of a suite, or across fire drills run by Rehearse. Reports of large
+// Closers are large too, so keep n small.
+//
+// Example:
+//
+//	c := closer.New(closer.WithReportHistory(20))
+func WithReportHistory(n int) Option {
+	return func(o *options) {
+		o.reportHistory = n
+	}
+}
+
+// History returns the reports kept as configured by WithReportHistory, oldest first. Reports
+// of rehearsals have ReasonRehearsal. It returns nil without WithReportHistory.
+func (c *Closer) History() []Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reports.all()
+}
+
+// reportRing holds the last reports of a Closer. Its methods do nothing on a nil reportRing.
+type reportRing struct {
+	reports []Report // up to the capacity of the ring, the oldest at next once full
+	next    int      // where the next report goes once the ring is full
+}
+
+// newReportRing returns a ring of n reports, nil if n is not positive.
+func newReportRing(n int) *reportRing {
+	if n <= 0 {
+		return nil
+	}
+	return &reportRing{reports: make([]Report, 0, n)}
+}
+
+// add keeps r, dropping the oldest report if the ring is full.
+func (h *reportRing) add(r Report) {
+	if h == nil {
+		return
+	}
+	if len(h.reports) < cap(h.reports) {
+		h.reports = append(h.reports, r)
+		return
+	}
+	h.reports[h.next] = r
+	h.next = (h.next + 1) % len(h.reports)
+}
+
+// all returns the reports kept, oldest first.
+func (h *reportRing) all() []Report {
+	if h == nil {
+		return nil
+	}
+	return append(append([]Report(nil), h.reports[h.next:]...), h.reports[:h.next]...)
+}
+
+// keepReport adds r to the reports kept by c, if any.
+func (c *Closer) keepReport(r Report) {
+	c.mu.Lock()
+	c.reports.add(r)
+	c.mu.Unlock()
+}
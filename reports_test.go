@@ -0,0 +1,63 @@
+package closer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHistory verifies that the last reports of shutdowns and rehearsals are kept, oldest
+// first, across Reset.
+func TestHistory(t *testing.T) {
+	c := New(WithReportHistory(3))
+	fail := true
+	c.AddNamed("db", func() error {
+		if fail {
+			return errors.New("busy")
+		}
+		return nil
+	})
+
+	c.Rehearse(context.Background())
+	for range 3 {
+		c.CloseAll()
+		c.Reset()
+		fail = false
+	}
+
+	h := c.History()
+	if len(h) != 3 {
+		t.Fatalf("expected 3 reports, got %d", len(h))
+	}
+	if h[0].Reason.Kind != ReasonCall || h[0].Funcs[0].Err == nil {
+		t.Errorf("expected the oldest report to be the failed shutdown, got %+v", h[0])
+	}
+	for _, r := range h[1:] {
+		if r.Funcs[0].Err != nil {
+			t.Errorf("expected later shutdowns to succeed, got %v", r.Funcs[0].Err)
+		}
+	}
+	if New().History() != nil {
+		t.Error("expected no history without WithReportHistory")
+	}
+}
+
+// TestHistoryDebugHandler verifies that DebugHandler shows the history.
+func TestHistoryDebugHandler(t *testing.T) {
+	c := New(WithReportHistory(5))
+	c.AddNamed("db", func() error { return errors.New("busy") })
+	c.Rehearse(context.Background())
+	c.CloseAll()
+
+	rec := httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/closer?format=json", nil))
+	var v debugView
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.History) != 2 || v.History[0].Reason != "rehearsal" || v.History[1].Failures != 1 {
+		t.Errorf("expected a rehearsal then a failed shutdown, got %+v", v.History)
+	}
+}
@@ -0,0 +1,58 @@
+package closer
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrShutdownInProgress is returned by Reset while a shutdown is running.
+var ErrShutdownInProgress = errors.New("closer: shutdown in progress")
+
+// Reset re-arms c after a completed shutdown, so that the same wiring can be shut down again,
+// typically by the next test case of a suite. The functions registered before the shutdown
+// are registered again with their options, the signals watched before are watched again and
+// the watchdogs of WithMemoryWatchdog and WithTriggerFile are started again;
+// the reason, report, errors and Context of the previous shutdown are discarded, as are
+// functions registered once it had started. Hooks and reload callbacks are kept. Reset does
+// nothing on a Closer that has not been shut down and returns ErrShutdownInProgress while
+// a shutdown is running. It must not be called concurrently with CloseAll, Wait or Report.
+func (c *Closer) Reset() error {
+	c.mu.Lock()
+	if !c.closing {
+		c.mu.Unlock()
+		return nil
+	}
+	if !c.closed {
+		c.mu.Unlock()
+		return ErrShutdownInProgress
+	}
+	done := c.done
+	c.mu.Unlock()
+	// Wait for CloseAll to finish its cleanup after recording the completion.
+	<-done
+
+	c.mu.Lock()
+	c.funcs.restore(c.registered)
+	c.registered = nil
+	c.steps = nil
+	c.triggers = 0
+	c.done = make(chan struct{})
+	c.ctx, c.cancel = context.WithCancelCause(context.Background())
+	c.closing, c.started, c.closed = false, false, false
+	c.reason = Reason{}
+	c.taskErrs = nil
+	c.fatal, c.settled = nil, false
+	c.err = nil
+	c.report = Report{}
+	c.results = nil
+	c.errs = nil
+	c.subs = nil
+	c.quiescence = nil
+	c.quiesced.Store(false)
+	if c.watcher != nil {
+		c.watcher = c.watcher.restart(c)
+	}
+	c.mu.Unlock()
+	c.startWatchdogs()
+	return nil
+}
@@ -0,0 +1,119 @@
+package closer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestReset verifies that a Closer shut down and reset runs the same functions again and
+// reports the second shutdown on its own.
+func TestReset(t *testing.T) {
+	c := New()
+	var calls atomic.Int32
+	c.AddNamed("db", func() error {
+		if calls.Add(1) == 1 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if err := c.Reset(); err != nil {
+		t.Errorf("expected no error before shutdown, got %v", err)
+	}
+	if err := c.CloseAll(); err == nil {
+		t.Error("expected error from the first shutdown")
+	}
+	if err := c.Reset(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if c.IsClosing() || c.Reason().Kind != ReasonNone || c.Context().Err() != nil {
+		t.Error("expected reset Closer not to be closing")
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error from the second shutdown, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+	if funcs := c.Report().Funcs; len(funcs) != 1 || funcs[0].Err != nil {
+		t.Errorf("expected a clean report, got %+v", funcs)
+	}
+}
+
+// TestResetInProgress verifies that a running shutdown cannot be reset.
+func TestResetInProgress(t *testing.T) {
+	c := New()
+	release, _ := c.Hold()
+	go c.CloseAll()
+	<-c.Done()
+
+	if err := c.Reset(); !errors.Is(err, ErrShutdownInProgress) {
+		t.Errorf("expected %v, got %v", ErrShutdownInProgress, err)
+	}
+	release()
+	c.Wait()
+}
+
+// TestResetSignals verifies that a reset Closer shuts down on its signals again.
+func TestResetSignals(t *testing.T) {
+	term := testSignal("term")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term), WithNotifier(n))
+	n.send(term)
+	c.Wait()
+
+	c.Reset()
+	if !n.send(term) {
+		t.Fatal("expected term to be watched again")
+	}
+	c.Wait()
+	if r := c.Reason(); r.Signal != term {
+		t.Errorf("expected shutdown on term, got %v", r)
+	}
+}
+
+// TestResetWatchdogs verifies that the watchdogs of WithTriggerFile and WithMemoryWatchdog
+// initiate shutdown again after Reset.
+func TestResetWatchdogs(t *testing.T) {
+	t.Run("trigger file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "shutdown")
+		c := New(WithTriggerFile(path))
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.WaitTimeout(5 * time.Second); err != nil {
+			t.Fatalf("expected shutdown, got %v", err)
+		}
+
+		c.Reset()
+		later := time.Now().Add(time.Second)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.WaitTimeout(5 * time.Second); err != nil {
+			t.Fatalf("expected shutdown once touched again, got %v", err)
+		}
+		if r := c.Reason(); r.Kind != ReasonTrigger {
+			t.Errorf("expected trigger file reason, got %v", r)
+		}
+	})
+	t.Run("memory", func(t *testing.T) {
+		setCgroup(t, map[string]string{"memory.current": "950\n", "memory.max": "1000\n"})
+		c := New(WithMemoryWatchdog(0, time.Millisecond))
+		if err := c.WaitTimeout(time.Second); err != nil {
+			t.Fatalf("expected shutdown, got %v", err)
+		}
+
+		c.Reset()
+		if err := c.WaitTimeout(time.Second); err != nil {
+			t.Fatalf("expected shutdown again, got %v", err)
+		}
+		if r := c.Reason(); r.Kind != ReasonMemory {
+			t.Errorf("expected memory pressure, got %v", r)
+		}
+	})
+}
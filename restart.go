@@ -0,0 +1,223 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables through which Restart hands listeners over to the new process.
+const (
+	listenersEnv = "CLOSER_LISTENERS" // comma-separated network=address keys of the inherited listeners
+	readyEnv     = "CLOSER_READY_FD"  // descriptor the new process reports readiness on
+)
+
+// ErrNotReady is returned by Restart when the new process exits or the context is done before
+// the new process calls Ready.
+var ErrNotReady = errors.New("closer: new process did not become ready")
+
+// WithRestart makes New create a Closer that calls Restart when sig is received, waiting at
+// most timeout for the new process to become ready; zero waits until it exits. The restart
+// signal does not otherwise trigger shutdown: if the restart fails, it is logged and the
+// process keeps running.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithRestart(syscall.SIGUSR2, time.Minute))
+func WithRestart(sig os.Signal, timeout time.Duration) Option {
+	return func(o *options) {
+		o.restartSignal = sig
+		o.restartTimeout = timeout
+	}
+}
+
+// Listen returns a listener on the network address, like net.Listen, that is handed over to
+// the new process on Restart. In a process started by Restart, it returns the listener
+// inherited for the same network and address instead of opening a new one, so that no
+// connection is refused while the processes take turns.
+//
+// Example:
+//
+//	l, err := c.Listen("tcp", ":8080")
+//	go srv.Serve(l)
+//	closer.Ready()
+func (c *Closer) Listen(network, address string) (net.Listener, error) {
+	key := network + "=" + address
+	l, err := inheritedListener(key)
+	if l == nil && err == nil {
+		l, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.listeners = append(c.listeners, keyedListener{key: key, l: l})
+	c.mu.Unlock()
+	return l, nil
+}
+
+// Restart replaces the running process without downtime: it starts the executable again with
+// the same arguments, handing over the listeners opened with Listen, waits until the new
+// process calls Ready, and then shuts down the Closer as CloseAll does, returning its errors.
+// If the new process exits or ctx is done first, the new process is killed and Restart returns
+// an error wrapping ErrNotReady, leaving the Closer running. Restarts never overlap. Restart
+// is not supported on Windows.
+func (c *Closer) Restart(ctx context.Context) error {
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+	c.mu.Lock()
+	listeners := slices.Clone(c.listeners)
+	c.mu.Unlock()
+
+	cmd, err := restartCommand()
+	if err != nil {
+		return fmt.Errorf("closer: restart: %w", err)
+	}
+	ready, err := startRestarted(cmd, listeners)
+	if err != nil {
+		return fmt.Errorf("closer: restart: %w", err)
+	}
+	l := c.log()
+	l.Info("restarting", "pid", cmd.Process.Pid)
+
+	reported := make(chan bool, 1)
+	go func() {
+		defer ready.Close()
+		n, _ := ready.Read(make([]byte, 1))
+		reported <- n > 0
+	}()
+	select {
+	case ok := <-reported:
+		if ok {
+			cmd.Process.Release()
+			return c.closeAll(context.Background(), Reason{Kind: ReasonRestart})
+		}
+		err = fmt.Errorf("%w: exited", ErrNotReady)
+	case <-ctx.Done():
+		err = fmt.Errorf("%w: %w", ErrNotReady, context.Cause(ctx))
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	l.Error("restart failed", "error", err)
+	return err
+}
+
+// Ready tells the process that started this one with Restart that it is ready to serve, so
+// that the old process shuts down. It does nothing and returns nil if this process was not
+// started by Restart, or if Ready has already been called.
+func Ready() error {
+	fd, err := strconv.Atoi(os.Getenv(readyEnv))
+	if err != nil {
+		return nil
+	}
+	os.Unsetenv(readyEnv)
+	f := os.NewFile(uintptr(fd), "closer-ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// keyedListener is a listener opened with Listen together with the network and address it
+// was requested for.
+type keyedListener struct {
+	key string
+	l   net.Listener
+}
+
+// restartCommand returns the command starting the executable of this process again.
+// Tests replace it to start a helper process.
+var restartCommand = func() (*exec.Cmd, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path, os.Args[1:]...), nil
+}
+
+// startRestarted starts cmd with the files of listeners, and returns the pipe it reports
+// readiness on.
+func startRestarted(cmd *exec.Cmd, listeners []keyedListener) (*os.File, error) {
+	keys := make([]string, 0, len(listeners))
+	for _, kl := range listeners {
+		fl, ok := kl.l.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, fmt.Errorf("listener %s cannot be handed over", kl.key)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+		keys = append(keys, kl.key)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+
+	// Descriptors 0 to 2 are the standard streams, ExtraFiles follow in order.
+	cmd.Env = append(os.Environ(),
+		listenersEnv+"="+strings.Join(keys, ","),
+		readyEnv+"="+strconv.Itoa(3+len(keys)),
+	)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   map[string]*os.File // listener files handed over by Restart, by key
+)
+
+// inheritedListener returns the listener handed over by Restart for key, if any. Each
+// inherited listener is returned at most once.
+func inheritedListener(key string) (net.Listener, error) {
+	inheritOnce.Do(func() {
+		keys := os.Getenv(listenersEnv)
+		os.Unsetenv(listenersEnv)
+		if keys == "" {
+			return
+		}
+		inherited = make(map[string]*os.File)
+		for i, k := range strings.Split(keys, ",") {
+			inherited[k] = os.NewFile(uintptr(3+i), k)
+		}
+	})
+	inheritMu.Lock()
+	f := inherited[key]
+	delete(inherited, key)
+	inheritMu.Unlock()
+	if f == nil {
+		return nil, nil
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// restartOnSignal restarts c on the restart signal, waiting at most timeout for the new
+// process to become ready. Failures are logged by Restart.
+func (c *Closer) restartOnSignal(timeout time.Duration) {
+	ctx := withClock(context.Background(), c.clock)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, timeout)
+		defer cancel()
+	}
+	c.Restart(ctx)
+}
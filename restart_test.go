@@ -0,0 +1,109 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// restartChildEnv marks the helper process started by the restart tests.
+const restartChildEnv = "CLOSER_TEST_RESTART_CHILD"
+
+// useRestartHelper makes Restart start the test binary running only TestRestartHelper, with
+// mode telling it how to behave.
+func useRestartHelper(t *testing.T, mode string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("restart is not supported on Windows")
+	}
+	t.Setenv(restartChildEnv, mode)
+	prev := restartCommand
+	restartCommand = func() (*exec.Cmd, error) {
+		return exec.Command(os.Args[0], "-test.run=^TestRestartHelper$"), nil
+	}
+	t.Cleanup(func() { restartCommand = prev })
+}
+
+// TestRestartHelper is the process started by the restart tests. It takes over the listener
+// and answers one connection, or exits without reporting readiness.
+func TestRestartHelper(t *testing.T) {
+	switch os.Getenv(restartChildEnv) {
+	case "serve":
+		c := New()
+		l, err := c.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			os.Exit(2)
+		}
+		Ready()
+		l.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		if conn, err := l.Accept(); err == nil {
+			conn.Write([]byte("new"))
+			conn.Close()
+		}
+		os.Exit(0)
+	case "fail":
+		os.Exit(1)
+	}
+}
+
+// TestRestart verifies that the new process takes over the listener before the Closer
+// shuts down.
+func TestRestart(t *testing.T) {
+	useRestartHelper(t, "serve")
+	c := New()
+	l, err := c.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddNamed("listener", l.Close)
+
+	if err := c.Restart(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonRestart {
+		t.Errorf("expected restart reason, got %v", r)
+	}
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("expected the new process to accept connections, got %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if b, _ := io.ReadAll(conn); string(b) != "new" {
+		t.Errorf("expected new, got %q", b)
+	}
+}
+
+// TestRestartNotReady verifies that a new process exiting before it is ready leaves the
+// Closer running.
+func TestRestartNotReady(t *testing.T) {
+	useRestartHelper(t, "fail")
+	c := New()
+
+	if err := c.Restart(context.Background()); !errors.Is(err, ErrNotReady) {
+		t.Errorf("expected %v, got %v", ErrNotReady, err)
+	}
+	if c.IsClosing() {
+		t.Error("expected the Closer to keep running")
+	}
+	c.CloseAll()
+}
+
+// TestListen verifies that Listen opens a new listener outside a restarted process.
+func TestListen(t *testing.T) {
+	c := New()
+	l, err := c.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, ok := l.(*net.TCPListener); !ok {
+		t.Errorf("expected a TCP listener, got %T", l)
+	}
+}
@@ -0,0 +1,55 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// WithRetry makes closing functions that fail be called again, up to attempts times in
+// total, waiting backoff before the first retry and twice as long before each following one.
+// Passed to New, it applies to every function registered without WithRetry of its own; passed
+// to a registration or a Group, it applies to those functions only. Only the error of the last
+// attempt is reported. Functions are not retried once they panic, nor once their timeout or
+// the shutdown deadline has passed. The number of attempts is part of the Report.
+//
+// Example:
+//
+//	c.AddNamed("upload", flushToS3, closer.WithRetry(3, 100*time.Millisecond))
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retry = &retryPolicy{attempts: attempts, backoff: backoff}
+	}
+}
+
+// retryPolicy configures how often and how fast a failing closing function is retried.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// wrap returns a function calling fn according to p, which counts the attempts of its
+// latest run in attempts.
+func (p *retryPolicy) wrap(fn contextFunc, attempts *atomic.Int32) contextFunc {
+	return func(ctx context.Context) error {
+		attempts.Store(0)
+		backoff := p.backoff
+		for {
+			attempts.Add(1)
+			err := call(fn, ctx)
+			var pe *PanicError
+			if err == nil || int(attempts.Load()) >= p.attempts || errors.As(err, &pe) {
+				return err
+			}
+			t := clockFrom(ctx).NewTimer(backoff)
+			select {
+			case <-t.C():
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			}
+			backoff *= 2
+		}
+	}
+}
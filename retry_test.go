@@ -0,0 +1,76 @@
+package closer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithRetry verifies that a failing function is retried until it succeeds and that the
+// attempts are reported.
+func TestWithRetry(t *testing.T) {
+	c := New()
+	var calls atomic.Int32
+	c.AddNamed("upload", func() error {
+		if calls.Add(1) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}, WithRetry(5, time.Millisecond))
+	c.Add(func() error { return nil })
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	funcs := c.Report().Funcs
+	if funcs[0].Attempts != 3 || funcs[1].Attempts != 1 {
+		t.Errorf("expected 3 and 1 attempts, got %d and %d", funcs[0].Attempts, funcs[1].Attempts)
+	}
+}
+
+// TestWithRetryGlobal verifies that a retry policy given to New applies to every function
+// and reports the error of the last attempt once attempts are exhausted.
+func TestWithRetryGlobal(t *testing.T) {
+	c := New(WithRetry(2, time.Millisecond))
+	var calls atomic.Int32
+	boom := errors.New("boom")
+	c.Add(func() error {
+		calls.Add(1)
+		return boom
+	})
+
+	if err := c.CloseAll(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+	if f := c.Report().Funcs[0]; f.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", f.Attempts)
+	}
+}
+
+// TestWithRetryDeadline verifies that retries stop once the function's timeout passes and
+// that panics are not retried.
+func TestWithRetryDeadline(t *testing.T) {
+	c := New()
+	var calls, panics atomic.Int32
+	c.AddNamed("upload", func() error {
+		calls.Add(1)
+		return errors.New("boom")
+	}, WithRetry(100, 10*time.Millisecond), WithTimeout(25*time.Millisecond))
+	c.AddNamed("panic", func() error {
+		panics.Add(1)
+		panic("boom")
+	}, WithRetry(3, 0))
+
+	c.CloseAll()
+	time.Sleep(20 * time.Millisecond)
+	if n := calls.Load(); n > 3 {
+		t.Errorf("expected retries to stop at the timeout, got %d calls", n)
+	}
+	if n := panics.Load(); n != 1 {
+		t.Errorf("expected a panic not to be retried, got %d calls", n)
+	}
+}
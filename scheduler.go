@@ -0,0 +1,55 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Scheduler is implemented by job schedulers, such as *cron.Cron of github.com/robfig/cron,
+// to take part in the phases of a shutdown.
+type Scheduler interface {
+	// Stop stops scheduling jobs, without interrupting those running, and returns a context
+	// that is done once they have completed.
+	Stop() context.Context
+}
+
+// AddScheduler registers s to take part in shutdown, configured by opts: it is stopped as a
+// quiescer, before the other closing functions or when Quiesce is called, then a closing
+// function waits for the jobs that were running to complete, so that functions ordered after
+// it, such as those closing what the jobs use, run once they have. If jobs are still running
+// at the deadline, the function fails with the deadline error; the jobs are not interrupted.
+// The functions are named after the dynamic type of s. It panics if s is nil.
+//
+// Example:
+//
+//	c.AddScheduler(cron, closer.WithPriority(10))
+//	c.AddNamed("db", db.Close)
+func (c *Closer) AddScheduler(s Scheduler, opts ...Option) {
+	mustNotBeNil(s, "Scheduler")
+	opts = append([]Option{withName(typeName(s))}, opts...)
+	var (
+		mu   sync.Mutex
+		jobs context.Context // returned by Stop, nil until it is called
+	)
+	stop := func() context.Context {
+		mu.Lock()
+		defer mu.Unlock()
+		if jobs == nil {
+			jobs = s.Stop()
+		}
+		return jobs
+	}
+	c.add(append(opts, asQuiescer()), func() error {
+		stop()
+		return nil
+	})
+	c.addContext(opts, func(ctx context.Context) error {
+		select {
+		case <-stop().Done():
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("jobs still running: %w", context.Cause(ctx))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeScheduler counts the calls to Stop and completes the running jobs once release is
+// closed.
+type fakeScheduler struct {
+	stops   atomic.Int32
+	release chan struct{}
+}
+
+func (s *fakeScheduler) Stop() context.Context {
+	s.stops.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-s.release
+		cancel()
+	}()
+	return ctx
+}
+
+// TestAddScheduler verifies that a scheduler is stopped once when quiescing, and that the
+// functions ordered after it run once its jobs have completed.
+func TestAddScheduler(t *testing.T) {
+	c := New()
+	s := &fakeScheduler{release: make(chan struct{})}
+	c.AddScheduler(s, WithPriority(1))
+	var released atomic.Bool
+	c.AddNamed("db", func() error {
+		if !released.Load() {
+			return errors.New("closed while jobs were running")
+		}
+		return nil
+	})
+
+	c.Quiesce()
+	if n := s.stops.Load(); n != 1 {
+		t.Fatalf("expected 1 Stop on Quiesce, got %d", n)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		released.Store(true)
+		close(s.release)
+	}()
+	if err := c.Terminate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if n := s.stops.Load(); n != 1 {
+		t.Errorf("expected Stop to be called once, got %d", n)
+	}
+	if names := c.Names(); len(names) != 3 || names[0] != "*closer.fakeScheduler" {
+		t.Errorf("expected functions named after the type, got %v", names)
+	}
+}
+
+// TestAddSchedulerDeadline verifies that jobs still running at the deadline are reported.
+func TestAddSchedulerDeadline(t *testing.T) {
+	c := New(WithTimeout(20 * time.Millisecond))
+	s := &fakeScheduler{release: make(chan struct{})}
+	defer close(s.release)
+	c.AddScheduler(s)
+
+	err := c.CloseAll()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,43 @@
+package closer
+
+import "context"
+
+// Scope creates a Closer for resources that live as long as ctx, such as those of a request
+// or a session. Its functions run when ctx is done, or when shutdown of c reaches the scope
+// first, in which case it is closed like a Child, as a closing function of c named "scope".
+// Once the scope has been closed, it is unregistered from c, so that short-lived scopes do not
+// accumulate in a long-running process. The scope can also be closed on its own with CloseAll.
+// A shutdown initiated by ctx is reported with ReasonContext.
+//
+// opts configure both the scope, as if passed to New, and its registration in c, as if passed
+// to AddNamed. The scope uses c's logger unless opts give it another one.
+//
+// Example:
+//
+//	func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+//		scope := s.closer.Scope(r.Context())
+//		f := closer.AddValue(scope, must(os.CreateTemp("", "upload")))
+//		...
+//	}
+func (c *Closer) Scope(ctx context.Context, opts ...Option) *Closer {
+	if c.logger != nil {
+		opts = append([]Option{WithLogger(c.logger)}, opts...)
+	}
+	scope := New(opts...)
+	h := &Handle{c: c}
+	h.at = c.addContext(append([]Option{withName("scope")}, opts...), func(ctx context.Context) error {
+		return scope.CloseAllContext(ctx)
+	})
+	stop := context.AfterFunc(ctx, func() {
+		scope.closeAll(context.Background(), Reason{Kind: ReasonContext, Err: context.Cause(ctx)})
+	})
+	scope.OnShutdownStart(func(Reason) {
+		stop()
+		h.Remove()
+	})
+	if scope.IsClosing() {
+		// ctx was already done and the shutdown started before the hook was registered.
+		h.Remove()
+	}
+	return scope
+}
@@ -0,0 +1,44 @@
+package closer
+
+import (
+	"context"
+	"testing"
+)
+
+// TestScope verifies that a scope is closed when its context is done and then unregistered,
+// and that a scope still alive is closed by the parent's shutdown.
+func TestScope(t *testing.T) {
+	c := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	scope := c.Scope(ctx)
+	closed := make(chan struct{})
+	scope.AddFunc(func() { close(closed) })
+	if n := c.Len(); n != 1 {
+		t.Fatalf("expected the scope to be registered, got %d functions", n)
+	}
+	cancel()
+	<-closed
+	scope.Wait()
+	if r := scope.Reason(); r.Kind != ReasonContext {
+		t.Errorf("expected %v, got %v", ReasonContext, r.Kind)
+	}
+	if n := c.Len(); n != 0 {
+		t.Errorf("expected the closed scope to be unregistered, got %d functions", n)
+	}
+
+	alive := c.Scope(context.Background())
+	var ran bool
+	alive.AddFunc(func() { ran = true })
+	c.CloseAll()
+	if !ran || !alive.IsClosed() {
+		t.Error("expected the parent's shutdown to close the scope")
+	}
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	late := New()
+	late.Scope(done).Wait()
+	if n := late.Len(); n != 0 {
+		t.Errorf("expected a scope of a done context to be unregistered, got %d functions", n)
+	}
+}
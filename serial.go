@@ -0,0 +1,21 @@
+package closer
+
+// AddSerialized registers a closing function that never runs concurrently with other
+// functions registered under the same key. During CloseAll, functions sharing a key run one
+// at a time in registration order, while functions with different keys and functions added
+// with Add still run concurrently. Timeouts and errors apply to each function individually.
+//
+// Example:
+//
+//	c.AddSerialized("cgo-handle", index.Close)
+//	c.AddSerialized("cgo-handle", store.Close)
+func (c *Closer) AddSerialized(key string, f closeFunc) {
+	c.add([]Option{withSerialKey(key)}, f)
+}
+
+// withSerialKey sets the key used to serialize a function with others sharing it.
+func withSerialKey(key string) Option {
+	return func(o *options) {
+		o.serial = key
+	}
+}
@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAddSerializedMutualExclusion mutates an unsynchronized variable from two serialized
+// functions. Run with -race to prove they never execute concurrently.
+func TestAddSerializedMutualExclusion(t *testing.T) {
+	c := New()
+	var order []int
+	for i := 0; i < 2; i++ {
+		c.AddSerialized("handle", func() error {
+			time.Sleep(5 * time.Millisecond)
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	c.CloseAll()
+	c.Wait()
+
+	if len(order) != 2 || order[0] != 0 || order[1] != 1 {
+		t.Errorf("expected functions to run in registration order, got %v", order)
+	}
+}
+
+// TestAddSerializedConcurrency tracks how many functions run at the same time: functions
+// sharing a key never overlap, while plain functions and other keys still run concurrently.
+func TestAddSerializedConcurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(c *Closer, f closeFunc)
+		want     int32
+	}{
+		{"same key", func(c *Closer, f closeFunc) { c.AddSerialized("key", f) }, 1},
+		{"plain add", func(c *Closer, f closeFunc) { c.Add(f) }, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			var active, peak int32
+			f := func() error {
+				n := atomic.AddInt32(&active, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+				return nil
+			}
+			for i := 0; i < 3; i++ {
+				tt.register(c, f)
+			}
+
+			c.CloseAll()
+			c.Wait()
+
+			if p := atomic.LoadInt32(&peak); p != tt.want {
+				t.Errorf("expected peak concurrency %d, got %d", tt.want, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package closer
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"sync"
+	"weak"
+)
+
+// OnSignal registers fn to be called every time sig is received, until shutdown starts. Unless
+// sig triggers shutdown, it is then ignored until shutdown completes, rather than getting back
+// a default behavior that may terminate the process. If sig was given to New, fn is called
+// before the shutdown it triggers; otherwise sig is watched from now on without triggering
+// shutdown, and the Closer keeps listening after fn returns. Callbacks run one at a time, in
+// registration order, on the goroutine watching signals. A callback that panics is logged and
+// does not stop the watching goroutine. OnSignal may be called at any time; it does nothing
+// once shutdown has started.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM, syscall.SIGQUIT))
+//	c.OnSignal(syscall.SIGQUIT, dumpStacks) // dump stacks, then shut down
+//	c.OnSignal(syscall.SIGHUP, reload)      // reload, keep running
+func (c *Closer) OnSignal(sig os.Signal, fn func(os.Signal)) {
+	c.onSignal(sig, func(_ *Closer, sig os.Signal) { fn(sig) })
+}
+
+// OnShutdownSignal registers fn to be called with whichever signal triggers shutdown, such
+// as one given to WithSignals or Notify, for last-moment actions that do not depend on the
+// signal: logging it, notifying peers or counting it in a metric. fn runs after the
+// callbacks registered with OnSignal for that signal and before the shutdown starts, so
+// before Done is closed and any closing function or hook runs. Callbacks run one at a time,
+// in registration order, on the goroutine watching signals; one that panics is logged.
+// Signals received once shutdown has started, and shutdowns initiated otherwise, do not call
+// fn. OnShutdownSignal does nothing once shutdown has started.
+//
+// Example:
+//
+//	c.OnShutdownSignal(func(sig os.Signal) {
+//		signalsReceived.WithLabelValues(sig.String()).Inc()
+//	})
+func (c *Closer) OnShutdownSignal(fn func(os.Signal)) {
+	mustNotBeNil(fn, "callback")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return
+	}
+	if c.watcher == nil {
+		c.watcher = newSignalWatcher(c)
+	}
+	c.watcher.forward(func(_ *Closer, sig os.Signal) { fn(sig) })
+}
+
+// signalCallback is a callback run when a signal is received, with the Closer watching it.
+// Callbacks get the Closer as an argument rather than capturing it, so that the watcher does
+// not keep the Closer reachable.
+type signalCallback func(c *Closer, sig os.Signal)
+
+// onSignal is like OnSignal for callbacks that need the Closer.
+func (c *Closer) onSignal(sig os.Signal, fn signalCallback) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return
+	}
+	if c.watcher == nil {
+		c.watcher = newSignalWatcher(c)
+	}
+	c.watcher.handle(sig, fn)
+}
+
+// Notify makes the signals sigs trigger CloseAll, in addition to those given to WithSignals
+// or to previous calls, for applications that enable signal handling depending on how they
+// run, such as as a daemon rather than from a terminal. Notify does nothing once shutdown has
+// started.
+//
+// Example:
+//
+//	c := closer.New()
+//	if daemon {
+//		c.Notify(syscall.SIGTERM, syscall.SIGINT)
+//	}
+func (c *Closer) Notify(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return
+	}
+	if c.watcher == nil {
+		c.watcher = newSignalWatcher(c)
+	}
+	c.watcher.shutdownOn(sigs)
+}
+
+// notifyUnlessWatched makes the signals sigs trigger CloseAll, as Notify does, unless a
+// signal already does.
+func (c *Closer) notifyUnlessWatched(sigs ...os.Signal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return
+	}
+	if c.watcher == nil {
+		c.watcher = newSignalWatcher(c)
+	}
+	if !c.watcher.shutsDown() {
+		c.watcher.shutdownOn(sigs)
+	}
+}
+
+// Ignore makes the signals sigs no longer trigger CloseAll. Signals without callbacks
+// registered with OnSignal are released and get their default behavior back; the others only
+// run their callbacks from now on. Ignore does nothing for signals that do not trigger
+// CloseAll, and once shutdown has started.
+func (c *Closer) Ignore(sigs ...os.Signal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing || c.watcher == nil {
+		return
+	}
+	c.watcher.ignore(sigs)
+}
+
+// shutdownStarted tells the signal watcher, if any, that shutdown has started.
+func (c *Closer) shutdownStarted() {
+	c.mu.Lock()
+	w := c.watcher
+	c.mu.Unlock()
+	if w != nil {
+		w.triggered()
+	}
+}
+
+// StopSignalHandling stops watching OS signals and restores their default behavior.
+// Signals received afterwards no longer trigger CloseAll or signal callbacks. It is safe to
+// call StopSignalHandling more than once, and on a Closer that watches no signals.
+// CloseAll stops signal handling automatically once shutdown completes.
+func (c *Closer) StopSignalHandling() {
+	c.mu.Lock()
+	w := c.watcher
+	c.mu.Unlock()
+	if w != nil {
+		w.stop()
+	}
+}
+
+// Notifier is a source of OS signals, with the semantics of signal.Notify and signal.Stop.
+// The default Notifier relays the signals received by the process; WithNotifier replaces it,
+// for example to deliver signals in tests without signaling the test process.
+type Notifier interface {
+	// Notify makes the Notifier relay the signals sigs to c, in addition to those already
+	// relayed to it.
+	Notify(c chan<- os.Signal, sigs ...os.Signal)
+	// Stop makes the Notifier stop relaying signals to c.
+	Stop(c chan<- os.Signal)
+}
+
+// WithNotifier makes New create a Closer that receives signals from n instead of from the
+// operating system, for WithSignals, OnSignal and reloads alike. A nil n keeps the default.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithNotifier(fake))
+func WithNotifier(n Notifier) Option {
+	return func(o *options) {
+		if n != nil {
+			o.notifier = n
+		}
+	}
+}
+
+// osNotifier is the Notifier relaying the signals received by the process.
+type osNotifier struct{}
+
+func (osNotifier) Notify(c chan<- os.Signal, sigs ...os.Signal) { signal.Notify(c, sigs...) }
+func (osNotifier) Stop(c chan<- os.Signal)                      { signal.Stop(c) }
+
+// signalWatcher forwards OS signals to a Closer. It does not keep the Closer reachable,
+// so a Closer that is dropped without ever being closed can be garbage collected,
+// which in turn stops the watcher and releases its signal registration.
+type signalWatcher struct {
+	ch        chan os.Signal
+	done      chan struct{}
+	once      sync.Once
+	notifier  Notifier   // source of the signals
+	forceExit *forceExit // how to exit on a shutdown signal during shutdown, nil to not exit
+
+	mu         sync.Mutex                     // protects the fields below
+	shutdown   map[os.Signal]bool             // signals that trigger CloseAll
+	callbacks  map[os.Signal][]signalCallback // callbacks run when a signal is received
+	forwards   []signalCallback               // callbacks run when any shutdown signal is received
+	escalating bool                           // shutdown has started, callbacks no longer run
+}
+
+// forceExit configures how the process exits when shutdown is escalated by a second signal.
+type forceExit struct {
+	code int       // exit code
+	dump io.Writer // destination of the goroutine stack dump, nil to skip it
+}
+
+// newSignalWatcher starts watching signals on behalf of c. No signal is subscribed yet.
+func newSignalWatcher(c *Closer) *signalWatcher {
+	w := &signalWatcher{
+		ch:        make(chan os.Signal, 1),
+		done:      make(chan struct{}),
+		notifier:  c.notifier,
+		forceExit: c.forceExit,
+		shutdown:  make(map[os.Signal]bool),
+		callbacks: make(map[os.Signal][]signalCallback),
+	}
+	runtime.AddCleanup(c, (*signalWatcher).stop, w)
+	go w.watch(weak.Make(c))
+	return w
+}
+
+// restart returns a new watcher for c, which replaces the stopped watcher w, watching the
+// same signals with the same callbacks.
+func (w *signalWatcher) restart(c *Closer) *signalWatcher {
+	w.stop()
+	next := newSignalWatcher(c)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var sigs []os.Signal
+	for sig := range w.shutdown {
+		sigs = append(sigs, sig)
+	}
+	if len(sigs) > 0 {
+		next.shutdownOn(sigs)
+	}
+	for sig, fns := range w.callbacks {
+		for _, fn := range fns {
+			next.handle(sig, fn)
+		}
+	}
+	for _, fn := range w.forwards {
+		next.forward(fn)
+	}
+	return next
+}
+
+// shutdownOn subscribes to sigs and makes them trigger CloseAll.
+// Subscription happens before returning, so signals delivered right after are not missed.
+func (w *signalWatcher) shutdownOn(sigs []os.Signal) {
+	w.mu.Lock()
+	for _, sig := range sigs {
+		w.shutdown[sig] = true
+	}
+	w.mu.Unlock()
+	w.notifier.Notify(w.ch, sigs...)
+}
+
+// shutsDown reports whether any signal triggers CloseAll.
+func (w *signalWatcher) shutsDown() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.shutdown) > 0
+}
+
+// ignore makes sigs no longer trigger CloseAll and unsubscribes the ones without callbacks.
+// As a Notifier can only stop relaying all signals at once, the remaining signals are
+// subscribed again; this happens under the lock, so that it does not interleave with
+// other changes.
+func (w *signalWatcher) ignore(sigs []os.Signal) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changed := false
+	for _, sig := range sigs {
+		if w.shutdown[sig] {
+			delete(w.shutdown, sig)
+			changed = true
+		}
+	}
+	if !changed || w.stopped() {
+		return
+	}
+	var keep []os.Signal
+	for sig := range w.shutdown {
+		keep = append(keep, sig)
+	}
+	for sig := range w.callbacks {
+		if !w.shutdown[sig] {
+			keep = append(keep, sig)
+		}
+	}
+	w.notifier.Stop(w.ch)
+	if len(keep) > 0 {
+		w.notifier.Notify(w.ch, keep...)
+	}
+}
+
+// handle subscribes to sig and registers fn to be called when it is received.
+func (w *signalWatcher) handle(sig os.Signal, fn signalCallback) {
+	w.mu.Lock()
+	w.callbacks[sig] = append(w.callbacks[sig], fn)
+	w.mu.Unlock()
+	w.notifier.Notify(w.ch, sig)
+}
+
+// forward registers fn to be called when any shutdown signal is received.
+func (w *signalWatcher) forward(fn signalCallback) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.forwards = append(w.forwards, fn)
+}
+
+// watch dispatches received signals until the watcher is stopped. The first shutdown signal
+// triggers CloseAll; if force exit is configured, a shutdown signal received after that
+// terminates the process.
+func (w *signalWatcher) watch(ref weak.Pointer[Closer]) {
+	for {
+		select {
+		case sig := <-w.ch:
+			w.mu.Lock()
+			callbacks := w.callbacks[sig]
+			shutdown := w.shutdown[sig]
+			if shutdown {
+				callbacks = append(slices.Clip(callbacks), w.forwards...)
+			}
+			escalating := w.escalating
+			w.mu.Unlock()
+
+			if escalating {
+				if shutdown && w.forceExit != nil {
+					w.forceExit.exit()
+				}
+				continue
+			}
+			c := ref.Value()
+			if c == nil {
+				// The Closer is being collected, which stops the watcher.
+				continue
+			}
+			for _, fn := range callbacks {
+				runCallback(c, fn, sig)
+			}
+			if !shutdown {
+				continue
+			}
+			w.triggered()
+			go c.closeAll(context.Background(), Reason{Kind: ReasonSignal, Signal: sig})
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// runCallback runs fn for sig, logging a panic instead of crashing the watching goroutine.
+func runCallback(c *Closer, fn signalCallback, sig os.Signal) {
+	err := call(func(context.Context) error {
+		fn(c, sig)
+		return nil
+	}, context.Background())
+	if err != nil {
+		c.log().Error("signal callback panicked", "signal", sig, "error", err)
+	}
+}
+
+// triggered switches the watcher to escalation mode, in which callbacks no longer run. Unless
+// force exit is configured, the shutdown signals are released and get their default behavior
+// back, while the signals with callbacks stay subscribed and are ignored until the watcher is
+// stopped: a reload signal received during a long shutdown must not kill the process.
+func (w *signalWatcher) triggered() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.escalating || w.stopped() {
+		return
+	}
+	w.escalating = true
+	if w.forceExit != nil {
+		return
+	}
+	var keep []os.Signal
+	for sig := range w.callbacks {
+		if !w.shutdown[sig] {
+			keep = append(keep, sig)
+		}
+	}
+	if len(keep) == 0 {
+		w.stop()
+		return
+	}
+	w.notifier.Stop(w.ch)
+	w.notifier.Notify(w.ch, keep...)
+}
+
+// exit dumps the goroutine stacks if configured and terminates the process.
+func (f *forceExit) exit() {
+	if f.dump != nil {
+		dumpStacks(f.dump)
+	}
+	exit(f.code)
+}
+
+// dumpStacks writes the stack traces of all goroutines to w, in the format of an unrecovered
+// panic.
+func dumpStacks(w io.Writer) {
+	pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// stopped reports whether the watcher has been stopped.
+func (w *signalWatcher) stopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop releases the signal registration and terminates the watching goroutine.
+func (w *signalWatcher) stop() {
+	w.once.Do(func() {
+		w.notifier.Stop(w.ch)
+		close(w.done)
+	})
+}
@@ -0,0 +1,422 @@
+package closer
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSignalWatcherNoLeak creates Closers with signals that are never closed and verifies
+// that their watching goroutines terminate once the Closers are garbage collected.
+func TestSignalWatcherNoLeak(t *testing.T) {
+	// The first subscription starts the signal loop of os/signal, which never returns.
+	New(WithSignals(os.Interrupt)).StopSignalHandling()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		New(WithSignals(os.Interrupt))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestSignalCallbacksNoLeak verifies that the signal callbacks installed by OnReload,
+// OnDiagnostics and WithRestart do not keep their Closers alive.
+func TestSignalCallbacksNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		c := New(WithSignals(os.Interrupt), WithReloadSignal(testSignal("hup")),
+			WithRestart(testSignal("usr2"), time.Second))
+		c.OnReload(func() error { return nil })
+		c.OnDiagnostics(testSignal("quit"), io.Discard)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestSignalCallbackPanic verifies that a panicking signal callback is logged and does not
+// stop the watcher from handling later signals.
+func TestSignalCallbackPanic(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	n := &fakeNotifier{}
+	l := &recordLogger{}
+	c := New(WithSignals(term), WithNotifier(n), WithLogger(l))
+	c.OnSignal(hup, func(os.Signal) { panic("boom") })
+
+	n.send(hup)
+	n.send(term)
+	c.Wait()
+	if r := c.Reason(); r.Signal != term {
+		t.Errorf("expected shutdown on term, got %v", r)
+	}
+	found := false
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "ERROR signal callback panicked signal=hup error=") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected panic to be logged, got %v", l.events)
+	}
+}
+
+// TestStopSignalHandling verifies that StopSignalHandling terminates the watching goroutine
+// and that CloseAll does the same.
+func TestStopSignalHandling(t *testing.T) {
+	tests := map[string]func(c *Closer){
+		"StopSignalHandling": (*Closer).StopSignalHandling,
+		"CloseAll":           func(c *Closer) { c.CloseAll() },
+	}
+	for name, stop := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(WithSignals(os.Interrupt))
+			stop(c)
+
+			select {
+			case <-c.watcher.done:
+			case <-time.After(time.Second):
+				t.Fatal("expected signal watcher to stop")
+			}
+			c.StopSignalHandling() // stopping again is a no-op
+		})
+	}
+}
+
+// BenchmarkCloseAll100k measures the memory used to shut down 100k no-op closing functions.
+func BenchmarkCloseAll100k(b *testing.B) {
+	noop := func() error { return nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c := New()
+		for j := 0; j < 100000; j++ {
+			c.Add(noop)
+		}
+		c.CloseAll()
+		c.Wait()
+	}
+}
+
+// testSignal is an os.Signal that is never delivered by the OS. Tests inject it directly
+// into the watcher channel to simulate signals without signaling the test process.
+type testSignal string
+
+func (s testSignal) String() string { return string(s) }
+func (s testSignal) Signal()        {}
+
+// TestOnSignal simulates two different signals and verifies that only the one given to New
+// triggers shutdown, while the callback-only one invokes its function and keeps listening.
+func TestOnSignal(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	c := New(WithSignals(term))
+
+	events := make(chan string, 10)
+	c.OnSignal(hup, func(sig os.Signal) { events <- "callback " + sig.String() })
+	c.OnSignal(term, func(sig os.Signal) { events <- "callback " + sig.String() })
+	c.Add(func() error {
+		events <- "closed"
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		c.watcher.ch <- hup
+		if got := <-events; got != "callback hup" {
+			t.Fatalf("expected hup callback, got %q", got)
+		}
+	}
+	select {
+	case got := <-events:
+		t.Fatalf("expected no shutdown on hup, got %q", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.watcher.ch <- term
+	c.Wait()
+
+	for _, want := range []string{"callback term", "closed"} {
+		if got := <-events; got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+// TestOnSignalWithoutShutdownSignals verifies that a Closer created without signals starts
+// watching when a callback is registered, without shutting down when it fires.
+func TestOnSignalWithoutShutdownSignals(t *testing.T) {
+	c := New()
+	called := make(chan os.Signal, 1)
+	c.OnSignal(testSignal("usr1"), func(sig os.Signal) { called <- sig })
+
+	c.watcher.ch <- testSignal("usr1")
+	select {
+	case sig := <-called:
+		if sig != testSignal("usr1") {
+			t.Errorf("expected usr1, got %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected callback to be invoked")
+	}
+
+	if _, ok := c.Hold(); !ok {
+		t.Error("expected Closer not to be shutting down")
+	}
+}
+
+// TestForceExit verifies that a shutdown signal received during shutdown dumps the goroutine
+// stacks and exits with the configured code.
+func TestForceExit(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	term := testSignal("term")
+	var dump syncBuffer
+	c := New(WithSignals(term), WithForceExit(3), WithStackDump(&dump))
+	started := make(chan struct{})
+	block := make(chan struct{})
+	defer close(block)
+	c.Add(func() error {
+		close(started)
+		<-block
+		return nil
+	})
+
+	c.watcher.ch <- term
+	<-started
+	c.watcher.ch <- term
+
+	select {
+	case code := <-codes:
+		if code != 3 {
+			t.Errorf("expected exit code 3, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected process to be forced to exit")
+	}
+	if !strings.Contains(dump.String(), "goroutine") {
+		t.Errorf("expected goroutine dump, got %q", dump.String())
+	}
+}
+
+// TestNoForceExitByDefault verifies that without force exit, signal handling stops once
+// shutdown starts.
+func TestNoForceExitByDefault(t *testing.T) {
+	term := testSignal("term")
+	c := New(WithSignals(term))
+	block := make(chan struct{})
+	c.Add(func() error {
+		<-block
+		return nil
+	})
+
+	c.watcher.ch <- term
+	select {
+	case <-c.watcher.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected signal handling to stop once shutdown started")
+	}
+	close(block)
+	c.Wait()
+}
+
+// fakeNotifier relays signals sent with send to the channels subscribed to them.
+type fakeNotifier struct {
+	mu   sync.Mutex
+	subs map[chan<- os.Signal][]os.Signal
+}
+
+func (n *fakeNotifier) Notify(c chan<- os.Signal, sigs ...os.Signal) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.subs == nil {
+		n.subs = make(map[chan<- os.Signal][]os.Signal)
+	}
+	n.subs[c] = append(n.subs[c], sigs...)
+}
+
+func (n *fakeNotifier) Stop(c chan<- os.Signal) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.subs, c)
+}
+
+// send delivers sig to the subscribed channels and reports whether any was subscribed.
+func (n *fakeNotifier) send(sig os.Signal) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := false
+	for c, sigs := range n.subs {
+		if slices.Contains(sigs, sig) {
+			c <- sig
+			sent = true
+		}
+	}
+	return sent
+}
+
+// TestWithNotifier verifies that signals come from the given Notifier, both for shutdown and
+// for callbacks, and that the Closer unsubscribes once shutdown starts.
+func TestWithNotifier(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term), WithNotifier(n))
+	reloaded := make(chan os.Signal, 1)
+	c.OnSignal(hup, func(sig os.Signal) { reloaded <- sig })
+
+	if !n.send(hup) || <-reloaded != hup {
+		t.Error("expected callback to receive hup")
+	}
+	if !n.send(term) {
+		t.Fatal("expected term to be subscribed")
+	}
+	c.Wait()
+	if r := c.Reason(); r.Signal != term {
+		t.Errorf("expected shutdown on term, got %v", r)
+	}
+	if n.send(term) {
+		t.Error("expected notifier to be stopped after shutdown")
+	}
+}
+
+// TestCallbackSignalsDuringShutdown verifies that once shutdown starts, shutdown signals are
+// released while signals with callbacks stay subscribed, without running the callbacks, until
+// shutdown completes.
+func TestCallbackSignalsDuringShutdown(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term), WithNotifier(n))
+	reloaded := make(chan os.Signal, 1)
+	c.OnSignal(hup, func(sig os.Signal) { reloaded <- sig })
+	running, release := make(chan struct{}), make(chan struct{})
+	c.Add(func() error {
+		close(running)
+		<-release
+		return nil
+	})
+
+	if !n.send(term) {
+		t.Fatal("expected term to be subscribed")
+	}
+	<-running
+	if n.send(term) {
+		t.Error("expected term to be released during shutdown")
+	}
+	if !n.send(hup) {
+		t.Error("expected hup to stay subscribed during shutdown")
+	}
+	close(release)
+	c.Wait()
+	select {
+	case <-reloaded:
+		t.Error("expected no callback during shutdown")
+	default:
+	}
+	if n.send(hup) {
+		t.Error("expected hup to be released after shutdown")
+	}
+}
+
+// TestNotifyIgnore verifies that Notify and Ignore change the signals triggering shutdown
+// after construction, and that ignored signals with callbacks stay subscribed.
+func TestNotifyIgnore(t *testing.T) {
+	term, intr, hup := testSignal("term"), testSignal("int"), testSignal("hup")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term), WithNotifier(n))
+	reloaded := make(chan os.Signal, 1)
+	c.OnSignal(hup, func(sig os.Signal) { reloaded <- sig })
+
+	c.Notify(intr, hup)
+	c.Ignore(term, hup)
+	if n.send(term) {
+		t.Error("expected term to be unsubscribed")
+	}
+	if !n.send(hup) || <-reloaded != hup {
+		t.Error("expected callback to receive hup")
+	}
+	if c.IsClosing() {
+		t.Fatal("expected hup not to trigger shutdown once ignored")
+	}
+	if !n.send(intr) {
+		t.Fatal("expected int to be subscribed")
+	}
+	c.Wait()
+	if r := c.Reason(); r.Signal != intr {
+		t.Errorf("expected shutdown on int, got %v", r)
+	}
+}
+
+// TestNotifyWithoutSignals verifies that Notify starts watching signals on a Closer created
+// without any.
+func TestNotifyWithoutSignals(t *testing.T) {
+	term := testSignal("term")
+	n := &fakeNotifier{}
+	c := New(WithNotifier(n))
+	c.Ignore(term)
+	c.Notify(term)
+	if !n.send(term) {
+		t.Fatal("expected term to be subscribed")
+	}
+	c.Wait()
+	if r := c.Reason(); r.Signal != term {
+		t.Errorf("expected shutdown on term, got %v", r)
+	}
+}
+
+// TestOnShutdownSignal verifies that shutdown signal subscribers are called, after the
+// callbacks of the signal, before the shutdown starts, and not for other signals.
+func TestOnShutdownSignal(t *testing.T) {
+	term, hup := testSignal("term"), testSignal("hup")
+	n := &fakeNotifier{}
+	c := New(WithSignals(term), WithNotifier(n))
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	c.OnShutdownSignal(func(sig os.Signal) {
+		select {
+		case <-c.Done():
+			record("subscriber after shutdown started")
+		default:
+			record("subscriber " + sig.String())
+		}
+	})
+	c.OnShutdownSignal(func(sig os.Signal) { record("second subscriber " + sig.String()) })
+	c.OnSignal(term, func(sig os.Signal) { record("callback " + sig.String()) })
+	c.OnSignal(hup, func(sig os.Signal) { record("callback " + sig.String()) })
+	c.Add(func() error {
+		record("closed")
+		return nil
+	})
+
+	n.send(hup)
+	n.send(term)
+	c.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"callback hup", "callback term", "subscriber term", "second subscriber term", "closed"}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
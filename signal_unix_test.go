@@ -0,0 +1,71 @@
+//go:build unix
+
+package closer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestSecondSignalKillsStuckShutdown runs a helper process whose shutdown hangs and verifies
+// that a second SIGTERM terminates it with the default disposition of the signal.
+func TestSecondSignalKillsStuckShutdown(t *testing.T) {
+	if os.Getenv("CLOSER_STUCK_HELPER") == "1" {
+		c := New(WithSignals(syscall.SIGTERM))
+		c.Add(func() error {
+			fmt.Println("closing")
+			select {}
+		})
+		fmt.Println("ready")
+		c.Wait()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSecondSignalKillsStuckShutdown$")
+	cmd.Env = append(os.Environ(), "CLOSER_STUCK_HELPER=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	lines := bufio.NewScanner(stdout)
+	expectLine := func(expected string) {
+		t.Helper()
+		if !lines.Scan() || lines.Text() != expected {
+			cmd.Process.Kill()
+			t.Fatalf("expected %q from helper, got %q", expected, lines.Text())
+		}
+	}
+
+	expectLine("ready")
+	cmd.Process.Signal(syscall.SIGTERM)
+	expectLine("closing")
+	// Give the watcher time to release the signal after triggering the shutdown.
+	time.Sleep(50 * time.Millisecond)
+	cmd.Process.Signal(syscall.SIGTERM)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("expected helper to be killed, got %v", err)
+		}
+		status := exitErr.Sys().(syscall.WaitStatus)
+		if !status.Signaled() || status.Signal() != syscall.SIGTERM {
+			t.Errorf("expected helper killed by SIGTERM, got %v", status)
+		}
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("expected second SIGTERM to kill the stuck helper")
+	}
+}
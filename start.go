@@ -0,0 +1,56 @@
+package closer
+
+import (
+	"context"
+	"errors"
+)
+
+// component is a part of the application started with Start.
+type component struct {
+	handle  *Handle
+	cleanup closeFunc
+}
+
+// Start starts a component of the application with fn, which receives the Context of c and
+// returns the function that stops the component. On success, that cleanup is registered,
+// configured by opts, to run on shutdown; fn may return a nil cleanup if there is nothing to
+// stop. If fn fails, the cleanups of the components started before with Start are run at
+// once, in reverse order, and unregistered, so that a failed boot does not leave half of the
+// application running; Start then returns the error of fn joined with those of the cleanups.
+// Once shutdown has started, Start returns ErrClosed without calling fn.
+//
+// Example:
+//
+//	err := c.Start(func(ctx context.Context) (func() error, error) {
+//		db, err := sql.Open("postgres", dsn)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return db.Close, db.PingContext(ctx)
+//	})
+func (c *Closer) Start(fn func(ctx context.Context) (cleanup func() error, err error), opts ...Option) error {
+	c.startMu.Lock()
+	defer c.startMu.Unlock()
+	if c.IsClosing() {
+		return ErrClosed
+	}
+	cleanup, err := fn(c.Context())
+	if err != nil {
+		errs := []error{err}
+		if cleanup != nil {
+			// The component may be partially started.
+			errs = append(errs, cleanup())
+		}
+		for i := len(c.components) - 1; i >= 0; i-- {
+			if s := c.components[i]; s.handle.Remove() {
+				errs = append(errs, s.cleanup())
+			}
+		}
+		c.components = nil
+		return errors.Join(errs...)
+	}
+	if cleanup != nil {
+		c.components = append(c.components, component{c.AddRemovable(cleanup, opts...), cleanup})
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestStart verifies that a failed start rolls back the components started before it in
+// reverse order, and that successful starts are stopped on shutdown.
+func TestStart(t *testing.T) {
+	c := New()
+	var stopped []string
+	component := func(name string, err error) func(context.Context) (func() error, error) {
+		return func(context.Context) (func() error, error) {
+			if err != nil {
+				return nil, err
+			}
+			return func() error {
+				stopped = append(stopped, name)
+				return nil
+			}, nil
+		}
+	}
+
+	c.Start(component("db", nil))
+	c.Start(component("cache", nil))
+	boom := errors.New("boom")
+	if err := c.Start(component("http", boom)); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if !slices.Equal(stopped, []string{"cache", "db"}) {
+		t.Errorf("expected rollback in reverse order, got %v", stopped)
+	}
+	if n := c.Len(); n != 0 {
+		t.Errorf("expected rolled back cleanups to be unregistered, got %d functions", n)
+	}
+
+	stopped = nil
+	c.Start(component("queue", nil))
+	c.CloseAll()
+	if !slices.Equal(stopped, []string{"queue"}) {
+		t.Errorf("expected the started component to be stopped on shutdown, got %v", stopped)
+	}
+	if err := c.Start(component("late", nil)); err != ErrClosed {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+}
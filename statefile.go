@@ -0,0 +1,287 @@
+package closer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ErrInterrupted is reported by OnDirtyStart for the functions of the previous shutdown that
+// had not finished when the process ended.
+var ErrInterrupted = errors.New("closer: shutdown interrupted")
+
+// WithStateFile makes New create a Closer that records the progress of its shutdown in the
+// file at path, for forensics after the process is killed in the middle of it, for example
+// by SIGKILL once the grace period of an orchestrator has passed. The file is truncated when
+// shutdown starts and receives a line of JSON with every function to run, then one for each
+// function that finished or failed as it does, and a last one once the shutdown has
+// completed. Each line is written straight to the file, so it survives the process.
+//
+// When the file is left without the last line by the previous process, New logs a Warn
+// "previous shutdown interrupted" event with the "reason" and "started" time of that
+// shutdown, and the functions that had not finished, likely leaving their resources dirty,
+// as "unfinished", and those that had failed as "failed"; OnDirtyStart lets the application
+// recover from it. Failing to read or write the file is logged as an Error event without
+// affecting the shutdown.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithStateFile("/var/lib/app/shutdown.state"))
+func WithStateFile(path string) Option {
+	return func(o *options) {
+		o.stateFile = path
+	}
+}
+
+// OnDirtyStart calls fn with the report of the previous shutdown if it was not clean, either
+// because the process ended before it completed or because required functions failed, as
+// recorded in the file given to WithStateFile, so that the application can recover: release
+// stale locks, remove temporary files or raise an alert. It does nothing if WithStateFile
+// was not given or the previous shutdown was clean. fn is called at once, so hooks are best
+// registered right after New, before components are started.
+//
+// The report is rebuilt from the state file: errors keep their message only, and functions
+// that had not finished are reported with ErrInterrupted.
+//
+// Example:
+//
+//	c.OnDirtyStart(func(prev closer.Report) {
+//		for _, f := range prev.Funcs {
+//			if f.Name == "lock" && f.Err != nil {
+//				lock.ForceRelease()
+//			}
+//		}
+//	})
+func (c *Closer) OnDirtyStart(fn func(prev Report)) {
+	mustNotBeNil(fn, "hook")
+	if c.dirty != nil {
+		fn(*c.dirty)
+	}
+}
+
+// Events of the lines of a state file.
+const (
+	stateStarted   = "started"
+	stateFinished  = "finished"
+	stateFailed    = "failed"
+	stateCompleted = "completed"
+)
+
+// stateLine is a line of a state file.
+type stateLine struct {
+	Event  string     `json:"event"`
+	Time   time.Time  `json:"time"`
+	Reason string     `json:"reason,omitempty"` // for stateStarted
+	Kind   ReasonKind `json:"kind,omitempty"`   // kind of the reason, for stateStarted
+	Signal int        `json:"signal,omitempty"` // number of the signal of the reason, for stateStarted
+	Err    string     `json:"error,omitempty"`  // error of the reason, for stateStarted
+	Funcs  []funcJSON `json:"funcs,omitempty"`  // functions to run, for stateStarted
+	Func   *funcJSON  `json:"func,omitempty"`   // for stateFinished and stateFailed
+}
+
+// stateFile records the progress of a shutdown as described in WithStateFile. Its methods
+// do nothing on a nil stateFile, and once writing has failed.
+type stateFile struct {
+	f     *os.File
+	enc   *json.Encoder
+	l     Logger
+	clock Clock // source of the times of the lines
+}
+
+// openStateFile truncates the state file at path and records the start of a shutdown
+// initiated by reason, running funcs, at the time of clock. It returns nil, having logged the
+// error to l, if the file cannot be written.
+func openStateFile(path string, l Logger, clock Clock, reason Reason, funcs []entry) *stateFile {
+	f, err := os.Create(path)
+	if err != nil {
+		l.Error("state file not written", "path", path, "error", err)
+		return nil
+	}
+	s := &stateFile{f: f, enc: json.NewEncoder(f), l: l, clock: clock}
+	line := stateLine{Event: stateStarted, Time: clock.Now(), Reason: reason.String(), Kind: reason.Kind, Funcs: make([]funcJSON, len(funcs))}
+	if sig, ok := reason.Signal.(syscall.Signal); ok {
+		line.Signal = int(sig)
+	}
+	if reason.Err != nil {
+		line.Err = reason.Err.Error()
+	}
+	for i, e := range funcs {
+		line.Funcs[i] = funcJSON{Index: e.index, Name: e.name, Label: e.label, Status: "pending", Optional: e.optional}
+	}
+	s.write(line)
+	return s
+}
+
+// mark records the outcome of the function of r.
+func (s *stateFile) mark(r result) {
+	if s == nil {
+		return
+	}
+	f := r.report()
+	line := stateLine{Event: stateFinished, Time: s.clock.Now(), Func: &funcJSON{
+		Index:    f.Index,
+		Name:     f.Name,
+		Label:    f.Label,
+		Status:   f.status(),
+		Duration: f.Duration.Seconds(),
+		Attempts: f.Attempts,
+		Optional: f.Optional,
+	}}
+	if f.Err != nil {
+		line.Event, line.Func.Err = stateFailed, f.Err.Error()
+	}
+	s.write(line)
+}
+
+// complete records the completion of the shutdown and closes the file.
+func (s *stateFile) complete() {
+	if s == nil {
+		return
+	}
+	s.write(stateLine{Event: stateCompleted, Time: s.clock.Now()})
+	if s.f != nil {
+		if err := s.f.Close(); err != nil {
+			s.l.Error("state file not written", "path", s.f.Name(), "error", err)
+		}
+	}
+}
+
+// write appends line to the file, closing it if that fails.
+func (s *stateFile) write(line stateLine) {
+	if s.f == nil {
+		return
+	}
+	if err := s.enc.Encode(line); err != nil {
+		s.l.Error("state file not written", "path", s.f.Name(), "error", err)
+		s.f.Close()
+		s.f = nil
+	}
+}
+
+// readStateFile reads the state file at path and returns the report of the shutdown it
+// records, as described in OnDirtyStart, and whether that shutdown completed. It returns
+// a nil report if there is no state file.
+func readStateFile(path string) (prev *Report, completed bool, err error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	outcomes := make(map[int]FuncReport)
+	var last time.Time
+	lines := bufio.NewScanner(f)
+	lines.Buffer(nil, 64<<20)
+	for lines.Scan() {
+		var line stateLine
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			// The process may have been killed while writing the line.
+			break
+		}
+		last = line.Time
+		switch line.Event {
+		case stateStarted:
+			prev = &Report{Reason: line.reason(), Start: line.Time, Funcs: make([]FuncReport, len(line.Funcs))}
+			for i, f := range line.Funcs {
+				prev.Funcs[i] = f.report(time.Time{}, ErrInterrupted)
+			}
+		case stateFinished, stateFailed:
+			if line.Func != nil {
+				var err error
+				if line.Func.Err != "" {
+					err = errors.New(line.Func.Err)
+				}
+				outcomes[line.Func.Index] = line.Func.report(line.Time, err)
+			}
+		case stateCompleted:
+			completed = true
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, false, err
+	}
+	if prev == nil {
+		return nil, false, nil
+	}
+	prev.Duration = last.Sub(prev.Start)
+	for i, f := range prev.Funcs {
+		if outcome, ok := outcomes[f.Index]; ok {
+			prev.Funcs[i] = outcome
+		}
+	}
+	return prev, completed, nil
+}
+
+// reason returns the reason recorded by a stateStarted line.
+func (line stateLine) reason() Reason {
+	r := Reason{Kind: line.Kind}
+	if line.Signal != 0 {
+		r.Signal = syscall.Signal(line.Signal)
+	}
+	if line.Err != "" {
+		r.Err = errors.New(line.Err)
+	}
+	return r
+}
+
+// report returns the FuncReport of a function recorded in a state file with err when its
+// line was written at end, zero if it has no outcome.
+func (f funcJSON) report(end time.Time, err error) FuncReport {
+	r := FuncReport{
+		Index:    f.Index,
+		Name:     f.Name,
+		Label:    f.Label,
+		Duration: time.Duration(f.Duration * float64(time.Second)),
+		Err:      err,
+		TimedOut: f.Status == "timeout",
+		Panicked: f.Status == "panicked",
+		Skipped:  f.Status == "skipped",
+		Attempts: f.Attempts,
+		Optional: f.Optional,
+	}
+	if !end.IsZero() && f.Attempts > 0 {
+		r.Start = end.Add(-r.Duration)
+	}
+	return r
+}
+
+// checkStateFile reads the shutdown recorded in the state file at path, logging it if it
+// was interrupted, and keeps it for OnDirtyStart if it was not clean.
+func (c *Closer) checkStateFile(path string) {
+	prev, completed, err := readStateFile(path)
+	if err != nil {
+		c.log().Error("state file not read", "path", path, "error", err)
+		return
+	}
+	if prev == nil {
+		return
+	}
+	var unfinished, failed []string
+	for _, f := range prev.Funcs {
+		switch {
+		case errors.Is(f.Err, ErrInterrupted):
+			unfinished = append(unfinished, f.describe())
+		case f.Err != nil && !f.Optional:
+			failed = append(failed, f.describe())
+		}
+	}
+	if !completed {
+		warn(c.log(), "previous shutdown interrupted", "path", path, "reason", prev.Reason.String(),
+			"started", prev.Start, "unfinished", unfinished, "failed", failed)
+	}
+	if !completed || len(failed) > 0 {
+		c.dirty = prev
+	}
+}
+
+// describe describes f as the String method of its entry does.
+func (f FuncReport) describe() string {
+	return entry{index: f.Index, name: f.Name, label: f.Label}.String()
+}
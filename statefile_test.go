@@ -0,0 +1,127 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestWithStateFile verifies that a shutdown interrupted midway is reported by the next
+// Closer using the same state file, and that a completed one is not.
+func TestWithStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown.state")
+	c := New(WithStateFile(path))
+	release := make(chan struct{})
+	c.AddNamed("db", func() error { return errors.New("broken pipe") })
+	c.AddNamed("cache", func() error { return nil })
+	c.AddNamed("queue", func() error { <-release; return nil })
+	go c.CloseAll()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if bytes.Count(data, []byte("\n")) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 lines in the state file, got %q", data)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	l := &warnLogger{}
+	New(WithStateFile(path), WithLogger(l))
+	want := "WARN previous shutdown interrupted path=" + path + " reason=CloseAll call started="
+	if len(l.events) != 1 || !strings.HasPrefix(l.events[0], want) {
+		t.Fatalf("expected %q, got %v", want, l.events)
+	}
+	if want := "unfinished=[queue #2] failed=[db #0]"; !strings.HasSuffix(l.events[0], want) {
+		t.Errorf("expected event to end with %q, got %q", want, l.events[0])
+	}
+
+	close(release)
+	c.Wait()
+	l = &warnLogger{}
+	New(WithStateFile(path), WithLogger(l))
+	if len(l.events) != 0 {
+		t.Errorf("expected no events after a completed shutdown, got %v", l.events)
+	}
+}
+
+// TestWithStateFileNotWritten verifies that failing to write the state file is logged
+// without affecting the shutdown.
+func TestWithStateFileNotWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "shutdown.state")
+	l := &recordLogger{}
+	c := New(WithStateFile(path), WithLogger(l))
+	c.Add(func() error { return nil })
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "ERROR state file not written path=" + path + " error="
+	found := false
+	for _, e := range l.events {
+		found = found || strings.HasPrefix(e, want)
+	}
+	if !found {
+		t.Errorf("expected %q, got %v", want, l.events)
+	}
+}
+
+// TestOnDirtyStart verifies that the hooks receive the report of a previous shutdown that
+// failed, with its errors and reason, and are not called after a clean one.
+func TestOnDirtyStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown.state")
+	c := New(WithStateFile(path))
+	c.AddNamed("lock", func() error { return errors.New("lock held") })
+	c.AddNamed("cache", func() error { return nil })
+	c.closeAll(context.Background(), Reason{Kind: ReasonSignal, Signal: syscall.SIGTERM})
+
+	var prev *Report
+	New(WithStateFile(path)).OnDirtyStart(func(r Report) { prev = &r })
+	if prev == nil {
+		t.Fatal("expected hook to be called")
+	}
+	if prev.Reason.Kind != ReasonSignal || prev.Reason.Signal != syscall.SIGTERM {
+		t.Errorf("expected reason %v, got %v", Reason{Kind: ReasonSignal, Signal: syscall.SIGTERM}, prev.Reason)
+	}
+	if len(prev.Funcs) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(prev.Funcs))
+	}
+	if f := prev.Funcs[0]; f.Name != "lock" || f.Err == nil || f.Err.Error() != "lock held" {
+		t.Errorf("expected lock to have failed, got %+v", f)
+	}
+	if f := prev.Funcs[1]; f.Name != "cache" || f.Err != nil || f.Attempts != 1 {
+		t.Errorf("expected cache to have succeeded, got %+v", f)
+	}
+
+	c = New(WithStateFile(path))
+	c.Add(func() error { return nil })
+	c.CloseAll()
+	New(WithStateFile(path)).OnDirtyStart(func(Report) { t.Error("expected hook not to be called after a clean shutdown") })
+}
+
+// TestOnDirtyStartInterrupted verifies that functions of an interrupted shutdown that had not
+// finished are reported with ErrInterrupted.
+func TestOnDirtyStartInterrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown.state")
+	c := New()
+	c.AddNamed("queue", func() error { return nil })
+	s := openStateFile(path, c.log(), c.clock, Reason{Kind: ReasonCall}, c.funcs.snapshot(false))
+	s.f.Close()
+
+	var prev *Report
+	New(WithStateFile(path), WithLogger(&recordLogger{})).OnDirtyStart(func(r Report) { prev = &r })
+	if prev == nil || len(prev.Funcs) != 1 {
+		t.Fatalf("expected a report with 1 function, got %+v", prev)
+	}
+	if err := prev.Funcs[0].Err; !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected %v, got %v", ErrInterrupted, err)
+	}
+}
@@ -0,0 +1,50 @@
+package closer
+
+import "fmt"
+
+// errorsBuffer is the capacity of the channel returned by Errors.
+const errorsBuffer = 64
+
+// Errors returns a channel receiving the errors of closing functions and tasks as they occur
+// during shutdown, each prefixed like in the error returned by CloseAll, so that they can be
+// forwarded, for example to an error tracker, before the shutdown completes. The channel is
+// closed once the shutdown has completed. Sending never blocks the shutdown: errors occurring
+// while the channel holds 64 unreceived errors are left out of the stream, though they are
+// still returned by CloseAll. Every call returns the same channel; to receive all errors, call
+// Errors before shutdown starts.
+//
+// Example:
+//
+//	go func() {
+//		for err := range c.Errors() {
+//			sentry.CaptureException(err)
+//		}
+//	}()
+func (c *Closer) Errors() <-chan error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.errs == nil {
+		c.errs = make(chan error, errorsBuffer)
+		if c.closed {
+			close(c.errs)
+		}
+	}
+	return c.errs
+}
+
+// stream sends err to ch without blocking, dropping it if ch is full. It does nothing if ch
+// is nil.
+func stream(ch chan<- error, err error) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- err:
+	default:
+	}
+}
+
+// prefixed returns the error of r prefixed with the identifier of its function.
+func (r result) prefixed() error {
+	return fmt.Errorf("%s: %w", r.entry, r.err)
+}
@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestErrors verifies that errors are streamed while the shutdown is still running and that
+// the channel is closed once it completes.
+func TestErrors(t *testing.T) {
+	c := New()
+	errs := c.Errors()
+	if c.Errors() != errs {
+		t.Error("expected the same channel on every call")
+	}
+	unblock := make(chan struct{})
+	c.AddNamed("db", func() error { return errors.New("boom") }, WithPriority(1))
+	c.Add(func() error {
+		<-unblock
+		return nil
+	})
+	go c.CloseAll()
+
+	if err := <-errs; err == nil || err.Error() != "db #0: boom" {
+		t.Errorf("expected db #0: boom, got %v", err)
+	}
+	close(unblock)
+	if err, ok := <-errs; ok {
+		t.Errorf("expected channel to be closed, got %v", err)
+	}
+}
+
+// TestErrorsFull verifies that a full channel does not block the shutdown and that a channel
+// requested after shutdown is closed.
+func TestErrorsFull(t *testing.T) {
+	c := New()
+	errs := c.Errors()
+	for range errorsBuffer + 1 {
+		c.Add(func() error { return errors.New("boom") })
+	}
+	if err := c.CloseAll(); strings.Count(err.Error(), "boom") != errorsBuffer+1 {
+		t.Errorf("expected all errors to be returned, got %v", err)
+	}
+
+	n := 0
+	for range errs {
+		n++
+	}
+	if n != errorsBuffer {
+		t.Errorf("expected %d streamed errors, got %d", errorsBuffer, n)
+	}
+
+	c = New()
+	c.CloseAll()
+	if _, ok := <-c.Errors(); ok {
+		t.Error("expected channel requested after shutdown to be closed")
+	}
+}
@@ -0,0 +1,339 @@
+package closer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"weak"
+)
+
+// supervisorEnv is the environment variable through which Supervise hands the child process
+// the descriptors of its pipes, as "control,status".
+const supervisorEnv = "CLOSER_SUPERVISOR_FDS"
+
+// ErrSupervisorLost is the error of the Reason of a shutdown initiated because the process
+// supervising this one with Supervise exited without asking for it.
+var ErrSupervisorLost = errors.New("closer: supervisor lost")
+
+// Lines of the supervision protocol.
+const (
+	supervisorShutdown  = "shutdown"  // from the supervisor: shut down
+	supervisorFinished  = "finished"  // from the child: a function finished
+	supervisorFailed    = "failed"    // from the child: a function failed
+	supervisorCompleted = "completed" // from the child: the shutdown completed, with its report
+)
+
+// supervisorLine is a line of JSON exchanged between a supervisor and a child process.
+type supervisorLine struct {
+	Event  string      `json:"event"`
+	Func   *funcJSON   `json:"func,omitempty"`   // for supervisorFinished and supervisorFailed
+	Report *reportJSON `json:"report,omitempty"` // for supervisorCompleted
+	Kind   ReasonKind  `json:"kind,omitempty"`   // kind of the reason, for supervisorCompleted
+	Signal int         `json:"signal,omitempty"` // number of the signal of the reason, for supervisorCompleted
+	Err    string      `json:"error,omitempty"`  // error of the reason, for supervisorCompleted
+}
+
+// WithSupervisor makes New create a Closer that is supervised by the process that started
+// this one with Supervise: it shuts down when the supervisor asks for it, reported with
+// ReasonSupervisor, or when the supervisor exits first, reported with ReasonSupervisor and
+// ErrSupervisorLost, and it streams the outcome of its functions and its Report back. It does
+// nothing if this process was not started by Supervise. Only one Closer of a process can be
+// supervised.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithSupervisor())
+func WithSupervisor() Option {
+	return func(o *options) {
+		o.supervised = true
+	}
+}
+
+// Supervised is a child process started by Supervise.
+type Supervised struct {
+	cmd    *exec.Cmd
+	exited chan struct{} // closed once the process has exited
+	err    error         // returned by cmd.Wait, set before exited is closed
+	done   chan struct{} // closed once the report has been read, or the status pipe to its end
+
+	mu     sync.Mutex
+	report *Report // report streamed back by the process, nil until its shutdown completed
+}
+
+// Supervise starts cmd, whose program creates its Closer with WithSupervisor, and registers
+// its shutdown as a closing function of c named after the executable, for process-per-core or
+// plugin-host architectures. When c shuts down, the child process is asked to shut down and
+// waited for, so that c's Wait only returns once every supervised child has confirmed the
+// completion of its shutdown and exited. The outcome of the functions of the child is streamed
+// back as they finish and logged as Debug "child closer finished" and Error "child closer
+// failed" events with the "pid" of the child; its failures are returned by the closing
+// function. A child that has not exited by the shutdown deadline is killed, reported with an
+// error wrapping ErrTimeout; one that exits without confirming its shutdown is reported with
+// its exit status. The child shuts down by itself if this process exits first.
+//
+// opts configure the registration in c, as if passed to AddNamed. cmd must not have been
+// started; the caller must not call cmd.Wait itself. Supervise is not supported on Windows.
+//
+// Example:
+//
+//	for i := range runtime.NumCPU() {
+//		cmd := exec.Command(os.Args[0], "-worker", strconv.Itoa(i))
+//		if _, err := c.Supervise(cmd); err != nil {
+//			return err
+//		}
+//	}
+func (c *Closer) Supervise(cmd *exec.Cmd, opts ...Option) (*Supervised, error) {
+	mustNotBeNil(cmd, "*exec.Cmd")
+	control, ctl, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("closer: supervise: %w", err)
+	}
+	defer control.Close()
+	status, st, err := os.Pipe()
+	if err != nil {
+		ctl.Close()
+		return nil, fmt.Errorf("closer: supervise: %w", err)
+	}
+	defer st.Close()
+
+	// Descriptors 0 to 2 are the standard streams, ExtraFiles follow in order.
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, control, st)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, supervisorEnv+"="+strconv.Itoa(fd)+","+strconv.Itoa(fd+1))
+	if err := cmd.Start(); err != nil {
+		ctl.Close()
+		status.Close()
+		return nil, fmt.Errorf("closer: supervise: %w", err)
+	}
+
+	s := &Supervised{cmd: cmd, exited: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		s.err = cmd.Wait()
+		close(s.exited)
+	}()
+	go s.read(status, c.log())
+	c.addContext(append([]Option{withName(filepath.Base(cmd.Path))}, opts...), func(ctx context.Context) error {
+		defer ctl.Close()
+		// The child may have exited already: its failure is reported below.
+		json.NewEncoder(ctl).Encode(supervisorLine{Event: supervisorShutdown})
+		select {
+		case <-s.exited:
+		case <-ctx.Done():
+			cmd.Process.Kill()
+			<-s.exited
+			return fmt.Errorf("child %d exit %w: %w, killed", s.Pid(), ErrTimeout, context.Cause(ctx))
+		}
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+		}
+		return s.result()
+	})
+	return s, nil
+}
+
+// Pid returns the process ID of s.
+func (s *Supervised) Pid() int {
+	return s.cmd.Process.Pid
+}
+
+// Report returns the report of the shutdown of s, as streamed back once it completed, and
+// reports false until then. The report is rebuilt from JSON: errors keep their message only.
+func (s *Supervised) Report() (Report, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.report == nil {
+		return Report{}, false
+	}
+	return *s.report, true
+}
+
+// result returns the error of the shutdown of s, once it has exited.
+func (s *Supervised) result() error {
+	r, ok := s.Report()
+	if !ok {
+		if s.err == nil {
+			return fmt.Errorf("child %d exited without confirming its shutdown", s.Pid())
+		}
+		return fmt.Errorf("child %d exited without confirming its shutdown: %w", s.Pid(), s.err)
+	}
+	var errs []error
+	for _, f := range r.Funcs {
+		if f.Err != nil && !f.Optional {
+			errs = append(errs, fmt.Errorf("%s: %w", f.describe(), f.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("child %d: %w", s.Pid(), errors.Join(errs...))
+	}
+	return s.err
+}
+
+// read reads the lines streamed back by the child on status until its report, logging the
+// outcome of its functions to l and keeping the report. The outcome of functions whose line
+// was not streamed before the report, as the child forwards them asynchronously, is logged
+// from the report.
+func (s *Supervised) read(status *os.File, l Logger) {
+	defer close(s.done)
+	defer status.Close()
+	lines := bufio.NewScanner(status)
+	lines.Buffer(nil, 64<<20)
+	logged := make(map[int]bool)
+	for lines.Scan() {
+		var line supervisorLine
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			continue
+		}
+		switch line.Event {
+		case supervisorFinished, supervisorFailed:
+			if line.Func == nil {
+				continue
+			}
+			logged[line.Func.Index] = true
+			s.logFunc(l, *line.Func)
+		case supervisorCompleted:
+			if line.Report == nil {
+				continue
+			}
+			for _, f := range line.Report.Funcs {
+				if !logged[f.Index] && (f.Err != "" || f.Status == "ok") {
+					s.logFunc(l, f)
+				}
+			}
+			r := line.Report.report(stateLine{Kind: line.Kind, Signal: line.Signal, Err: line.Err}.reason())
+			s.mu.Lock()
+			s.report = &r
+			s.mu.Unlock()
+			// Descendants of the child may hold the pipe open: do not wait for its end.
+			return
+		}
+	}
+}
+
+// logFunc logs the outcome of the function f of s to l.
+func (s *Supervised) logFunc(l Logger, f funcJSON) {
+	r := f.report(time.Time{}, nil)
+	if f.Err != "" {
+		l.Error("child closer failed", "pid", s.Pid(), "closer", r.describe(), "error", f.Err)
+	} else {
+		l.Debug("child closer finished", "pid", s.Pid(), "closer", r.describe(), "duration", r.Duration)
+	}
+}
+
+// report returns the Report that out is the JSON form of, with reason.
+func (out reportJSON) report(reason Reason) Report {
+	r := Report{
+		Reason:   reason,
+		Start:    out.Start,
+		Duration: time.Duration(out.Duration * float64(time.Second)),
+		Extended: time.Duration(out.Extended * float64(time.Second)),
+	}
+	for _, f := range out.Funcs {
+		var err error
+		if f.Err != "" {
+			err = errors.New(f.Err)
+		}
+		r.Funcs = append(r.Funcs, f.report(time.Time{}, err))
+	}
+	return r
+}
+
+// superviseBy connects c to the supervisor that started this process with Supervise, if
+// any, as described in WithSupervisor.
+func (c *Closer) superviseBy() {
+	fds := strings.Split(os.Getenv(supervisorEnv), ",")
+	os.Unsetenv(supervisorEnv)
+	if len(fds) != 2 {
+		return
+	}
+	controlFD, err1 := strconv.Atoi(fds[0])
+	statusFD, err2 := strconv.Atoi(fds[1])
+	if err1 != nil || err2 != nil {
+		return
+	}
+	control := os.NewFile(uintptr(controlFD), "closer-control")
+	status := os.NewFile(uintptr(statusFD), "closer-status")
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(status)
+	send := func(line supervisorLine, last bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if enc == nil {
+			return
+		}
+		if err := enc.Encode(line); err != nil || last {
+			status.Close()
+			enc = nil
+		}
+	}
+	events := c.Subscribe()
+	go func() {
+		for ev := range events {
+			switch ev.Kind {
+			case FuncFinished, FuncFailed:
+				f := ev.Func
+				line := supervisorLine{Event: supervisorFinished, Func: &funcJSON{
+					Index:    f.Index,
+					Name:     f.Name,
+					Label:    f.Label,
+					Status:   f.status(),
+					Duration: f.Duration.Seconds(),
+					Attempts: f.Attempts,
+					Optional: f.Optional,
+				}}
+				if f.Err != nil {
+					line.Event, line.Func.Err = supervisorFailed, f.Err.Error()
+				}
+				send(line, false)
+			}
+		}
+	}()
+	c.OnShutdownEnd(func(r Report) {
+		out := r.json()
+		line := supervisorLine{Event: supervisorCompleted, Report: &out, Kind: r.Reason.Kind}
+		if sig, ok := r.Reason.Signal.(syscall.Signal); ok {
+			line.Signal = int(sig)
+		}
+		if r.Reason.Err != nil {
+			line.Err = r.Reason.Err.Error()
+		}
+		send(line, true)
+	})
+
+	ref := weak.Make(c)
+	stop := context.AfterFunc(c.Context(), func() { control.Close() })
+	go func() {
+		defer stop()
+		defer control.Close()
+		lines := bufio.NewScanner(control)
+		reason := Reason{Kind: ReasonSupervisor, Err: ErrSupervisorLost}
+		for lines.Scan() {
+			var line supervisorLine
+			if json.Unmarshal(lines.Bytes(), &line) == nil && line.Event == supervisorShutdown {
+				reason.Err = nil
+				break
+			}
+		}
+		if lines.Err() != nil {
+			// The Closer closed the pipe as its shutdown started otherwise.
+			return
+		}
+		if c := ref.Value(); c != nil {
+			c.closeAll(context.Background(), reason)
+		}
+	}()
+}
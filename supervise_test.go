@@ -0,0 +1,122 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// superviseChildEnv marks the helper process started by the supervision tests.
+const superviseChildEnv = "CLOSER_TEST_SUPERVISED_CHILD"
+
+// superviseHelper returns a command starting the test binary running only
+// TestSuperviseHelper, with mode telling it how to behave.
+func superviseHelper(t *testing.T, mode string) *exec.Cmd {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("supervision is not supported on Windows")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSuperviseHelper$")
+	cmd.Env = append(os.Environ(), superviseChildEnv+"="+mode)
+	return cmd
+}
+
+// TestSuperviseHelper is the process started by the supervision tests. It shuts down when
+// asked to by its supervisor, checking the reason, with a failing function in "fail" mode, or
+// exits at once in "crash" mode.
+func TestSuperviseHelper(t *testing.T) {
+	mode := os.Getenv(superviseChildEnv)
+	if mode == "" {
+		return
+	}
+	if mode == "crash" {
+		os.Exit(3)
+	}
+	c := New(WithSupervisor())
+	c.AddNamed("reason", func() error { return nil })
+	c.AddContext(func(ctx context.Context) error {
+		if r, _ := ReasonFromContext(ctx); r.Kind != ReasonSupervisor || r.Err != nil {
+			return errors.New("unexpected reason " + r.String())
+		}
+		return nil
+	})
+	if mode == "fail" {
+		c.AddNamed("cache", func() error { return errors.New("cache not flushed") })
+	}
+	c.Wait()
+	os.Exit(0)
+}
+
+// TestSupervise verifies that the shutdown of the supervisor shuts the child down and waits
+// for it, and that the report of the child is streamed back.
+func TestSupervise(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	s, err := c.Supervise(superviseHelper(t, "ok"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Report(); ok {
+		t.Error("expected no report before the child shut down")
+	}
+
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	r, ok := s.Report()
+	if !ok {
+		t.Fatal("expected the report of the child")
+	}
+	if r.Reason.Kind != ReasonSupervisor || len(r.Funcs) != 2 || r.json().Failures != 0 {
+		t.Errorf("expected a clean report of 2 functions shut down by the supervisor, got %+v", r)
+	}
+	if len(l.events) == 0 {
+		t.Errorf("expected the functions of the child to be logged, got no events")
+	}
+}
+
+// TestSuperviseFailure verifies that the failures of the child are returned by the closing
+// function of the supervisor.
+func TestSuperviseFailure(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithLogger(l))
+	s, err := c.Supervise(superviseHelper(t, "fail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.CloseAll()
+	if err == nil || !strings.Contains(err.Error(), "cache not flushed") {
+		t.Errorf("expected the failure of the child, got %v", err)
+	}
+	found := false
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "ERROR child closer failed pid=") && strings.Contains(e, "error=cache not flushed") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the failure to be streamed back, got %v", l.events)
+	}
+	if r, ok := s.Report(); !ok || r.json().Failures != 1 {
+		t.Errorf("expected a report with a failure, got %+v", r)
+	}
+}
+
+// TestSuperviseCrash verifies that a child exiting without confirming its shutdown is
+// reported as a failure.
+func TestSuperviseCrash(t *testing.T) {
+	c := New()
+	if _, err := c.Supervise(superviseHelper(t, "crash")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.CloseAll()
+	if err == nil || !strings.Contains(err.Error(), "exited without confirming its shutdown: exit status 3") {
+		t.Errorf("expected the exit of the child, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package closer
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WithSystemd makes New create a Closer that reports to systemd, for services of Type=notify,
+// through the socket named by the NOTIFY_SOCKET environment variable. It sends STOPPING=1 as
+// soon as shutdown is initiated and, if extend is positive, EXTEND_TIMEOUT_USEC every half of
+// extend until the shutdown completes, so that systemd waits for closing functions that are
+// still making progress instead of killing the process at TimeoutStopSec. If the unit sets
+// WatchdogSec, it also sends WATCHDOG=1 keepalives at half the watchdog interval from New
+// until the shutdown completes. Outside systemd, WithSystemd has no effect.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithSystemd(30*time.Second))
+//	closer.NotifySystemd("READY=1")
+func WithSystemd(extend time.Duration) Option {
+	return func(o *options) {
+		o.systemd = true
+		o.systemdExtend = extend
+	}
+}
+
+// NotifySystemd sends state, such as "READY=1", to systemd through the socket named by the
+// NOTIFY_SOCKET environment variable. It does nothing and returns nil if the variable is unset.
+func NotifySystemd(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:] // abstract socket
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval returns the interval at which systemd expects keepalives from this
+// process, or zero if it expects none.
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// notifySystemd sends state to systemd, logging a failure.
+func (c *Closer) notifySystemd(state string) {
+	if err := NotifySystemd(state); err != nil {
+		c.log().Error("systemd notification failed", "state", state, "error", err)
+	}
+}
+
+// reportToSystemd sets up the notifications configured by WithSystemd.
+func (c *Closer) reportToSystemd(extend time.Duration) {
+	if os.Getenv("NOTIFY_SOCKET") == "" {
+		return
+	}
+	// Hooks of a shutdown run one after another on the same goroutine, so extending needs no
+	// synchronization, and the watchdog stops with the first shutdown.
+	watchdog, extending := make(chan struct{}), chan struct{}(nil)
+	var stopWatchdog sync.Once
+	if interval := watchdogInterval(); interval > 0 {
+		go c.notifyEvery(interval/2, "WATCHDOG=1", watchdog)
+	}
+	c.startHooks = append(c.startHooks, func(Reason) {
+		c.notifySystemd("STOPPING=1")
+		if extend > 0 {
+			state := "EXTEND_TIMEOUT_USEC=" + strconv.FormatInt(extend.Microseconds(), 10)
+			c.notifySystemd(state)
+			extending = make(chan struct{})
+			go c.notifyEvery(extend/2, state, extending)
+		}
+	})
+	c.endHooks = append(c.endHooks, func(Report) {
+		stopWatchdog.Do(func() { close(watchdog) })
+		if extending != nil {
+			close(extending)
+			extending = nil
+		}
+	})
+}
+
+// notifyEvery sends state to systemd every interval until done is closed.
+func (c *Closer) notifyEvery(interval time.Duration, state string, done <-chan struct{}) {
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.notifySystemd(state)
+		case <-done:
+			return
+		}
+	}
+}
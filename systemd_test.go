@@ -0,0 +1,70 @@
+package closer
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// listenSystemd points NOTIFY_SOCKET at a new datagram socket and returns it.
+func listenSystemd(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+// readStates reads the states sent to conn until it has been quiet for a while.
+func readStates(conn *net.UnixConn) map[string]int {
+	states := make(map[string]int)
+	buf := make([]byte, 256)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			return states
+		}
+		states[string(buf[:n])]++
+	}
+}
+
+// TestWithSystemd verifies that keepalives are sent while running, and that shutdown is
+// reported and its timeout extended while closing functions run.
+func TestWithSystemd(t *testing.T) {
+	conn := listenSystemd(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	c := New(WithSystemd(40 * time.Millisecond))
+	c.Add(func() error {
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	states := readStates(conn)
+	if states["WATCHDOG=1"] == 0 {
+		t.Errorf("expected watchdog keepalives, got %v", states)
+	}
+	if states["STOPPING=1"] != 1 {
+		t.Errorf("expected STOPPING=1 once, got %v", states)
+	}
+	if states["EXTEND_TIMEOUT_USEC=40000"] < 2 {
+		t.Errorf("expected the stop timeout to be extended repeatedly, got %v", states)
+	}
+}
+
+// TestNotifySystemdOutside verifies that notifying outside systemd does nothing.
+func TestNotifySystemdOutside(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := NotifySystemd("READY=1"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
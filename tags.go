@@ -0,0 +1,80 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// WithTags tags closing functions, so that CloseTagged can close them while the process keeps
+// running. Tags accumulate when WithTags is given more than once.
+//
+// Example:
+//
+//	c.AddNamed("sessions", sessions.Purge, closer.WithTags("cache"))
+//	c.OnSignal(syscall.SIGUSR1, func(os.Signal) { c.CloseTagged("cache") })
+func WithTags(tags ...string) Option {
+	return func(o *options) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// CloseTagged runs the closing functions registered with any of tags, in the order the
+// shutdown would run them and bounded by the timeout of the Closer, without initiating
+// shutdown, for example to drop caches on a signal. The functions are unregistered, so they
+// do not run again on shutdown. CloseTagged returns their errors joined in registration
+// order. Once shutdown has started, it returns ErrClosed without running anything.
+func (c *Closer) CloseTagged(tags ...string) error {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	var funcs []entry
+	for _, at := range c.untag(tags) {
+		// Functions with several of tags, or already removed, are not there anymore.
+		if e, ok := c.funcs.take(at.shard, at.index); ok {
+			funcs = append(funcs, e)
+		}
+	}
+	slices.SortFunc(funcs, func(a, b entry) int { return a.index - b.index })
+	steps := c.plan(funcs)
+	c.mu.Unlock()
+
+	c.log().Info("closing tagged", "tags", tags, "funcs", len(funcs))
+	ctx := withClock(context.Background(), c.clock)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	col := collector{ignored: c.ignored, results: make([]result, 0, len(funcs)), clock: c.clock}
+	execute(ctx, steps, c.limit, nil, &col)
+	return errors.Join(col.errors()...)
+}
+
+// tag records the n functions registered from index first in shard under tags.
+func (c *Closer) tag(tags []string, shard, first, n int) {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+	if c.tagged == nil {
+		c.tagged = make(map[string][]slot)
+	}
+	for _, tag := range tags {
+		for i := range n {
+			c.tagged[tag] = append(c.tagged[tag], slot{shard: shard, index: first + i})
+		}
+	}
+}
+
+// untag forgets the functions recorded under tags and returns where they were registered.
+func (c *Closer) untag(tags []string) []slot {
+	c.tagsMu.Lock()
+	defer c.tagsMu.Unlock()
+	var slots []slot
+	for _, tag := range tags {
+		slots = append(slots, c.tagged[tag]...)
+		delete(c.tagged, tag)
+	}
+	return slots
+}
@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestCloseTagged verifies that CloseTagged runs only the functions with one of the tags, in
+// shutdown order, and that they do not run again on shutdown.
+func TestCloseTagged(t *testing.T) {
+	c := New(WithSequential())
+	var ran []string
+	errCache := errors.New("cache failed")
+	c.AddNamed("db", func() error { ran = append(ran, "db"); return nil })
+	c.AddNamed("sessions", func() error { ran = append(ran, "sessions"); return nil }, WithTags("cache"))
+	c.AddNamed("pages", func() error { ran = append(ran, "pages"); return errCache }, WithTags("cache", "http"), WithPriority(1))
+	c.AddNamed("tmp", func() error { ran = append(ran, "tmp"); return nil }, WithTags("files"))
+
+	err := c.CloseTagged("cache", "unknown")
+	if !errors.Is(err, errCache) {
+		t.Errorf("expected %v, got %v", errCache, err)
+	}
+	if want := []string{"pages", "sessions"}; !slices.Equal(ran, want) {
+		t.Errorf("expected %v to run, got %v", want, ran)
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("expected 2 functions left, got %d", n)
+	}
+
+	ran = nil
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"db", "tmp"}; !slices.Equal(ran, want) {
+		t.Errorf("expected %v to run on shutdown, got %v", want, ran)
+	}
+	if err := c.CloseTagged("files"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+}
+
+// TestCloseTaggedKeyed verifies that keyed and removed functions can be tagged.
+func TestCloseTaggedKeyed(t *testing.T) {
+	c := New()
+	ran := 0
+	c.AddKeyed("conn", func() error { ran++; return nil }, WithTags("conns"))
+	h := c.AddRemovable(func() error { ran += 10; return nil }, WithTags("conns"))
+	h.Remove()
+
+	if err := c.CloseTagged("conns"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ran != 1 {
+		t.Errorf("expected only the keyed function to run, got %d", ran)
+	}
+}
@@ -0,0 +1,98 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Go runs task in a new goroutine as a long-lived part of the application, such as a server
+// loop or a queue consumer. The task receives the context returned by Context, so it is
+// canceled as soon as shutdown is initiated. When a task returns, it initiates the shutdown
+// itself; its error, if any, is reported by Wait and CloseAll before the errors of the closing
+// functions. Errors of tasks that return context.Canceled or net.ErrClosed after shutdown was
+// initiated by something else are not reported.
+//
+// CloseAll waits for all tasks to return before running closing functions, so that those can
+// release the resources the tasks use, but gives up on them once the shutdown deadline passes.
+// Go does nothing if shutdown has already been initiated.
+//
+// Example:
+//
+//	c.Go(func(ctx context.Context) error {
+//		return consumer.Run(ctx)
+//	})
+//	c.AddNamed("db", db.Close)
+//	if err := c.Wait(); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Closer) Go(task func(ctx context.Context) error) {
+	mustNotBeNil(task, "task")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return
+	}
+	c.tasks++
+	go func() {
+		err := call(task, c.ctx)
+
+		c.mu.Lock()
+		initiated := !c.closing
+		if ignore(err, c.ignored) != nil && (initiated || !errors.Is(err, context.Canceled) && !errors.Is(err, net.ErrClosed)) {
+			c.taskErrs = append(c.taskErrs, fmt.Errorf("task: %w", err))
+		}
+		c.tasks--
+		if c.tasks == 0 {
+			c.held.Broadcast()
+		}
+		c.mu.Unlock()
+		if initiated {
+			go c.closeAll(context.Background(), Reason{Kind: ReasonTask, Err: err})
+		}
+	}()
+}
+
+// waitTasks waits for the tasks started with Go to return, or for ctx to be done, and
+// returns the errors to report for them. ctx being done must wake up c.held.
+func (c *Closer) waitTasks(ctx context.Context) []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.tasks > 0 && ctx.Err() == nil {
+		c.held.Wait()
+	}
+	errs := append([]error(nil), c.taskErrs...)
+	if c.tasks > 0 {
+		errs = append(errs, fmt.Errorf("tasks %w", notFinished(ctx.Err())))
+	}
+	return errs
+}
+
+// Waiter is a group of goroutines that can be waited for, such as an *errgroup.Group from
+// golang.org/x/sync.
+type Waiter interface {
+	Wait() error
+}
+
+// GoGroup runs the Wait method of g as a task started with Go, so that code structured around
+// an errgroup adopts the Closer in one line: the group finishing or failing initiates the
+// shutdown, and closing functions run once it has returned. For the goroutines of the group
+// to be canceled on shutdown, create it with the Context of c.
+//
+// Example:
+//
+//	g, ctx := errgroup.WithContext(c.Context())
+//	g.Go(func() error { return consumer.Run(ctx) })
+//	g.Go(func() error { return server.Run(ctx) })
+//	c.GoGroup(g)
+//	c.AddNamed("db", db.Close)
+//	if err := c.Wait(); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Closer) GoGroup(g Waiter) {
+	mustNotBeNil(g, "Waiter")
+	c.Go(func(context.Context) error {
+		return g.Wait()
+	})
+}
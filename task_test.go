@@ -0,0 +1,139 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGo verifies that a failing task initiates shutdown, that closing functions run only
+// once the other tasks have returned, and that the task's error is reported.
+func TestGo(t *testing.T) {
+	c := New()
+	boom := errors.New("boom")
+	var stopped int32
+	c.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&stopped, 1)
+		return ctx.Err()
+	})
+	c.Add(func() error {
+		if atomic.LoadInt32(&stopped) != 1 {
+			return errors.New("closing function ran before the task returned")
+		}
+		return nil
+	})
+	c.Go(func(context.Context) error { return boom })
+
+	err := c.Wait()
+	if !errors.Is(err, boom) || err.Error() != "task: boom" {
+		t.Errorf("expected only the task error, got %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonTask || r.Err != boom {
+		t.Errorf("expected shutdown initiated by the failing task, got %v", r)
+	}
+}
+
+// TestGoCloseAll verifies that tasks are canceled by CloseAll without reporting errors.
+func TestGoCloseAll(t *testing.T) {
+	c := New()
+	c.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	var ran int32
+	c.Go(func(context.Context) error {
+		atomic.StoreInt32(&ran, 1)
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("expected task started after shutdown not to run")
+	}
+}
+
+// TestGoDeadline verifies that tasks ignoring cancellation are given up on at the deadline.
+func TestGoDeadline(t *testing.T) {
+	c := New(WithTimeout(10 * time.Millisecond))
+	c.Go(func(context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	err := c.CloseAll()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+// errGroup is a minimal errgroup.Group returning the first error of its goroutines and
+// canceling its context on that error, as created by errgroup.WithContext.
+type errGroup struct {
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+	cancel context.CancelFunc
+}
+
+func newErrGroup(ctx context.Context) (*errGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &errGroup{cancel: cancel}, ctx
+}
+
+func (g *errGroup) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *errGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// TestGoGroup verifies that a failing group initiates shutdown with its error, and that
+// the goroutines of a group created with the Context are canceled on shutdown.
+func TestGoGroup(t *testing.T) {
+	c := New()
+	g, ctx := newErrGroup(c.Context())
+	boom := errors.New("boom")
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Go(func() error { return boom })
+	c.GoGroup(g)
+	if err := c.Wait(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if r := c.Reason(); r.Kind != ReasonTask {
+		t.Errorf("expected %v, got %v", ReasonTask, r.Kind)
+	}
+
+	c = New()
+	g, ctx = newErrGroup(c.Context())
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	c.GoGroup(g)
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected the canceled group not to be reported, got %v", err)
+	}
+}
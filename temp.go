@@ -0,0 +1,47 @@
+package closer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// AddTempDir registers the directory at path to be removed with its contents when CloseAll
+// is called. A directory that no longer exists is not an error. Errors name the path that
+// could not be removed. The function is named "temp-dir".
+//
+// Example:
+//
+//	c.AddTempDir(filepath.Join(os.TempDir(), "render-cache"))
+func (c *Closer) AddTempDir(path string) {
+	c.AddNamed("temp-dir", func() error {
+		return os.RemoveAll(path)
+	})
+}
+
+// AddTempFile registers the file at path to be removed when CloseAll is called. A file that
+// no longer exists is not an error. Errors name the path that could not be removed. The
+// function is named "temp-file".
+func (c *Closer) AddTempFile(path string) {
+	c.AddNamed("temp-file", func() error {
+		if err := os.Remove(path); !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// TempDir creates a new directory in the default directory for temporary files, named
+// after pattern as os.MkdirTemp does, and registers it to be removed with AddTempDir.
+//
+// Example:
+//
+//	dir, err := c.TempDir("uploads-*")
+func (c *Closer) TempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	c.AddTempDir(dir)
+	return dir, nil
+}
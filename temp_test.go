@@ -0,0 +1,62 @@
+package closer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTempDir verifies that a directory created by TempDir is removed with its contents.
+func TestTempDir(t *testing.T) {
+	c := New()
+	dir, err := c.TempDir("closer-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %s to be removed, got %v", dir, err)
+	}
+}
+
+// TestAddTempFile verifies that a file is removed and that a missing one is not an error.
+func TestAddTempFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.AddTempFile(path)
+	c.AddTempFile(filepath.Join(dir, "missing"))
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %s to be removed, got %v", path, err)
+	}
+}
+
+// TestAddTempFileError verifies that a failure names the path.
+func TestAddTempFileError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.AddTempFile(dir) // a non-empty directory cannot be removed as a file
+
+	if err := c.CloseAll(); err == nil || !strings.Contains(err.Error(), dir) {
+		t.Errorf("expected an error naming %s, got %v", dir, err)
+	}
+}
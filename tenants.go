@@ -0,0 +1,115 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Registry maps keys, such as tenant identifiers, to child Closers of a parent, so that the
+// resources of a single tenant can be torn down on eviction while the shutdown of the parent
+// still closes all of them. Each child is registered in the parent under its key, like
+// a Child, and is unregistered from the parent and forgotten by the Registry once it has been
+// closed, by whatever means. A Registry is safe for concurrent use.
+type Registry struct {
+	c    *Closer
+	opts []Option
+
+	mu       sync.Mutex
+	children map[string]*Closer
+}
+
+// NewRegistry returns an empty Registry of children of c. opts configure every child, as if
+// passed to Child.
+//
+// Example:
+//
+//	tenants := c.NewRegistry(closer.WithTimeout(5 * time.Second))
+//	db := closer.AddValue(tenants.Get(id), openTenantDB(id))
+//	...
+//	tenants.CloseOne(id) // when the tenant is evicted
+func (c *Closer) NewRegistry(opts ...Option) *Registry {
+	if c.logger != nil {
+		opts = append([]Option{WithLogger(c.logger)}, opts...)
+	}
+	return &Registry{c: c, opts: opts, children: make(map[string]*Closer)}
+}
+
+// Get returns the child registered under key, creating it if there is none, including when
+// the previous one has been closed.
+func (r *Registry) Get(key string) *Closer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if child, ok := r.children[key]; ok {
+		return child
+	}
+	child := New(r.opts...)
+	h := &Handle{c: r.c}
+	h.at = r.c.addContext(append([]Option{withName(key)}, r.opts...), func(ctx context.Context) error {
+		return child.CloseAllContext(ctx)
+	})
+	child.OnShutdownStart(func(Reason) {
+		h.Remove()
+		r.forget(key, child)
+	})
+	r.children[key] = child
+	return child
+}
+
+// Lookup returns the child registered under key, reporting whether there is one, without
+// creating it.
+func (r *Registry) Lookup(key string) (*Closer, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	child, ok := r.children[key]
+	return child, ok
+}
+
+// Keys returns the keys of the children, sorted.
+func (r *Registry) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.children))
+	for key := range r.children {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// CloseOne closes the child registered under key and returns the errors of its functions, as
+// CloseAll does. It returns nil if there is no such child.
+func (r *Registry) CloseOne(key string) error {
+	child, ok := r.Lookup(key)
+	if !ok {
+		return nil
+	}
+	return child.CloseAll()
+}
+
+// CloseAll closes all children concurrently and returns their errors joined, in the order of
+// their keys. The parent and the Registry stay usable; Get creates new children afterwards.
+func (r *Registry) CloseAll() error {
+	keys := r.Keys()
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = r.CloseOne(key)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// forget removes child from the Registry, unless another child has replaced it under key.
+func (r *Registry) forget(key string, child *Closer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.children[key] == child {
+		delete(r.children, key)
+	}
+}
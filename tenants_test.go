@@ -0,0 +1,66 @@
+package closer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestRegistryCloseOne verifies that closing a single child leaves the others registered,
+// and that it is forgotten and unregistered from the parent.
+func TestRegistryCloseOne(t *testing.T) {
+	c := New()
+	tenants := c.NewRegistry()
+	var closed []string
+	for _, key := range []string{"a", "b"} {
+		if tenants.Get(key) != tenants.Get(key) {
+			t.Fatalf("expected Get to return the same child for %s", key)
+		}
+		tenants.Get(key).AddNamed("db", func() error { closed = append(closed, key); return nil })
+	}
+
+	if err := tenants.CloseOne("a"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := tenants.CloseOne("missing"); err != nil {
+		t.Errorf("expected nil for a missing key, got %v", err)
+	}
+	if keys := tenants.Keys(); !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Errorf("expected keys [b], got %v", keys)
+	}
+	if plan := c.Plan(); len(plan) != 1 || len(plan[0].Funcs) != 1 || plan[0].Funcs[0].Name != "b" {
+		t.Errorf("expected only b to stay registered in the parent, got %+v", plan)
+	}
+
+	c.CloseAll()
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(closed, expected) {
+		t.Errorf("expected %v to be closed, got %v", expected, closed)
+	}
+	if _, ok := tenants.Lookup("b"); ok {
+		t.Error("expected b to be forgotten after the parent shutdown")
+	}
+}
+
+// TestRegistryCloseAll verifies that all children are closed and their errors joined, and
+// that Get creates a new child afterwards.
+func TestRegistryCloseAll(t *testing.T) {
+	c := New()
+	tenants := c.NewRegistry()
+	boom := errors.New("boom")
+	first := tenants.Get("a")
+	first.Add(func() error { return boom })
+	tenants.Get("b").Add(func() error { return nil })
+
+	if err := tenants.CloseAll(); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if keys := tenants.Keys(); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+	if tenants.Get("a") == first {
+		t.Error("expected a new child after the previous one was closed")
+	}
+	if c.IsClosing() {
+		t.Error("expected the parent to keep running")
+	}
+}
@@ -0,0 +1,71 @@
+package closer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultTerminationLog is the file Kubernetes reads the termination message of a container
+// from, unless the pod specifies another terminationMessagePath.
+const defaultTerminationLog = "/dev/termination-log"
+
+// maxTerminationLog is the size Kubernetes truncates termination messages to.
+const maxTerminationLog = 4096
+
+// WithTerminationLog makes New create a Closer that writes a short summary of its shutdown to
+// the file at path, /dev/termination-log if path is empty, once all closing functions have
+// finished: the reason, whether the shutdown was clean, and the functions that failed, so
+// that kubectl describe pod shows why and how the container terminated. The summary is
+// truncated to the 4096 bytes Kubernetes keeps. Failing to write it is logged as an Error
+// "termination log not written" event without affecting the shutdown.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithTerminationLog(""))
+func WithTerminationLog(path string) Option {
+	return func(o *options) {
+		if path == "" {
+			path = defaultTerminationLog
+		}
+		o.terminationLog = path
+	}
+}
+
+// writeTerminationLog writes the summary of r to path, as described in WithTerminationLog.
+func (c *Closer) writeTerminationLog(path string, r Report) {
+	msg := terminationMessage(r)
+	if len(msg) > maxTerminationLog {
+		msg = msg[:maxTerminationLog]
+	}
+	if err := os.WriteFile(path, []byte(msg), 0o644); err != nil {
+		c.log().Error("termination log not written", "path", path, "error", err)
+	}
+}
+
+// terminationMessage returns the summary of r written by WithTerminationLog.
+func terminationMessage(r Report) string {
+	var failed []FuncReport
+	for _, f := range r.Funcs {
+		if f.Err != nil && !f.Optional {
+			failed = append(failed, f)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "shutdown: %s\n", r.Reason)
+	if len(failed) == 0 {
+		fmt.Fprintf(&b, "clean: %d closers finished in %v\n", len(r.Funcs), r.Duration)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "dirty: %d of %d closers failed in %v\n", len(failed), len(r.Funcs), r.Duration)
+	for _, f := range failed {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", f.Index)
+		}
+		// Keep the first line only, leaving out the stack of panics.
+		err, _, _ := strings.Cut(f.Err.Error(), "\n")
+		fmt.Fprintf(&b, "failed: %s: %s\n", name, err)
+	}
+	return b.String()
+}
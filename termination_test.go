@@ -0,0 +1,46 @@
+package closer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWithTerminationLog verifies the summary written for a dirty shutdown.
+func TestWithTerminationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	c := New(WithTerminationLog(path))
+	c.AddNamed("db", func() error { return errors.New("boom") })
+	c.Add(func() error { panic("oops") })
+	c.Add(func() error { return nil })
+	c.AddOptional(func() error { return errors.New("ignored") })
+	c.CloseAll()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected termination log to be written, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", data)
+	}
+	if lines[0] != "shutdown: CloseAll call" || !strings.HasPrefix(lines[1], "dirty: 2 of 4 closers failed in ") {
+		t.Errorf("expected reason and dirty result, got %q", lines[:2])
+	}
+	if lines[2] != "failed: db: boom" || lines[3] != "failed: #1: closer: panic: oops" {
+		t.Errorf("expected failed closers, got %q", lines[2:])
+	}
+}
+
+// TestWithTerminationLogClean verifies the summary of a clean shutdown and the default path.
+func TestWithTerminationLogClean(t *testing.T) {
+	if o := newOptions([]Option{WithTerminationLog("")}); o.terminationLog != defaultTerminationLog {
+		t.Errorf("expected %s, got %s", defaultTerminationLog, o.terminationLog)
+	}
+	r := Report{Funcs: []FuncReport{{Name: "db"}}}
+	if msg := terminationMessage(r); !strings.HasSuffix(msg, "clean: 1 closers finished in 0s\n") {
+		t.Errorf("expected a clean summary, got %q", msg)
+	}
+}
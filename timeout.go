@@ -0,0 +1,81 @@
+package closer
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// AddWithTimeout registers one or more closing functions that may each run for at most d.
+// A function that does not finish in time is abandoned without delaying the rest of the
+// shutdown, and is reported with an error wrapping ErrTimeout, distinct from the errors the
+// functions return themselves.
+//
+// Example:
+//
+//	c.AddWithTimeout(5*time.Second, uploader.Flush)
+func (c *Closer) AddWithTimeout(d time.Duration, f ...closeFunc) {
+	c.add([]Option{WithTimeout(d)}, f...)
+}
+
+// timeoutEnv lists the environment variables TimeoutFromEnv reads, in order of preference.
+var timeoutEnv = []string{"SHUTDOWN_GRACE_PERIOD", "TERMINATION_GRACE_PERIOD_SECONDS"}
+
+// TimeoutFromEnv returns the shutdown timeout to use for the grace period configured by the
+// deployment, so that it does not drift from a hardcoded value: the grace period is read from
+// SHUTDOWN_GRACE_PERIOD or, failing that, TERMINATION_GRACE_PERIOD_SECONDS, for example set
+// from terminationGracePeriodSeconds in a Kubernetes pod spec, as a duration such as "30s" or
+// a number of seconds. margin is subtracted from it, to leave time for exiting before the
+// process is killed, but never more than half of the grace period. fallback is returned if
+// neither variable holds a positive duration.
+//
+// Example:
+//
+//	c := closer.New(closer.WithTimeout(closer.TimeoutFromEnv(30*time.Second, 5*time.Second)))
+func TimeoutFromEnv(fallback, margin time.Duration) time.Duration {
+	for _, name := range timeoutEnv {
+		grace, ok := parseGracePeriod(os.Getenv(name))
+		if ok {
+			return grace - min(margin, grace/2)
+		}
+	}
+	return fallback
+}
+
+// parseGracePeriod parses a grace period given as a duration or a number of seconds, and
+// reports whether it is a positive one.
+func parseGracePeriod(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	return d, d > 0
+}
+
+// WithCancelGrace makes functions whose context is canceled, because the shutdown deadline
+// passed or their own timeout elapsed, have d more to return before being abandoned, so that
+// context-aware functions such as http.Server.Shutdown can switch to closing forcefully and
+// report how that went. A function returning within d is reported with the error it returns,
+// if any. Given to New, it applies to all functions registered without it.
+//
+// Example:
+//
+//	c := closer.New(closer.WithTimeout(10*time.Second), closer.WithCancelGrace(time.Second))
+//	c.AddContext(func(ctx context.Context) error {
+//		if err := srv.Shutdown(ctx); err != nil {
+//			return errors.Join(err, srv.Close())
+//		}
+//		return nil
+//	})
+func WithCancelGrace(d time.Duration) Option {
+	return func(o *options) {
+		o.cancelGrace = d
+	}
+}
@@ -0,0 +1,104 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAddWithTimeout verifies that a slow function is time-boxed and reported with
+// ErrTimeout, while other functions are unaffected.
+func TestAddWithTimeout(t *testing.T) {
+	c := New()
+	errFlush := errors.New("flush failed")
+	block := make(chan struct{})
+	defer close(block)
+
+	c.AddWithTimeout(10*time.Millisecond, func() error {
+		<-block
+		return nil
+	})
+	c.AddWithTimeout(time.Second, func() error { return errFlush })
+
+	start := time.Now()
+	err := c.CloseAll()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected slow function to be abandoned, shutdown took %v", elapsed)
+	}
+
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, errFlush) {
+		t.Fatalf("expected timeout and flush errors, got %v", err)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "#0: "+ErrTimeout.Error()) || lines[1] != "#1: flush failed" {
+		t.Errorf("expected distinct timeout and flush errors, got %q", err.Error())
+	}
+}
+
+// TestWithCancelGrace verifies that a function whose context is canceled by the shutdown
+// deadline gets the grace to return, and is reported with its own outcome.
+func TestWithCancelGrace(t *testing.T) {
+	for _, sequential := range []bool{false, true} {
+		opts := []Option{WithTimeout(20 * time.Millisecond), WithCancelGrace(time.Second)}
+		if sequential {
+			opts = append(opts, WithSequential())
+		}
+		c := New(opts...)
+		forced := make(chan struct{})
+		c.addContext([]Option{withName("server")}, func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond) // close connections forcefully
+			close(forced)
+			return errors.New("forced close")
+		})
+
+		err := c.CloseAll()
+		select {
+		case <-forced:
+		default:
+			t.Fatalf("sequential=%v: expected CloseAll to wait for the forced close", sequential)
+		}
+		if err == nil || !strings.Contains(err.Error(), "forced close") || errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("sequential=%v: expected the error of the function, got %v", sequential, err)
+		}
+	}
+}
+
+// TestWithCancelGraceExceeded verifies that a function not returning within its grace is
+// abandoned.
+func TestWithCancelGraceExceeded(t *testing.T) {
+	c := New(WithTimeout(10*time.Millisecond), WithCancelGrace(10*time.Millisecond))
+	block := make(chan struct{})
+	defer close(block)
+	c.AddContext(func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+	if err := c.CloseAll(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestTimeoutFromEnv verifies the variables read, the formats accepted and the margin.
+func TestTimeoutFromEnv(t *testing.T) {
+	tests := []struct {
+		grace, seconds string
+		expected       time.Duration
+	}{
+		{"", "", time.Minute},
+		{"", "30", 25 * time.Second},
+		{"20s", "30", 15 * time.Second},
+		{"6", "", 3 * time.Second},
+		{"1.5", "", 750 * time.Millisecond},
+		{"soon", "-5", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Setenv("SHUTDOWN_GRACE_PERIOD", tt.grace)
+		t.Setenv("TERMINATION_GRACE_PERIOD_SECONDS", tt.seconds)
+		if got := TimeoutFromEnv(time.Minute, 5*time.Second); got != tt.expected {
+			t.Errorf("%q, %q: expected %v, got %v", tt.grace, tt.seconds, tt.expected, got)
+		}
+	}
+}
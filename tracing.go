@@ -0,0 +1,52 @@
+package closer
+
+import "context"
+
+// Tracer starts trace spans, for example with OpenTelemetry. A Closer given one with
+// WithTracer wraps the shutdown in a span named "shutdown", and each closing function in a
+// child span named after the function as in log messages, such as "kafka/consumer #3".
+//
+// Example:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, func(error)) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		return ctx, func(err error) {
+//			if err != nil {
+//				span.RecordError(err)
+//				span.SetStatus(codes.Error, err.Error())
+//			}
+//			span.End()
+//		}
+//	}
+type Tracer interface {
+	// Start starts a span named name as a child of the span in ctx, if any, and returns a
+	// context carrying the new span together with a function that ends it. The function is
+	// given the error the span's operation ended with, or nil.
+	Start(ctx context.Context, name string) (context.Context, func(err error))
+}
+
+// WithTracer makes New create a Closer that traces its shutdown with t.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// trace wraps the functions of steps in spans started with t. A function that is abandoned
+// keeps its span open until it actually returns.
+func trace(t Tracer, steps []step) {
+	for _, s := range steps {
+		for i := range s.funcs {
+			e := &s.funcs[i]
+			name, fn := e.String(), e.fn
+			e.fn = func(ctx context.Context) error {
+				ctx, end := t.Start(ctx, name)
+				err := call(fn, ctx)
+				end(err)
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// spanKey is the context key under which recordTracer stores the current span name.
+type spanKey struct{}
+
+// recordTracer is a Tracer that records ended spans as "parent > name: err" lines.
+type recordTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string) (context.Context, func(error)) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	return context.WithValue(ctx, spanKey{}, name), func(err error) {
+		t.mu.Lock()
+		t.spans = append(t.spans, parent+" > "+name+": "+errString(err))
+		t.mu.Unlock()
+	}
+}
+
+// errString returns the message of err, or "ok" if err is nil.
+func errString(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
+
+// TestWithTracer verifies that the shutdown and each function get a span, with the
+// function spans as children of the shutdown span.
+func TestWithTracer(t *testing.T) {
+	tr := &recordTracer{}
+	c := New(WithTracer(tr))
+	c.AddNamed("cache", func() error { return nil })
+	c.AddContext(func(ctx context.Context) error {
+		if ctx.Value(spanKey{}) != "#1" {
+			return errors.New("no span in context")
+		}
+		return nil
+	})
+	c.AddNamed("db", func() error { return errors.New("boom") })
+	c.CloseAll()
+
+	sort.Strings(tr.spans)
+	expected := []string{
+		" > shutdown: db #2: boom",
+		"shutdown > #1: ok",
+		"shutdown > cache #0: ok",
+		"shutdown > db #2: boom",
+	}
+	if got := strings.Join(tr.spans, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected spans\n%s\ngot\n%s", strings.Join(expected, "\n"), got)
+	}
+}
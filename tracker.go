@@ -0,0 +1,106 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Tracker counts units of work in flight, such as consumed messages or cron jobs, so that
+// shutdown waits for them to complete. Trackers are created with Closer.Tracker.
+type Tracker struct {
+	unit     string // what is tracked, for error messages
+	mu       sync.Mutex
+	idle     *sync.Cond // signaled when the last unit of work in flight completes
+	inflight int
+	draining bool // set once the drain function has started, new work is refused
+}
+
+// Tracker returns a new Tracker and registers a closing function, named "tracker" and
+// configured by opts, that waits for the work in flight to complete. Once that function has
+// started, Begin refuses new work. The function runs before all others, or in PhaseRequests if
+// c declares it, as NewWebService does, unless opts give it another priority or phase; it
+// fails with an error wrapping the shutdown context's error if work is still in flight when
+// the deadline passes.
+//
+// Example:
+//
+//	jobs := c.Tracker(closer.WithTimeout(30 * time.Second))
+//	for msg := range messages {
+//		if !jobs.Track(func() { handle(msg) }) {
+//			break
+//		}
+//	}
+func (c *Closer) Tracker(opts ...Option) *Tracker {
+	return c.tracker("units of work", append([]Option{withName("tracker")}, opts...))
+}
+
+// tracker returns a new Tracker of unit and registers its drain function configured by opts.
+func (c *Closer) tracker(unit string, opts []Option) *Tracker {
+	t := &Tracker{unit: unit}
+	t.idle = sync.NewCond(&t.mu)
+	c.addContext(append([]Option{c.phaseOr(PhaseRequests, WithPriority(math.MaxInt))}, opts...), t.drain)
+	return t
+}
+
+// Begin marks the start of a unit of work and reports whether it may start. Once draining
+// has started, it returns false and the work must not be done. Every successful Begin must be
+// followed by a call to End.
+func (t *Tracker) Begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.inflight++
+	return true
+}
+
+// End marks the end of a unit of work started with Begin.
+func (t *Tracker) End() {
+	t.mu.Lock()
+	t.inflight--
+	if t.inflight == 0 {
+		t.idle.Broadcast()
+	}
+	t.mu.Unlock()
+}
+
+// Track runs fn as a unit of work, unless draining has started, and reports whether it ran.
+func (t *Tracker) Track(fn func()) bool {
+	if !t.Begin() {
+		return false
+	}
+	defer t.End()
+	fn()
+	return true
+}
+
+// InFlight returns the number of units of work in flight.
+func (t *Tracker) InFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inflight
+}
+
+// drain refuses new work and waits for the work in flight to complete or for ctx to be done.
+func (t *Tracker) drain(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		t.idle.Broadcast()
+		t.mu.Unlock()
+	})
+	defer stop()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+	for t.inflight > 0 && ctx.Err() == nil {
+		t.idle.Wait()
+	}
+	if t.inflight > 0 {
+		return fmt.Errorf("%d %s in flight: %w", t.inflight, t.unit, ctx.Err())
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTracker verifies that shutdown waits for the work in flight before running other
+// functions, and that new work is refused once draining has started.
+func TestTracker(t *testing.T) {
+	c := New()
+	jobs := c.Tracker()
+	if !jobs.Begin() {
+		t.Fatal("expected work to be admitted before shutdown")
+	}
+	var inflight int
+	c.Add(func() error {
+		inflight = jobs.InFlight()
+		return nil
+	})
+
+	closed := make(chan error)
+	go func() { closed <- c.CloseAll() }()
+	time.Sleep(20 * time.Millisecond)
+	if jobs.Track(func() {}) {
+		t.Error("expected new work to be refused while draining")
+	}
+	jobs.End()
+
+	if err := <-closed; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if inflight != 0 {
+		t.Errorf("expected other functions to run once work completed, got %d in flight", inflight)
+	}
+}
+
+// TestTrackerTimeout verifies that work still in flight at the deadline is reported.
+func TestTrackerTimeout(t *testing.T) {
+	c := New()
+	jobs := c.Tracker()
+	jobs.Begin()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.CloseAllContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
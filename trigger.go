@@ -0,0 +1,90 @@
+package closer
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+	"weak"
+)
+
+// triggerPollInterval is how often WithTriggerFile checks the trigger file.
+const triggerPollInterval = 250 * time.Millisecond
+
+// WithTriggerFile makes New create a Closer that initiates shutdown when the file at path is
+// created or touched, for environments where sending a signal to the process is awkward but
+// writing a file is easy, such as a sandbox or a container whose main process runs as PID 1:
+//
+//	touch /run/app/shutdown
+//
+// A file already at path when New is called initiates shutdown only once touched, so that a
+// trigger left over by a previous process is ignored. If path is a named pipe at that time,
+// shutdown is instead initiated by writing to it. The file is checked every 250ms. The
+// shutdown is reported with ReasonTrigger and preceded by an Info "shutdown triggered" event
+// with the "path".
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.WithTriggerFile("/run/app/shutdown"))
+func WithTriggerFile(path string) Option {
+	return func(o *options) {
+		o.triggerFile = path
+	}
+}
+
+// watchTriggerFile starts watching the trigger file at path on behalf of c, until shutdown is
+// initiated or c is garbage collected.
+func (c *Closer) watchTriggerFile(path string) {
+	ref, done := weak.Make(c), c.Context().Done()
+	trigger := func() {
+		if c := ref.Value(); c != nil {
+			c.log().Info("shutdown triggered", "path", path)
+			c.closeAll(context.Background(), Reason{Kind: ReasonTrigger})
+		}
+	}
+	info, err := os.Stat(path)
+	if err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		// Opening the pipe for writing too keeps it from reporting the end of the file, and
+		// from blocking until a writer opens it.
+		pipe, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			c.log().Error("trigger file not watched", "path", path, "error", err)
+			return
+		}
+		// Closing the pipe once shutdown is initiated or c is garbage collected ends the read.
+		stop := context.AfterFunc(c.Context(), func() { pipe.Close() })
+		runtime.AddCleanup(c, func(pipe *os.File) { pipe.Close() }, pipe)
+		go func() {
+			defer stop()
+			defer pipe.Close()
+			if _, err := pipe.Read(make([]byte, 1)); err == nil {
+				trigger()
+			}
+		}()
+		return
+	}
+
+	ticker, collected := c.clock.NewTicker(triggerPollInterval), c.collected()
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+			case <-done:
+				return
+			case <-collected:
+				return
+			}
+			next, err := os.Stat(path)
+			if err != nil {
+				info = nil
+				continue
+			}
+			if info != nil && next.ModTime().Equal(info.ModTime()) {
+				continue
+			}
+			trigger()
+			return
+		}
+	}()
+}
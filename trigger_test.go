@@ -0,0 +1,70 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestWithTriggerFile verifies that creating the trigger file initiates shutdown.
+func TestWithTriggerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown")
+	l := &recordLogger{}
+	c := New(WithLogger(l), WithTriggerFile(path))
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WaitTimeout(5 * time.Second); err != nil {
+		t.Fatalf("expected shutdown, got %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonTrigger || r.String() != "trigger file" {
+		t.Errorf("expected trigger file reason, got %v", r)
+	}
+	if want := "INFO shutdown triggered path=" + path; !slices.Contains(l.events, want) {
+		t.Errorf("expected %q, got %v", want, l.events)
+	}
+}
+
+// TestWithTriggerFileExisting verifies that a trigger file present at start initiates
+// shutdown only once touched.
+func TestWithTriggerFileExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := New(WithTriggerFile(path))
+	if err := c.WaitTimeout(2 * triggerPollInterval); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected no shutdown, got %v", err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WaitTimeout(5 * time.Second); err != nil {
+		t.Errorf("expected shutdown once touched, got %v", err)
+	}
+}
+
+// TestWithTriggerFileNoLeak verifies that the watcher of a Closer that is never closed stops
+// once the Closer is garbage collected.
+func TestWithTriggerFileNoLeak(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown")
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		New(WithTriggerFile(path))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build unix
+
+package closer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestWithTriggerFilePipe verifies that writing to a named pipe given as the trigger file
+// initiates shutdown.
+func TestWithTriggerFilePipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown")
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := New(WithTriggerFile(path))
+	pipe, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipe.Close()
+	if _, err := pipe.WriteString("stop\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WaitTimeout(5 * time.Second); err != nil {
+		t.Fatalf("expected shutdown, got %v", err)
+	}
+	if r := c.Reason(); r.Kind != ReasonTrigger {
+		t.Errorf("expected trigger file reason, got %v", r)
+	}
+}
+
+// TestWithTriggerFilePipeNoLeak verifies that the reader of a named pipe of a Closer that is
+// never closed stops once the Closer is garbage collected.
+func TestWithTriggerFilePipeNoLeak(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shutdown")
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		New(WithTriggerFile(path))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at most %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,88 @@
+package closer
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// WithSkipNil makes New create a Closer that skips nil closing functions, logging an Error
+// "nil closer skipped" with the "caller" that registered them, instead of panicking. Without
+// it, registering a nil function panics right away, rather than failing mid-shutdown.
+func WithSkipNil() Option {
+	return func(o *options) {
+		o.skipNil = true
+	}
+}
+
+// WithDuplicateWarnings makes New create a Closer that logs a Warn "duplicate closer
+// registered", with the "caller", when the same function value is registered twice, such as
+// a package-level function or a method value stored in a variable, which usually means that
+// a resource would be closed twice. Method values and closures capturing variables are
+// created anew by every evaluation, so registering them twice is not reported.
+func WithDuplicateWarnings() Option {
+	return func(o *options) {
+		o.duplicates = true
+	}
+}
+
+// duplicates holds the function values registered with a Closer checking for duplicates.
+type duplicates struct {
+	mu   sync.Mutex
+	seen map[unsafe.Pointer]bool
+}
+
+// newDuplicates returns an empty set of function values.
+func newDuplicates() *duplicates {
+	return &duplicates{seen: make(map[unsafe.Pointer]bool)}
+}
+
+// funcValue returns the identity of the function value f, shared by its copies only.
+func funcValue[F ~func() | ~func() error | ~func(context.Context) error](f F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+// validContextFuncs returns the functions of fs that pass validation, as add does.
+func (c *Closer) validContextFuncs(fs []contextFunc) []contextFunc {
+	// Copy the valid functions only once one is rejected, which is rare.
+	var valid []contextFunc
+	rejected := false
+	for i, f := range fs {
+		ok := c.valid(f == nil, funcValue(f))
+		switch {
+		case !ok && !rejected:
+			valid, rejected = append([]contextFunc(nil), fs[:i]...), true
+		case ok && rejected:
+			valid = append(valid, f)
+		}
+	}
+	if !rejected {
+		return fs
+	}
+	return valid
+}
+
+// valid checks a function about to be registered, identified by the function value fn, and
+// reports whether to register it. A nil function panics, unless WithSkipNil is given, in
+// which case it is logged and skipped. Duplicates are reported if WithDuplicateWarnings is
+// given.
+func (c *Closer) valid(isNil bool, fn unsafe.Pointer) bool {
+	if isNil {
+		if !c.skipNil {
+			panic("closer: nil closing function")
+		}
+		c.log().Error("nil closer skipped", "caller", caller())
+		return false
+	}
+	if c.dups == nil {
+		return true
+	}
+	c.dups.mu.Lock()
+	seen := c.dups.seen[fn]
+	c.dups.seen[fn] = true
+	c.dups.mu.Unlock()
+	if seen {
+		warn(c.log(), "duplicate closer registered", "caller", caller())
+	}
+	return true
+}
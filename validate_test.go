@@ -0,0 +1,75 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestNilFuncPanics verifies that registering a nil function panics at registration.
+func TestNilFuncPanics(t *testing.T) {
+	for name, add := range map[string]func(c *Closer){
+		"Add":        func(c *Closer) { c.Add(nil) },
+		"AddContext": func(c *Closer) { c.AddContext(nil) },
+		"TryAdd":     func(c *Closer) { c.TryAdd(nil) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != "closer: nil closing function" {
+					t.Errorf("%s: expected panic on nil function, got %v", name, r)
+				}
+			}()
+			add(New())
+		}()
+	}
+}
+
+// TestWithSkipNil verifies that nil functions are skipped and logged with their caller.
+func TestWithSkipNil(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithSkipNil(), WithLogger(l))
+	ran := 0
+	c.Add(nil, func() error { ran++; return nil })
+	c.AddContext(func(context.Context) error { ran++; return nil }, nil)
+
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ran != 2 {
+		t.Errorf("expected 2 functions to run, got %d", ran)
+	}
+	events := l.events
+	n := 0
+	for _, e := range events {
+		if strings.HasPrefix(e, "ERROR nil closer skipped") {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected 2 skipped events, got %v", events)
+	}
+}
+
+// TestWithDuplicateWarnings verifies that a function value registered twice is reported,
+// while distinct closures are not.
+func TestWithDuplicateWarnings(t *testing.T) {
+	l := &recordLogger{}
+	c := New(WithDuplicateWarnings(), WithLogger(l))
+	f := func() error { return nil }
+	c.Add(f)
+	for i := range 3 {
+		c.Add(func() error { return fmt.Errorf("worker %d", i) })
+	}
+	c.AddNamed("again", f)
+
+	var warnings []string
+	for _, e := range l.events {
+		if strings.Contains(e, "duplicate closer registered") {
+			warnings = append(warnings, e)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected 1 duplicate warning, got %v", warnings)
+	}
+}
@@ -0,0 +1,42 @@
+package closer
+
+import "context"
+
+// contextValue is a value added to the context of closing functions with WithValue.
+type contextValue struct {
+	key, val any
+}
+
+// WithValue makes the context handed to closing functions carry val under key, as
+// context.WithValue does, so that functions registered with AddContext, and the Interceptors
+// and logs they feed, can tell which request, tenant or trace owns the resource being torn
+// down. Values accumulate when WithValue is given more than once; later ones win for the same
+// key.
+func WithValue(key, val any) Option {
+	return func(o *options) {
+		o.values = append(o.values, contextValue{key: key, val: val})
+	}
+}
+
+// AddWithValue registers a single closing function that receives the shutdown context
+// carrying val under key, configured by opts, as AddContext and WithValue do.
+//
+// Example:
+//
+//	c.AddWithValue(tenantKey{}, tenantID, func(ctx context.Context) error {
+//		log.InfoContext(ctx, "closing tenant pool")
+//		return pool.Close()
+//	})
+func (c *Closer) AddWithValue(key, val any, f func(ctx context.Context) error, opts ...Option) {
+	c.addContext(append([]Option{WithValue(key, val)}, opts...), f)
+}
+
+// withValues returns fn called with a context carrying values.
+func withValues(fn contextFunc, values []contextValue) contextFunc {
+	return func(ctx context.Context) error {
+		for _, v := range values {
+			ctx = context.WithValue(ctx, v.key, v.val)
+		}
+		return fn(ctx)
+	}
+}
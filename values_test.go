@@ -0,0 +1,41 @@
+package closer
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type tenantKey struct{}
+
+// TestAddWithValue verifies that the closing functions and the interceptors see the values
+// given at registration in the shutdown context, and that later values win.
+func TestAddWithValue(t *testing.T) {
+	var mu sync.Mutex
+	intercepted := make(map[string]any)
+	c := New(WithInterceptor(func(name string, next func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			intercepted[name] = ctx.Value(tenantKey{})
+			mu.Unlock()
+			return next(ctx)
+		}
+	}))
+	var seen, request any
+	c.AddWithValue(tenantKey{}, "acme", func(ctx context.Context) error {
+		seen, request = ctx.Value(tenantKey{}), ctx.Value("request")
+		return nil
+	}, withName("pool"), WithValue("request", "42"))
+	c.AddNamed("other", func() error { return nil }, WithValue(tenantKey{}, "a"), WithValue(tenantKey{}, "b"))
+	c.CloseAll()
+
+	if seen != "acme" || request != "42" {
+		t.Errorf("expected acme and 42, got %v and %v", seen, request)
+	}
+	if got := intercepted["pool #0"]; got != "acme" {
+		t.Errorf("expected the interceptor to see acme, got %v", got)
+	}
+	if got := intercepted["other #1"]; got != "b" {
+		t.Errorf("expected the later value to win, got %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package closer
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// Phases declared by NewWebService, in the order they run, to assign closing functions to with
+// InPhase. Functions registered without a phase are closed as dependencies.
+const (
+	// PhaseListeners stops accepting connections, such as with AddListener, which defaults to
+	// it.
+	PhaseListeners = "listeners"
+	// PhaseRequests drains the requests and connections in flight, such as with the Shutdown
+	// method of an http.Server. AddHTTPServer, AddShutdowner, AddGracefulStopper,
+	// HTTPMiddleware, Tracker, Connections and Workers default to it.
+	PhaseRequests = "requests"
+	// PhaseDependencies closes what requests use: databases, caches and clients of other
+	// services.
+	PhaseDependencies = "dependencies"
+	// PhaseTelemetry flushes logs, metrics and traces, last so that they cover the rest of the
+	// shutdown.
+	PhaseTelemetry = "telemetry"
+)
+
+// Defaults of NewWebService.
+const (
+	// DefaultWebDrainDelay leaves load balancers and Kubernetes endpoints time to stop routing
+	// traffic to the process once its readiness probe fails.
+	DefaultWebDrainDelay = 5 * time.Second
+	// DefaultWebTimeout keeps the shutdown within the 30 seconds Kubernetes grants by default
+	// before killing the process.
+	DefaultWebTimeout = 25 * time.Second
+)
+
+// NewWebService creates a Closer for web services, wired for the usual graceful shutdown:
+// on SIGTERM or Ctrl-C, Ready turns false so that ReadyHandler fails readiness probes, the
+// service keeps serving for DefaultWebDrainDelay while traffic is routed away from it, then
+// the phases PhaseListeners, PhaseRequests, PhaseDependencies and PhaseTelemetry run in this
+// order, the whole shutdown being bounded by DefaultWebTimeout. opts are applied after these
+// settings, so they can override them, for example WithDrainDelay outside Kubernetes, except
+// for WithSignals, which adds signals to SIGTERM and Ctrl-C rather than replacing them; use
+// Ignore to stop watching those. AddHTTPServer, AddShutdowner and AddGracefulStopper default
+// to PhaseRequests, as HTTPMiddleware, Tracker, Connections and Workers do, and AddListener to
+// PhaseListeners.
+//
+// Example:
+//
+//	c := closer.NewWebService(closer.WithSlog(logger))
+//	mux.Handle("/readyz", c.ReadyHandler())
+//	c.AddHTTPServer(srv)
+//	c.AddNamed("db", db.Close)
+//	c.AddSink(metrics, 0, closer.InPhase(closer.PhaseTelemetry))
+func NewWebService(opts ...Option) *Closer {
+	return New(append([]Option{
+		WithSignals(syscall.SIGTERM, os.Interrupt),
+		WithDrainDelay(DefaultWebDrainDelay),
+		WithTimeout(DefaultWebTimeout),
+		WithPhase(PhaseListeners, 300),
+		WithPhase(PhaseRequests, 200),
+		WithPhase(PhaseDependencies, 0),
+		WithPhase(PhaseTelemetry, -100),
+	}, opts...)...)
+}
+
+// phaseOr returns the option placing the closing function of a helper by default: in phase
+// if c declares it, as NewWebService does, and as otherwise says if not.
+func (c *Closer) phaseOr(phase string, otherwise Option) Option {
+	if _, ok := c.phases[phase]; ok {
+		return InPhase(phase)
+	}
+	return otherwise
+}
@@ -0,0 +1,99 @@
+package closer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestNewWebService verifies that the phases of a web service run in order, and that the
+// drain delay can be overridden.
+func TestNewWebService(t *testing.T) {
+	c := NewWebService(WithNotifier(&fakeNotifier{}), WithDrainDelay(0))
+	if c.drainDelay != 0 || c.timeout != DefaultWebTimeout {
+		t.Errorf("expected no drain delay and the default timeout, got %v and %v", c.drainDelay, c.timeout)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddListener(l, InPhase(PhaseListeners))
+	c.HTTPMiddleware(InPhase(PhaseRequests))
+	c.AddNamed("db", func() error { return nil })
+	c.AddNamed("metrics", func() error { return nil }, InPhase(PhaseTelemetry))
+
+	var phases []string
+	for _, s := range c.Plan() {
+		phases = append(phases, s.Phase)
+	}
+	want := []string{PhaseListeners, PhaseRequests, PhaseDependencies, PhaseTelemetry}
+	if !slices.Equal(phases, want) {
+		t.Errorf("expected phases %v, got %v", want, phases)
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestNewWebServiceDefaults verifies the drain delay of a web service.
+func TestNewWebServiceDefaults(t *testing.T) {
+	c := NewWebService(WithNotifier(&fakeNotifier{}))
+	if c.drainDelay != DefaultWebDrainDelay {
+		t.Errorf("expected drain delay %v, got %v", DefaultWebDrainDelay, c.drainDelay)
+	}
+}
+
+// TestNewWebServiceHelperPhases verifies that the helpers of a web service default to its
+// phases rather than to running before all of them, unless given another priority.
+func TestNewWebServiceHelperPhases(t *testing.T) {
+	c := NewWebService(WithNotifier(&fakeNotifier{}), WithDrainDelay(0))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddListener(l)
+	c.HTTPMiddleware()
+	c.Tracker()
+	c.Connections(time.Millisecond)
+	c.Workers(1, func(ctx context.Context) { <-ctx.Done() })
+	c.AddHTTPServer(&http.Server{})
+	c.AddShutdowner(context.Background(), &fakeShutdowner{})
+	g := newFakeGracefulStopper()
+	close(g.release)
+	c.AddGracefulStopper(g, time.Second)
+	c.AddNamed("db", func() error { return nil })
+	c.AddNamed("probe", func() error { return nil }, InPhase(PhaseTelemetry), WithPriority(500))
+
+	phases := make(map[string]string)
+	for _, s := range c.Plan() {
+		for _, f := range s.Funcs {
+			phases[f.Name] = s.Phase
+		}
+	}
+	want := map[string]string{
+		"listener":                    PhaseListeners,
+		"http-drain":                  PhaseRequests,
+		"tracker":                     PhaseRequests,
+		"connections":                 PhaseRequests,
+		"workers":                     PhaseRequests,
+		"http-server":                 PhaseRequests,
+		"db":                          PhaseDependencies,
+		"*closer.fakeShutdowner":      PhaseRequests,
+		"*closer.fakeGracefulStopper": PhaseRequests,
+		"probe":                       "",
+	}
+	for name, phase := range want {
+		if phases[name] != phase {
+			t.Errorf("expected %s in phase %q, got %q", name, phase, phases[name])
+		}
+	}
+	if s := c.Plan()[0]; s.Funcs[0].Name != "probe" {
+		t.Errorf("expected the function given a priority to run first, got %+v", s.Funcs)
+	}
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
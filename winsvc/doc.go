@@ -0,0 +1,5 @@
+// Package winsvc runs an application using a closer as a Windows service: stopping the
+// service or shutting the system down calls CloseAll, and the service manager is told that
+// the service is stopping each time a closing function finishes. It lives in its own module,
+// so that the closer package does not depend on golang.org/x/sys.
+package winsvc
@@ -0,0 +1,10 @@
+module github.com/nzb3/closer/winsvc
+
+go 1.24.0
+
+require (
+	github.com/nzb3/closer v0.0.0
+	golang.org/x/sys v0.36.0
+)
+
+replace github.com/nzb3/closer => ../
@@ -0,0 +1,73 @@
+//go:build windows
+
+package winsvc
+
+import (
+	"time"
+
+	"github.com/nzb3/closer"
+	"golang.org/x/sys/windows/svc"
+)
+
+// waitHint is how long the service manager is asked to wait for the next checkpoint while
+// the service is stopping.
+const waitHint = 10 * time.Second
+
+// Run runs the service name with the service manager until c has shut down, and returns once
+// the service is reported stopped. It must be called from a process started by the service
+// manager; see svc.IsWindowsService.
+//
+// Example:
+//
+//	c := closer.New()
+//	c.AddHTTPServer(srv)
+//	go srv.ListenAndServe()
+//	if err := winsvc.Run("myapp", c); err != nil {
+//		log.Fatal(err)
+//	}
+func Run(name string, c *closer.Closer) error {
+	return svc.Run(name, Handler(c))
+}
+
+// Handler returns the svc.Handler used by Run, for svc.Run and debug.Run. It accepts stop and
+// shutdown requests, both of which call CloseAll on c. Once shutdown starts, however it was
+// initiated, the service is reported as StopPending with a new checkpoint for every event of
+// the shutdown, so that the service manager keeps waiting while closing functions finish. The
+// exit code of the service is the one returned by ExitCode.
+func Handler(c *closer.Closer) svc.Handler {
+	return handler{closer: c}
+}
+
+// handler is the svc.Handler returned by Handler.
+type handler struct {
+	closer *closer.Closer
+}
+
+// Execute reports the service as running until c shuts down, then follows the shutdown.
+func (h handler) Execute(_ []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	events := h.closer.Subscribe()
+	status := svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	s <- status
+	for {
+		select {
+		case req := <-r:
+			switch req.Cmd {
+			case svc.Interrogate:
+				s <- status
+			case svc.Stop, svc.Shutdown:
+				go h.closer.CloseAll()
+			}
+		case _, ok := <-events:
+			if !ok {
+				code := uint32(h.closer.ExitCode())
+				return code != 0, code
+			}
+			status = svc.Status{
+				State:      svc.StopPending,
+				CheckPoint: status.CheckPoint + 1,
+				WaitHint:   uint32(waitHint / time.Millisecond),
+			}
+			s <- status
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//go:build windows
+
+package winsvc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nzb3/closer"
+	"golang.org/x/sys/windows/svc"
+)
+
+// execute runs the handler of c in the background, returning the channel of change requests,
+// the statuses reported and a channel receiving the result of Execute.
+func execute(c *closer.Closer) (chan<- svc.ChangeRequest, <-chan svc.Status, <-chan uint32) {
+	r, s, done := make(chan svc.ChangeRequest), make(chan svc.Status, 64), make(chan uint32, 1)
+	go func() {
+		ssec, code := Handler(c).Execute(nil, r, s)
+		if ssec != (code != 0) {
+			code = 1 << 31
+		}
+		done <- code
+	}()
+	return r, s, done
+}
+
+// TestHandlerStop verifies that a stop request shuts the Closer down and that the service is
+// reported stopping with increasing checkpoints until the shutdown completes.
+func TestHandlerStop(t *testing.T) {
+	c := closer.New()
+	var closed bool
+	c.Add(func() error {
+		closed = true
+		return nil
+	})
+	r, s, done := execute(c)
+
+	if st := <-s; st.State != svc.Running || st.Accepts != svc.AcceptStop|svc.AcceptShutdown {
+		t.Fatalf("expected running status accepting stop and shutdown, got %+v", st)
+	}
+	r <- svc.ChangeRequest{Cmd: svc.Stop}
+	if code := <-done; code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+	if !closed {
+		t.Error("expected closing function to run")
+	}
+
+	var checkpoint uint32
+	for len(s) > 0 {
+		st := <-s
+		if st.State != svc.StopPending || st.CheckPoint != checkpoint+1 || st.WaitHint == 0 {
+			t.Errorf("expected stop pending with checkpoint %d, got %+v", checkpoint+1, st)
+		}
+		checkpoint = st.CheckPoint
+	}
+	if checkpoint == 0 {
+		t.Error("expected stop pending statuses")
+	}
+}
+
+// TestHandlerShutdownFailure verifies that a system shutdown request closes the Closer and that
+// a failing closing function is reported as a service-specific exit code.
+func TestHandlerShutdownFailure(t *testing.T) {
+	c := closer.New()
+	c.Add(func() error { return errors.New("flush failed") })
+	r, s, done := execute(c)
+
+	<-s
+	r <- svc.ChangeRequest{Cmd: svc.Interrogate}
+	if st := <-s; st.State != svc.Running {
+		t.Errorf("expected interrogate to report running, got %+v", st)
+	}
+	r <- svc.ChangeRequest{Cmd: svc.Shutdown}
+	if code := <-done; code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+// TestHandlerClosedOtherwise verifies that the service stops when the Closer is shut down
+// without a request from the service manager.
+func TestHandlerClosedOtherwise(t *testing.T) {
+	c := closer.New()
+	_, s, done := execute(c)
+
+	<-s
+	c.CloseAll()
+	if code := <-done; code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+	if st := <-s; st.State != svc.StopPending {
+		t.Errorf("expected stop pending status, got %+v", st)
+	}
+}
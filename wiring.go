@@ -0,0 +1,92 @@
+package closer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoClosers is returned by Validate when no closing function is registered.
+	ErrNoClosers = errors.New("closer: no closing function registered")
+
+	// ErrMissingClosers is returned by Validate when functions declared with Require are not
+	// registered.
+	ErrMissingClosers = errors.New("closer: required closing functions not registered")
+)
+
+// Require makes New create a Closer that expects closing functions named names, as given to
+// AddNamed or AddKeyed, to be registered by the time it shuts down, catching components that
+// forgot to register their cleanup. Shutdown logs a Warn "required closers missing" event
+// with the names of those that are not as "missing", and Validate reports them ahead of it.
+//
+// Example:
+//
+//	c := closer.New(closer.WithSignals(syscall.SIGTERM), closer.Require("db", "http"))
+func Require(names ...string) Option {
+	return func(o *options) {
+		o.required = append(o.required, names...)
+	}
+}
+
+// WithEmptyWarning makes New create a Closer that logs a Warn "no closers registered" event
+// when it shuts down without any closing function registered, which usually means that
+// components were registered with another Closer than the one shut down.
+func WithEmptyWarning() Option {
+	return func(o *options) {
+		o.warnEmpty = true
+	}
+}
+
+// Validate checks the registered closing functions, for applications to call once wired,
+// before serving: it returns ErrNoClosers if none is registered, and an error wrapping
+// ErrMissingClosers naming those declared with Require that are not. It returns nil
+// otherwise, and once shutdown has started, validates the functions it runs.
+//
+// Example:
+//
+//	if err := c.Validate(); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *Closer) Validate() error {
+	funcs := c.registeredFuncs()
+	var errs []error
+	if len(funcs) == 0 {
+		errs = append(errs, ErrNoClosers)
+	}
+	if missing := c.missing(funcs); len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrMissingClosers, strings.Join(missing, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// checkWiring logs to l what Validate would report about funcs, about to be run by shutdown,
+// as configured by WithEmptyWarning and Require.
+func (c *Closer) checkWiring(l Logger, funcs []entry) {
+	if c.warnEmpty && len(funcs) == 0 {
+		warn(l, "no closers registered")
+	}
+	if missing := c.missing(funcs); len(missing) > 0 {
+		warn(l, "required closers missing", "missing", missing)
+	}
+}
+
+// missing returns the names declared with Require that no function of funcs has, in the order
+// they were declared.
+func (c *Closer) missing(funcs []entry) []string {
+	if len(c.required) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, len(funcs))
+	for i := range funcs {
+		names[funcs[i].name] = true
+	}
+	var missing []string
+	for _, name := range c.required {
+		if !names[name] && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
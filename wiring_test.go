@@ -0,0 +1,63 @@
+package closer
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestValidate verifies that Validate reports an empty Closer and required functions that
+// are not registered, and nothing once they are.
+func TestValidate(t *testing.T) {
+	c := New(Require("db", "http"))
+	err := c.Validate()
+	if !errors.Is(err, ErrNoClosers) || !errors.Is(err, ErrMissingClosers) {
+		t.Fatalf("expected ErrNoClosers and ErrMissingClosers, got %v", err)
+	}
+
+	c.AddNamed("db", func() error { return nil })
+	err = c.Validate()
+	if errors.Is(err, ErrNoClosers) {
+		t.Errorf("expected no ErrNoClosers, got %v", err)
+	}
+	if !errors.Is(err, ErrMissingClosers) || !strings.HasSuffix(err.Error(), ": http") {
+		t.Errorf("expected http missing, got %v", err)
+	}
+
+	c.AddKeyed("http", func() error { return nil })
+	if err := c.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestCheckWiring verifies that shutdown warns about missing required functions, and about
+// an empty Closer only with WithEmptyWarning.
+func TestCheckWiring(t *testing.T) {
+	l := &warnLogger{}
+	c := New(WithLogger(l), Require("db", "http", "db"), WithEmptyWarning())
+	if err := c.CloseAll(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{"WARN no closers registered", "WARN required closers missing missing=[db http]"} {
+		if !slices.Contains(l.events, want) {
+			t.Errorf("expected %q, got %v", want, l.events)
+		}
+	}
+
+	l = &warnLogger{}
+	c = New(WithLogger(l), Require("db"))
+	c.AddNamed("db", func() error { return nil })
+	c.CloseAll()
+	for _, e := range l.events {
+		if strings.HasPrefix(e, "WARN") {
+			t.Errorf("expected no warning, got %q", e)
+		}
+	}
+
+	l = &warnLogger{}
+	New(WithLogger(l)).CloseAll()
+	if slices.Contains(l.events, "WARN no closers registered") {
+		t.Errorf("expected no warning without WithEmptyWarning, got %v", l.events)
+	}
+}
@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+)
+
+// Workers starts n goroutines running worker, such as the consumers of a queue, and registers
+// a closing function, named "workers" and configured by opts, that waits for all of them to
+// return. Workers receive the context returned by Context, so they are asked to stop as soon
+// as shutdown is initiated. The function runs before all others, or in PhaseRequests if c
+// declares it, as NewWebService does, unless opts give it another priority or phase, so that
+// workers can still use the resources closed after them. Panics in workers are recovered and
+// reported by that function once all workers have returned; if the deadline passes first, the
+// function is reported as not finished. It panics if worker is nil or n is negative.
+//
+// Example:
+//
+//	c.Workers(8, func(ctx context.Context) {
+//		for job := range jobs(ctx) {
+//			process(job)
+//		}
+//	})
+func (c *Closer) Workers(n int, worker func(ctx context.Context), opts ...Option) {
+	mustNotBeNil(worker, "worker")
+	if n < 0 {
+		panic("closer: negative number of workers")
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error // panics recovered from workers, protected by mu
+		stopped = make(chan struct{})
+	)
+	fn := func(ctx context.Context) error {
+		worker(ctx)
+		return nil
+	}
+	wg.Add(n)
+	for range n {
+		go func() {
+			defer wg.Done()
+			err := call(fn, c.ctx)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	opts = append([]Option{withName("workers"), c.phaseOr(PhaseRequests, WithPriority(math.MaxInt))}, opts...)
+	c.addContext(opts, func(ctx context.Context) error {
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			return notFinished(ctx.Err())
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return errors.Join(errs...)
+	})
+}
@@ -0,0 +1,76 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkers verifies that workers are asked to stop on shutdown and waited for before the
+// other closing functions run.
+func TestWorkers(t *testing.T) {
+	c := New()
+	var running atomic.Int32
+	c.Workers(4, func(ctx context.Context) {
+		running.Add(1)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+	})
+	var left int32 = -1
+	c.Add(func() error {
+		left = running.Load()
+		return nil
+	})
+
+	if err := c.CloseAll(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if left != 0 {
+		t.Errorf("expected all workers to have returned, got %d running", left)
+	}
+}
+
+// TestWorkersPanic verifies that panicking workers are reported.
+func TestWorkersPanic(t *testing.T) {
+	c := New()
+	var started atomic.Int32
+	c.Workers(2, func(ctx context.Context) {
+		if started.Add(1) == 1 {
+			panic("boom")
+		}
+		<-ctx.Done()
+	})
+
+	var pe *PanicError
+	if err := c.CloseAll(); !errors.As(err, &pe) {
+		t.Errorf("expected a panic, got %v", err)
+	}
+}
+
+// TestWorkersStuck verifies that workers still running at the deadline are reported.
+func TestWorkersStuck(t *testing.T) {
+	c := New()
+	c.Workers(1, func(ctx context.Context) {
+		time.Sleep(time.Second)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.CloseAllContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+// TestWorkersNegative verifies that a negative number of workers panics with a message of
+// the package rather than the one of sync.WaitGroup.
+func TestWorkersNegative(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "closer: negative number of workers" {
+			t.Errorf("expected panic on negative n, got %v", r)
+		}
+	}()
+	New().Workers(-1, func(context.Context) {})
+}